@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	usecases "no-code-app/apps/02_use_cases"
+	entities "no-code-app/apps/03_entities"
+	errorhandler "no-code-app/apps/10_utils/error"
+	"no-code-app/pkg/middleware"
+	"no-code-app/pkg/openapi"
+	"no-code-app/pkg/router"
+)
+
+// LogLevelControllerは、実行中のサービスのログレベルを参照、変更する管理用のコントローラーです。
+type LogLevelController struct {
+	// ユースケースのインターフェース
+	useCase usecases.LogLevelUseCase
+}
+
+// NewLogLevelControllerは、新しいLogLevelControllerを初期化します。
+// group: ルートを登録するグループ
+// useCase: ログレベルのユースケース
+// auth: 認証ミドルウェア
+func NewLogLevelController(group *router.Group, useCase usecases.LogLevelUseCase, auth router.Middleware) {
+	controller := &LogLevelController{useCase: useCase}
+	admin := group.Group("/admin", auth)
+	// ログレベルを取得するエンドポイント
+	admin.GET("/log/levels", controller.GetLogLevels, router.Name("admin.log.levels"), router.Doc(&openapi.Operation{
+		Summary:   "ログレベルを取得します",
+		Tags:      []string{"admin"},
+		Responses: map[string]*openapi.Response{"200": openapi.JSONResponse("ログレベルとコンポーネントごとのログレベル", openapi.SchemaOf(entities.LogLevels{}))},
+		Security:  []openapi.SecurityRequirement{{openapi.BearerAuth: {}}},
+	}))
+	// ログレベルを変更するエンドポイント
+	admin.PUT("/log/levels", controller.UpdateLogLevels, router.Name("admin.log.levels.update"), router.Doc(&openapi.Operation{
+		Summary:     "ログレベルを変更します",
+		Description: "Level が空の場合はログレベルを変更しません。Components は指定したコンポーネントのみ変更し、ログレベルが空のコンポーネントは設定を削除します。再起動すると設定ファイルの値に戻ります。",
+		Tags:        []string{"admin"},
+		RequestBody: openapi.JSONBody(openapi.SchemaOf(entities.LogLevels{})),
+		Responses:   map[string]*openapi.Response{"200": openapi.JSONResponse("変更後のログレベル", openapi.SchemaOf(entities.LogLevels{}))},
+		Security:    []openapi.SecurityRequirement{{openapi.BearerAuth: {}}},
+	}))
+}
+
+// GetLogLevelsは、ログレベルとコンポーネントごとのログレベルを返します。
+func (ctrl *LogLevelController) GetLogLevels(w http.ResponseWriter, r *http.Request) {
+	// 管理の権限を確認
+	if !ctrl.useCase.CanManage(middleware.UserIDFromContext(r.Context())) {
+		errorhandler.WriteError(w, r, errorhandler.Forbidden())
+		return
+	}
+	writeJSON(w, http.StatusOK, ctrl.useCase.GetLogLevels())
+}
+
+// UpdateLogLevelsは、リクエストボディのログレベルに変更し、変更後のログレベルを返します。
+func (ctrl *LogLevelController) UpdateLogLevels(w http.ResponseWriter, r *http.Request) {
+	// 管理の権限を確認
+	if !ctrl.useCase.CanManage(middleware.UserIDFromContext(r.Context())) {
+		errorhandler.WriteError(w, r, errorhandler.Forbidden())
+		return
+	}
+	// リクエストボディを取得
+	var levels entities.LogLevels
+	if err := json.NewDecoder(r.Body).Decode(&levels); err != nil {
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(err.Error()))
+		return
+	}
+	// ログレベルを変更
+	updated, err := ctrl.useCase.UpdateLogLevels(r.Context(), levels)
+	if err != nil {
+		// エラーレスポンスを返す
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(err.Error()))
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
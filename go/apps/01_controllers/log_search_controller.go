@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	usecases "no-code-app/apps/02_use_cases"
+	entities "no-code-app/apps/03_entities"
+	errorhandler "no-code-app/apps/10_utils/error"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/pkg/middleware"
+	"no-code-app/pkg/openapi"
+	"no-code-app/pkg/router"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// エクスポートするCSVの列
+var logExportColumns = []string{"log_id", "created_at", "log_level", "program_id", "client_ip", "server_ip", "created_by", "message"}
+
+// LogSearchControllerは、cm_t_log テーブルのログを検索、エクスポート、ライブテールする管理用のコントローラーです。
+type LogSearchController struct {
+	// ユースケースのインターフェース
+	useCase usecases.LogSearchUseCase
+	// WebSocketのアップグレーダー
+	upgrader websocket.Upgrader
+	// 接続されているクライアントのハブ（ログは個別に送信し、シャットダウン時のクローズにのみ使用）
+	hub *WebSocketHub
+}
+
+// NewLogSearchControllerは、新しいLogSearchControllerを初期化します。
+// group: ルートを登録するグループ
+// useCase: ログの検索のユースケース
+// auth: 認証ミドルウェア
+// hub: ライブテールのWebSocketクライアントのハブ（シャットダウン時にクローズする）
+func NewLogSearchController(group *router.Group, useCase usecases.LogSearchUseCase, auth router.Middleware, hub *WebSocketHub) {
+	controller := &LogSearchController{
+		useCase:  useCase,
+		hub:      hub,
+		upgrader: newUpgrader(),
+	}
+	admin := group.Group("/admin", auth)
+	// ログを検索するエンドポイント
+	admin.GET("/logs", controller.SearchLogs, router.Name("admin.logs"), router.Doc(&openapi.Operation{
+		Summary:     "ログを検索します",
+		Description: "条件に一致するログを新しい順に返します。次のページは NextCursor を cursor に指定して取得します。",
+		Tags:        []string{"admin"},
+		Parameters: append(logQueryParams(),
+			openapi.QueryParam("cursor", "前のページの NextCursor", openapi.String()),
+			openapi.QueryParam("limit", "1ページの件数（省略時は100件、最大1000件）", openapi.Integer()),
+		),
+		Responses: map[string]*openapi.Response{"200": openapi.JSONResponse("ログの1ページ", openapi.SchemaOf(entities.LogPage{}))},
+		Security:  []openapi.SecurityRequirement{{openapi.BearerAuth: {}}},
+	}))
+	// ログをエクスポートするエンドポイント
+	admin.GET("/logs/export", controller.ExportLogs, router.Name("admin.logs.export"), router.Doc(&openapi.Operation{
+		Summary:     "ログをエクスポートします",
+		Description: "条件に一致するログを新しい順に、最大100000件までCSVまたはJSONのファイルで返します。",
+		Tags:        []string{"admin"},
+		Parameters: append(logQueryParams(),
+			openapi.QueryParam("format", "ファイルの形式（省略時はcsv）", openapi.String().OneOf("csv", "json")),
+		),
+		Responses: map[string]*openapi.Response{"200": {Description: "ログのファイル"}},
+		Security:  []openapi.SecurityRequirement{{openapi.BearerAuth: {}}},
+	}))
+	// 新しいログを配信するWebSocketのエンドポイント
+	admin.GET("/logs/ws", controller.TailWebSocketHandler, router.Name("admin.logs.ws"), router.Doc(&openapi.Operation{
+		Summary:     "新しいログをWebSocketで購読します",
+		Description: "接続以降に記録された条件に一致するログ（LogEntry）を古い順にJSONのメッセージで配信します。APIキーは Authorization ヘッダーまたは X-API-Key ヘッダーで指定します。",
+		Tags:        []string{"admin"},
+		Parameters:  logQueryParams(),
+		Responses:   map[string]*openapi.Response{"101": {Description: "Switching Protocols"}},
+		Security:    []openapi.SecurityRequirement{{openapi.BearerAuth: {}}},
+	}))
+}
+
+// logQueryParamsは、ログの検索条件のクエリパラメータを返します。
+func logQueryParams() []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.QueryParam("from", "作成日時の開始（RFC3339形式）", openapi.DateTime()),
+		openapi.QueryParam("to", "作成日時の終了（RFC3339形式、この日時を含まない）", openapi.DateTime()),
+		openapi.QueryParam("level", "ログレベル（カンマ区切りで複数指定）", openapi.String()),
+		openapi.QueryParam("program_id", "プログラムID", openapi.String()),
+		openapi.QueryParam("ip", "クライアントまたはサーバーのIPアドレス", openapi.String()),
+		openapi.QueryParam("q", "メッセージに含まれる文字列", openapi.String()),
+	}
+}
+
+// parseLogQueryは、クエリパラメータからログの検索条件を取得します。
+func parseLogQuery(r *http.Request) (entities.LogQuery, error) {
+	values := r.URL.Query()
+	query := entities.LogQuery{
+		ProgramID: values.Get("program_id"),
+		IP:        values.Get("ip"),
+		Text:      values.Get("q"),
+	}
+	for _, name := range []string{"from", "to"} {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, fmt.Errorf("invalid %s: %v", name, err)
+		}
+		if name == "from" {
+			query.From = parsed
+		} else {
+			query.To = parsed
+		}
+	}
+	for _, value := range values["level"] {
+		for _, level := range strings.Split(value, ",") {
+			if level = strings.TrimSpace(level); level != "" {
+				query.Levels = append(query.Levels, level)
+			}
+		}
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return query, fmt.Errorf("invalid limit: %q", value)
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
+
+// writeLogSearchErrorは、検索条件が不正な場合は400、それ以外（DBのエラーなど）は500のエラーレスポンスを返します。
+func writeLogSearchError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, usecases.ErrInvalidLogQuery) {
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(err.Error()))
+		return
+	}
+	errorhandler.WriteError(w, r, err)
+}
+
+// SearchLogsは、条件に一致するログを新しい順に1ページ返します。
+func (ctrl *LogSearchController) SearchLogs(w http.ResponseWriter, r *http.Request) {
+	// 検索の権限を確認
+	if !ctrl.useCase.CanSearch(middleware.UserIDFromContext(r.Context())) {
+		errorhandler.WriteError(w, r, errorhandler.Forbidden())
+		return
+	}
+	// 検索条件を取得
+	query, err := parseLogQuery(r)
+	if err != nil {
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(err.Error()))
+		return
+	}
+	// ログを検索
+	page, err := ctrl.useCase.Search(r.Context(), query, r.URL.Query().Get("cursor"))
+	if err != nil {
+		// エラーレスポンスを返す
+		writeLogSearchError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// ExportLogsは、条件に一致するログをCSVまたはJSONのファイルで返します。
+// 書き込みを始めた後のエラーはステータスを変更できないため、ログに出力してファイルを途中で終了します。
+func (ctrl *LogSearchController) ExportLogs(w http.ResponseWriter, r *http.Request) {
+	// 検索の権限を確認
+	if !ctrl.useCase.CanSearch(middleware.UserIDFromContext(r.Context())) {
+		errorhandler.WriteError(w, r, errorhandler.Forbidden())
+		return
+	}
+	// 検索条件を取得
+	query, err := parseLogQuery(r)
+	if err != nil {
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(err.Error()))
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(fmt.Sprintf("invalid format: %q", format)))
+		return
+	}
+
+	// 最初のログを書き込むときにレスポンスを開始する（書き込む前の検索のエラーはエラーレスポンスで返す）
+	csvWriter := csv.NewWriter(w)
+	encoder := json.NewEncoder(w)
+	count := 0
+	start := func() error {
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="logs-%s.%s"`, time.Now().Format("20060102T150405"), format))
+		w.WriteHeader(http.StatusOK)
+		if format == "csv" {
+			return csvWriter.Write(logExportColumns)
+		}
+		_, err := w.Write([]byte("["))
+		return err
+	}
+	write := func(entry entities.LogEntry) error {
+		count++
+		if count == 1 {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if format == "csv" {
+			return csvWriter.Write([]string{
+				strconv.FormatInt(entry.LogID, 10), entry.CreatedAt.Format(time.RFC3339), entry.LogLevel, entry.ProgramID,
+				entry.ClientIP, entry.ServerIP, entry.CreatedBy, entry.Message,
+			})
+		}
+		// JSONの配列の要素として1件ずつ書き込む
+		if count > 1 {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		return encoder.Encode(entry)
+	}
+	finish := func() error {
+		if count == 0 {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if format == "csv" {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+		_, err := w.Write([]byte("]\n"))
+		return err
+	}
+
+	// ログをエクスポート
+	if err := ctrl.useCase.Export(r.Context(), query, write); err != nil {
+		if count == 0 {
+			// エラーレスポンスを返す
+			writeLogSearchError(w, r, err)
+			return
+		}
+		logger.Ctx(r.Context()).With("error", err).Warn("failed to export logs")
+		return
+	}
+	if err := finish(); err != nil {
+		logger.Ctx(r.Context()).With("error", err).Warn("failed to export logs")
+	}
+}
+
+// TailWebSocketHandlerは、接続以降に記録された条件に一致するログをWebSocketで配信します。
+func (ctrl *LogSearchController) TailWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	// 検索の権限を確認
+	if !ctrl.useCase.CanSearch(middleware.UserIDFromContext(r.Context())) {
+		errorhandler.WriteError(w, r, errorhandler.Forbidden())
+		return
+	}
+	// 検索条件を取得
+	query, err := parseLogQuery(r)
+	if err != nil {
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(err.Error()))
+		return
+	}
+	// ログの配信を開始（クライアントの切断でキャンセルする）
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	entries, err := ctrl.useCase.Tail(ctx, query)
+	if err != nil {
+		// エラーレスポンスを返す
+		writeLogSearchError(w, r, err)
+		return
+	}
+
+	// WebSocket接続をアップグレード
+	conn, err := ctrl.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	// 接続終了時にクローズ
+	defer conn.Close()
+	// シャットダウン時にクローズできるようハブに追加
+	ctrl.hub.Register(conn)
+	defer ctrl.hub.Unregister(conn)
+
+	// クライアントの切断を検知したら配信を停止
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	// 新しいログを送信（配信が停止するとチャネルが閉じられる）
+	for entry := range entries {
+		if err := conn.WriteJSON(entry); err != nil {
+			return
+		}
+	}
+}
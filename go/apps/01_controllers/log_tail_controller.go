@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"net/http"
+	usecases "no-code-app/apps/02_use_cases"
+	entities "no-code-app/apps/03_entities"
+	errorhandler "no-code-app/apps/10_utils/error"
+	"no-code-app/pkg/middleware"
+	"no-code-app/pkg/openapi"
+	"no-code-app/pkg/router"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+)
+
+// LogTailControllerは、監視対象サービスのログをWebSocketで配信するコントローラーです。
+type LogTailController struct {
+	// ユースケースのインターフェース
+	useCase usecases.LogTailUseCase
+	// WebSocketのアップグレーダー
+	upgrader websocket.Upgrader
+	// 接続されているクライアントのハブ（ログは個別に送信し、シャットダウン時のクローズにのみ使用）
+	hub *WebSocketHub
+}
+
+// NewLogTailControllerは、新しいLogTailControllerを初期化します。
+// group: ルートを登録するグループ
+// useCase: ログ追従のユースケース
+// auth: 認証ミドルウェア
+// hub: WebSocketクライアントのハブ（シャットダウン時にクローズする）
+func NewLogTailController(group *router.Group, useCase usecases.LogTailUseCase, auth router.Middleware, hub *WebSocketHub) {
+	controller := &LogTailController{
+		useCase:  useCase,
+		hub:      hub,
+		upgrader: newUpgrader(),
+	}
+	// ログを配信するWebSocketのエンドポイント
+	group.Group("", auth).GET("/monitor/{serviceName}/logs/ws", controller.LogWebSocketHandler, router.Name("monitor.logs.ws"), router.Doc(&openapi.Operation{
+		Summary:     "サービスのログをWebSocketで購読します",
+		Description: "直近の履歴から順にログの行（LogLine）をJSONのメッセージで配信します。APIキーは Authorization ヘッダーまたは X-API-Key ヘッダーで指定します。",
+		Tags:        []string{"monitor"},
+		Parameters: []openapi.Parameter{
+			openapi.QueryParam("pattern", "絞り込む正規表現", openapi.String()),
+			openapi.QueryParam("ignore_case", "大文字と小文字を区別しない", openapi.Boolean()),
+			openapi.QueryParam("invert", "一致しない行を配信する", openapi.Boolean()),
+		},
+		Responses: map[string]*openapi.Response{"101": {Description: "Switching Protocols"}},
+		Security:  []openapi.SecurityRequirement{{openapi.BearerAuth: {}}},
+	}))
+}
+
+// LogWebSocketHandlerは、サービスのログを直近の履歴から順にWebSocketで配信します。
+// クエリパラメータ pattern（正規表現）、ignore_case、invert でgrepと同様に絞り込みます。
+func (ctrl *LogTailController) LogWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	// パスパラメータからサービス名を取得
+	serviceName := r.PathValue("serviceName")
+	// 購読の権限を確認
+	if !ctrl.useCase.CanSubscribe(middleware.UserIDFromContext(r.Context()), serviceName) {
+		errorhandler.WriteError(w, r, errorhandler.Forbidden())
+		return
+	}
+	// 絞り込み条件を取得
+	query := r.URL.Query()
+	ignoreCase, _ := strconv.ParseBool(query.Get("ignore_case"))
+	invert, _ := strconv.ParseBool(query.Get("invert"))
+	filter := entities.LogFilter{Pattern: query.Get("pattern"), IgnoreCase: ignoreCase, Invert: invert}
+
+	// ログを購読
+	history, lines, unsubscribe, err := ctrl.useCase.Subscribe(serviceName, filter)
+	if err != nil {
+		// エラーレスポンスを返す
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(err.Error()))
+		return
+	}
+	defer unsubscribe()
+
+	// WebSocket接続をアップグレード
+	conn, err := ctrl.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	// 接続終了時にクローズ
+	defer conn.Close()
+	// シャットダウン時にクローズできるようハブに追加
+	ctrl.hub.Register(conn)
+	defer ctrl.hub.Unregister(conn)
+
+	// クライアントの切断を検知したら購読を解除
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				unsubscribe()
+				return
+			}
+		}
+	}()
+
+	// 直近の履歴を送信
+	for _, line := range history {
+		if err := conn.WriteJSON(line); err != nil {
+			return
+		}
+	}
+	// 新しい行を送信（購読が解除されるとチャネルが閉じられる）
+	for line := range lines {
+		if err := conn.WriteJSON(line); err != nil {
+			return
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	usecases "no-code-app/apps/02_use_cases"
 	entities "no-code-app/apps/03_entities"
+	errorhandler "no-code-app/apps/10_utils/error"
+	"no-code-app/pkg/openapi"
+	"no-code-app/pkg/router"
+	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
@@ -15,70 +19,166 @@ type MonitoringController struct {
 	useCase usecases.MonitoringUseCase
 	// WebSocketのアップグレーダー
 	upgrader websocket.Upgrader
-	// 接続されているクライアントのマップ
-	clients map[*websocket.Conn]bool
+	// 接続されているクライアントのハブ
+	hub *WebSocketHub
 	// サービスステータスをブロードキャストするためのチャネル
-	broadcast chan entities.ServiceStatus
+	broadcast <-chan entities.ServiceStatus
 }
 
 // NewMonitoringControllerは、新しいMonitoringControllerを初期化します。
-func NewMonitoringController(router *gin.Engine, useCase usecases.MonitoringUseCase) {
+// group: ルートを登録するグループ
+// useCase: 監視のユースケース
+// hub: ステータスを配信するWebSocketクライアントのハブ（シャットダウン時にクローズする）
+func NewMonitoringController(group *router.Group, useCase usecases.MonitoringUseCase, hub *WebSocketHub) {
 	controller := &MonitoringController{
-		useCase: useCase,
-		upgrader: websocket.Upgrader{
-			// 読み取りバッファサイズ
-			ReadBufferSize: 1024,
-			// 書き込みバッファサイズ
-			WriteBufferSize: 1024,
-		},
-		// クライアントマップの初期化
-		clients: make(map[*websocket.Conn]bool),
-		// ブロードキャストチャネルの初期化
-		broadcast: make(chan entities.ServiceStatus),
+		useCase:  useCase,
+		upgrader: newUpgrader(),
+		// クライアントハブ
+		hub: hub,
 	}
+	// 記録されたサービスステータスを購読してブロードキャストする
+	controller.broadcast, _ = useCase.Subscribe()
 	// サービスステータスを取得するエンドポイント
-	router.GET("/monitor/:serviceName", controller.GetServiceStatus)
+	group.GET("/monitor/{serviceName}", controller.GetServiceStatus, router.Name("monitor.status"), router.Doc(&openapi.Operation{
+		Summary:   "サービスのステータスを取得します",
+		Tags:      []string{"monitor"},
+		Responses: map[string]*openapi.Response{"200": openapi.JSONResponse("サービスのステータス", openapi.SchemaOf(entities.ServiceStatus{}))},
+	}))
+	// サービスの稼働率を取得するエンドポイント
+	group.GET("/monitor/{serviceName}/uptime", controller.GetUptime, router.Name("monitor.uptime"), router.Doc(&openapi.Operation{
+		Summary:    "サービスの稼働率を取得します",
+		Tags:       []string{"monitor"},
+		Parameters: timeRangeParams(),
+		Responses:  map[string]*openapi.Response{"200": openapi.JSONResponse("期間の稼働率", openapi.SchemaOf(entities.Uptime{}))},
+	}))
+	// サービスのメトリクスの異常を取得するエンドポイント
+	group.GET("/monitor/{serviceName}/anomalies", controller.GetAnomalies, router.Name("monitor.anomalies"), router.Doc(&openapi.Operation{
+		Summary:    "サービスのメトリクスの異常とディスクの予測を取得します",
+		Tags:       []string{"monitor"},
+		Parameters: timeRangeParams(),
+		Responses:  map[string]*openapi.Response{"200": openapi.JSONResponse("期間の異常", openapi.SchemaOf([]entities.Anomaly{}))},
+	}))
 	// WebSocketハンドラーのエンドポイント
-	router.GET("/ws", controller.WebSocketHandler)
+	group.GET("/ws", controller.WebSocketHandler, router.Name("monitor.ws"), router.Doc(&openapi.Operation{
+		Summary:     "サービスのステータスをWebSocketで購読します",
+		Description: "記録されたサービスのステータス（ServiceStatus）をJSONのメッセージで配信します。",
+		Tags:        []string{"monitor"},
+		Responses:   map[string]*openapi.Response{"101": {Description: "Switching Protocols"}},
+	}))
 	// メッセージハンドリングのゴルーチンを開始
 	go controller.handleMessages()
 }
 
 // GetServiceStatusは、指定されたサービスのステータスを取得します。
-func (ctrl *MonitoringController) GetServiceStatus(c *gin.Context) {
+func (ctrl *MonitoringController) GetServiceStatus(w http.ResponseWriter, r *http.Request) {
 	// パスパラメータからサービス名を取得
-	serviceName := c.Param("serviceName")
+	serviceName := r.PathValue("serviceName")
 	// サービスステータスを取得
-	status, err := ctrl.useCase.GetServiceStatus(serviceName)
+	status, err := ctrl.useCase.GetServiceStatus(r.Context(), serviceName)
 	if err != nil {
 		// エラーレスポンスを返す
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorhandler.WriteError(w, r, err)
 		return
 	}
 	// ステータスをJSON形式で返す
-	c.JSON(http.StatusOK, status)
+	writeJSON(w, http.StatusOK, status)
+}
+
+// GetUptimeは、指定されたサービスの稼働率を取得します。
+// クエリパラメータ from, to（RFC3339形式）で集計期間を指定します。省略時は直近24時間です。
+func (ctrl *MonitoringController) GetUptime(w http.ResponseWriter, r *http.Request) {
+	// パスパラメータからサービス名を取得
+	serviceName := r.PathValue("serviceName")
+	// 集計期間を取得
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(err.Error()))
+		return
+	}
+	// 稼働率を取得
+	uptime, err := ctrl.useCase.GetUptime(serviceName, from, to)
+	if err != nil {
+		// エラーレスポンスを返す
+		errorhandler.WriteError(w, r, err)
+		return
+	}
+	// 稼働率をJSON形式で返す
+	writeJSON(w, http.StatusOK, uptime)
+}
+
+// GetAnomaliesは、指定されたサービスのメトリクスの異常とディスクの予測を注釈として取得します。
+// クエリパラメータ from, to（RFC3339形式）で対象期間を指定します。省略時は直近24時間です。
+func (ctrl *MonitoringController) GetAnomalies(w http.ResponseWriter, r *http.Request) {
+	// パスパラメータからサービス名を取得
+	serviceName := r.PathValue("serviceName")
+	// 対象期間を取得
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		errorhandler.WriteError(w, r, errorhandler.BadRequest(err.Error()))
+		return
+	}
+	// 異常を取得
+	anomalies, err := ctrl.useCase.GetAnomalies(serviceName, from, to)
+	if err != nil {
+		// エラーレスポンスを返す
+		errorhandler.WriteError(w, r, err)
+		return
+	}
+	// 異常をJSON形式で返す
+	writeJSON(w, http.StatusOK, anomalies)
+}
+
+// timeRangeParamsは、集計期間のクエリパラメータ from, to を作成します。
+func timeRangeParams() []openapi.Parameter {
+	return []openapi.Parameter{
+		openapi.QueryParam("from", "期間の開始（RFC3339形式、省略時は to の24時間前）", openapi.DateTime()),
+		openapi.QueryParam("to", "期間の終了（RFC3339形式、省略時は現在時刻）", openapi.DateTime()),
+	}
+}
+
+// parseTimeRangeは、クエリパラメータ from, to（RFC3339形式）から期間を取得します。
+// 省略時は直近24時間です。
+func parseTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	to := time.Now()
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to: %v", err)
+		}
+		to = parsed
+	}
+	from := to.Add(-24 * time.Hour)
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from: %v", err)
+		}
+		from = parsed
+	}
+	return from, to, nil
 }
 
 // WebSocketHandlerは、WebSocket接続を処理します。
-func (ctrl *MonitoringController) WebSocketHandler(c *gin.Context) {
+func (ctrl *MonitoringController) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 	// WebSocket接続をアップグレード
-	conn, err := ctrl.upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := ctrl.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		// エラーレスポンスを返す
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		// エラーレスポンスはアップグレーダーが返す
 		return
 	}
 	// 接続終了時にクローズ
 	defer conn.Close()
-	// クライアントをマップに追加
-	ctrl.clients[conn] = true
+	// クライアントをハブに追加
+	ctrl.hub.Register(conn)
+	monitorLogger(r.Context()).With("remote_addr", conn.RemoteAddr().String()).Debug("websocket client connected")
 
 	for {
 		// メッセージを読み取る
 		_, _, err := conn.ReadMessage()
 		if err != nil {
-			// エラーが発生した場合、クライアントをマップから削除
-			delete(ctrl.clients, conn)
+			// エラーが発生した場合、クライアントをハブから削除
+			ctrl.hub.Unregister(conn)
+			monitorLogger(r.Context()).With("remote_addr", conn.RemoteAddr().String()).Debug("websocket client disconnected")
 			break
 		}
 	}
@@ -86,18 +186,9 @@ func (ctrl *MonitoringController) WebSocketHandler(c *gin.Context) {
 
 // handleMessagesは、ブロードキャストメッセージを処理します。
 func (ctrl *MonitoringController) handleMessages() {
-	for {
-		// ブロードキャストチャネルからメッセージを受信
-		msg := <-ctrl.broadcast
-		for client := range ctrl.clients {
-			// クライアントにメッセージを送信
-			err := client.WriteJSON(msg)
-			if err != nil {
-				// エラーが発生した場合、クライアントをクローズ
-				client.Close()
-				// クライアントをマップから削除
-				delete(ctrl.clients, client)
-			}
-		}
+	// ブロードキャストチャネルからメッセージを受信
+	for msg := range ctrl.broadcast {
+		// 全てのクライアントにメッセージを送信
+		ctrl.hub.Broadcast(msg)
 	}
 }
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	errorhandler "no-code-app/apps/10_utils/error"
+
+	"github.com/gorilla/websocket"
+)
+
+// writeJSONは、値をJSON形式でレスポンスに書き込みます。
+// w: レスポンスの書き込み先
+// status: HTTPステータスコード
+// v: 書き込む値
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// newUpgraderは、アップグレードの失敗を共通のエラー形式で返すWebSocketのアップグレーダーを作成します。
+func newUpgrader() websocket.Upgrader {
+	return websocket.Upgrader{
+		// 読み取りバッファサイズ
+		ReadBufferSize: 1024,
+		// 書き込みバッファサイズ
+		WriteBufferSize: 1024,
+		// アップグレードに失敗した場合のエラーレスポンス
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			errorhandler.WriteError(w, r, errorhandler.New(status, errorhandler.CodeForStatus(status), reason.Error()))
+		},
+	}
+}
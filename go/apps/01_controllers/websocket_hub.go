@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	logger "no-code-app/apps/10_utils/log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketHubは、接続中のWebSocketクライアントを管理し、メッセージを配信します。
+// メッセージはクライアントごとの送信ゴルーチンが書き込むため、送信が滞ったクライアントが他のクライアントへの配信を妨げることはありません。
+type WebSocketHub struct {
+	// 排他制御用のミューテックス（クライアントへの書き込み中は保持しない）
+	mu sync.Mutex
+	// 接続されているクライアントと送信の待ち
+	clients map[*websocket.Conn]*hubClient
+	// クローズ済みかどうか
+	closed bool
+}
+
+// hubClientは、クライアントに送信するメッセージの待ちです。
+type hubClient struct {
+	// 送信を待つメッセージ（閉じると送信ゴルーチンが終了する）
+	send chan interface{}
+}
+
+const (
+	// クローズフレームの書き込みのタイムアウト
+	closeWriteTimeout = time.Second
+	// メッセージの書き込みのタイムアウト（超えたクライアントはクローズする）
+	writeWait = 10 * time.Second
+	// クライアントごとに送信を待つメッセージの最大件数（超えたクライアントは送信が滞っているとみなしてクローズする）
+	sendBufferSize = 64
+)
+
+// NewWebSocketHubは、新しいWebSocketHubを初期化します。
+func NewWebSocketHub() *WebSocketHub {
+	return &WebSocketHub{clients: make(map[*websocket.Conn]*hubClient)}
+}
+
+// Registerは、クライアントを配信対象に追加し、送信ゴルーチンを開始します。
+// ハブがクローズ済みの場合は、クローズフレームを送信して接続をクローズします。
+// conn: WebSocket接続
+func (h *WebSocketHub) Register(conn *websocket.Conn) {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		// クローズフレームの書き込みはロックの外で行う
+		closeConn(conn)
+		return
+	}
+	client := &hubClient{send: make(chan interface{}, sendBufferSize)}
+	h.clients[conn] = client
+	h.mu.Unlock()
+	go writeMessages(conn, client.send)
+}
+
+// Unregisterは、クライアントを配信対象から削除し、送信ゴルーチンを終了します。
+// conn: WebSocket接続
+func (h *WebSocketHub) Unregister(conn *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(conn)
+}
+
+// removeLockedは、h.mu を保持した状態でクライアントを配信対象から削除し、送信の待ちを閉じます。
+// conn: WebSocket接続
+func (h *WebSocketHub) removeLocked(conn *websocket.Conn) {
+	if client, ok := h.clients[conn]; ok {
+		close(client.send)
+		delete(h.clients, conn)
+	}
+}
+
+// Broadcastは、全てのクライアントの送信の待ちにメッセージを追加します。書き込みの完了は待ちません。
+// 送信の待ちが上限に達しているクライアントは、送信が滞っているとみなしてクローズし、配信対象から削除します。
+// msg: 送信するメッセージ
+func (h *WebSocketHub) Broadcast(msg interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conn, client := range h.clients {
+		select {
+		case client.send <- msg:
+		default:
+			// クローズすると読み取り側がエラーを検知して Unregister する
+			monitorLogger(context.Background()).With("remote_addr", conn.RemoteAddr().String()).Warn("closing slow websocket client")
+			conn.Close()
+			h.removeLocked(conn)
+		}
+	}
+}
+
+// writeMessagesは、送信の待ちのメッセージをJSON形式で順に書き込みます。
+// 書き込みに失敗した場合やタイムアウトした場合は、接続をクローズして終了します。
+// conn: WebSocket接続
+// send: 送信を待つメッセージ
+func writeMessages(conn *websocket.Conn, send <-chan interface{}) {
+	for msg := range send {
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteJSON(msg); err != nil {
+			monitorLogger(context.Background()).With("remote_addr", conn.RemoteAddr().String(), "error", err).Debug("failed to write websocket message")
+			conn.Close()
+			// 残りのメッセージは破棄する（Unregister で待ちが閉じられるまで受信する）
+			for range send {
+			}
+			return
+		}
+	}
+}
+
+// Closeは、全てのクライアントにクローズフレームを送信して接続をクローズします。
+// サーバーのシャットダウン時に呼び出します。以降に登録されたクライアントも即座にクローズします。
+// クローズフレームはロックの外でクライアントごとに並行して送信するため、送信が滞ったクライアントがあっても
+// クローズフレームの書き込みのタイムアウト程度で終了します。
+func (h *WebSocketHub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*websocket.Conn, 0, len(h.clients))
+	for conn := range h.clients {
+		conns = append(conns, conn)
+		h.removeLocked(conn)
+	}
+	h.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			closeConn(conn)
+		}()
+	}
+	wg.Wait()
+}
+
+// closeConnは、サーバーの停止を示すクローズフレームを期限付きで送信して接続をクローズします。
+// WriteControl は書き込み中の他のメッセージと並行して呼び出せます。
+// conn: WebSocket接続
+func closeConn(conn *websocket.Conn) {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(closeWriteTimeout))
+	conn.Close()
+}
+
+// monitorLoggerは、監視サービスのログを出力するロガー（component=monitor とコンテキストのリクエストIDなどを付与）を返します。
+// log.components.monitor でログレベルを変更できます。
+// ctx: コンテキスト
+func monitorLogger(ctx context.Context) *logger.Logger {
+	return logger.Ctx(ctx).With(logger.FieldComponent, "monitor")
+}
@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+)
+
+type AlertUseCase interface {
+	// アラートを発報するメソッド（メンテナンス中の場合は抑止される）
+	Raise(alert entities.Alert) (entities.Alert, error)
+}
+
+type alertUseCase struct {
+	// メンテナンスのユースケース（nilの場合は抑止しない）
+	maintenance MaintenanceUseCase
+	// アラートの通知先
+	notifiers []interfaces.AlertNotifier
+}
+
+// NewAlertUseCaseは、新しいAlertUseCaseを初期化します。
+// maintenance: メンテナンスのユースケース
+// notifiers: アラートの通知先
+func NewAlertUseCase(maintenance MaintenanceUseCase, notifiers ...interfaces.AlertNotifier) AlertUseCase {
+	return &alertUseCase{maintenance: maintenance, notifiers: notifiers}
+}
+
+// Raiseは、アラートを通知先に送信します。メンテナンス中のサービスのアラートは抑止されます。
+func (uc *alertUseCase) Raise(alert entities.Alert) (entities.Alert, error) {
+	// メンテナンス期間中であれば通知しない
+	if uc.maintenance != nil && uc.maintenance.IsUnderMaintenance(alert.ServiceName, alert.Timestamp) {
+		alert.Suppressed = true
+		monitorLogger(context.Background()).With("service", alert.ServiceName, "alert", alert.Message).Debug("alert suppressed by maintenance window")
+		return alert, nil
+	}
+
+	var firstErr error
+	for _, notifier := range uc.notifiers {
+		// 一部の通知先が失敗しても残りの通知先には送信する
+		if err := notifier.Notify(alert); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to notify alert: %v", err)
+		}
+	}
+	return alert, firstErr
+}
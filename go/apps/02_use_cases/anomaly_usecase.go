@@ -0,0 +1,240 @@
+package usecases
+
+import (
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	"no-code-app/apps/10_utils/anomaly"
+	"sync"
+	"time"
+)
+
+// 異常検知の対象とするメトリクス
+var anomalyMetrics = []struct {
+	// メトリクス名
+	name string
+	// ステータスから値を取り出す関数
+	value func(entities.ServiceStatus) float64
+}{
+	{"cpu", func(s entities.ServiceStatus) float64 { return s.CPUUsage }},
+	{"memory", func(s entities.ServiceStatus) float64 { return s.MemoryUsage }},
+	{"disk", func(s entities.ServiceStatus) float64 { return s.DiskUsage }},
+}
+
+// AnomalySettingsは、異常検知のユースケースの設定です。
+type AnomalySettings struct {
+	// 検知器の設定
+	Detector anomaly.Config
+	// ベースラインの学習に使用する過去の期間
+	BaselineWindow time.Duration
+	// トレンド予測に使用する過去の期間
+	ForecastWindow time.Duration
+	// この期間内にディスクが閾値に到達すると予測された場合にアラートを発報
+	ForecastHorizon time.Duration
+	// ディスク使用量の閾値
+	DiskThreshold float64
+	// 同じサービス・メトリクス・種類のアラートを再発報しない期間
+	AlertCooldown time.Duration
+}
+
+// anomalyAlertKeyは、アラートの再発報を抑止する単位（サービス・メトリクス・種類）です。
+type anomalyAlertKey struct {
+	serviceName string
+	metric      string
+	kind        entities.AnomalyKind
+}
+
+type AnomalyUseCase interface {
+	// 指定期間のメトリクスの異常とディスクの予測を取得するメソッド
+	DetectAnomalies(serviceName string, from time.Time, to time.Time) ([]entities.Anomaly, error)
+	// 新しいステータスを評価し、異常があればアラートを発報するメソッド
+	Evaluate(status entities.ServiceStatus) ([]entities.Anomaly, error)
+}
+
+type anomalyUseCase struct {
+	// ステータス履歴のリポジトリ
+	historyRepo interfaces.StatusHistoryRepository
+	// アラートのユースケース（nilの場合はアラートを発報しない）
+	alerts AlertUseCase
+	// 設定
+	settings AnomalySettings
+	// 最後にアラートを発報した時刻を保護するミューテックス
+	mu sync.Mutex
+	// サービス・メトリクス・種類ごとに最後にアラートを発報した時刻
+	lastRaised map[anomalyAlertKey]time.Time
+}
+
+// NewAnomalyUseCaseは、新しいAnomalyUseCaseを初期化します。
+// historyRepo: ステータス履歴のリポジトリ
+// alerts: アラートのユースケース
+// settings: 異常検知の設定
+func NewAnomalyUseCase(historyRepo interfaces.StatusHistoryRepository, alerts AlertUseCase, settings AnomalySettings) AnomalyUseCase {
+	return &anomalyUseCase{historyRepo: historyRepo, alerts: alerts, settings: settings, lastRaised: make(map[anomalyAlertKey]time.Time)}
+}
+
+// DetectAnomaliesは、指定期間のメトリクスの異常とディスクの予測を取得します。
+func (uc *anomalyUseCase) DetectAnomalies(serviceName string, from time.Time, to time.Time) ([]entities.Anomaly, error) {
+	// ベースラインの学習のため集計期間より前の履歴も取得
+	history, err := uc.historyRepo.GetServiceStatusHistory(serviceName, from.Add(-uc.settings.BaselineWindow), to)
+	if err != nil {
+		return nil, err
+	}
+
+	var anomalies []entities.Anomaly
+	for _, metric := range anomalyMetrics {
+		for _, result := range anomaly.Detect(uc.settings.Detector, toSeries(history, metric.value)) {
+			// 学習用の期間の結果は除外
+			if result.Point.Time.Before(from) {
+				continue
+			}
+			anomalies = append(anomalies, deviationAnomaly(serviceName, metric.name, result))
+		}
+	}
+	if forecast, ok := uc.forecastDisk(serviceName, history, to); ok {
+		anomalies = append(anomalies, forecast)
+	}
+	return anomalies, nil
+}
+
+// Evaluateは、履歴に保存済みの新しいステータスを評価し、異常があればアラートを発報します。
+// 同じサービス・メトリクス・種類のアラートは、AlertCooldown の間は再発報しません。
+func (uc *anomalyUseCase) Evaluate(status entities.ServiceStatus) ([]entities.Anomaly, error) {
+	history, err := uc.historyRepo.GetServiceStatusHistory(status.ServiceName, status.Timestamp.Add(-uc.settings.BaselineWindow), status.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	// 評価対象のステータスを除いた履歴でベースラインを学習
+	baseline := make([]entities.ServiceStatus, 0, len(history))
+	for _, s := range history {
+		if s.Timestamp.Before(status.Timestamp) {
+			baseline = append(baseline, s)
+		}
+	}
+
+	var anomalies []entities.Anomaly
+	for _, metric := range anomalyMetrics {
+		detector := anomaly.NewDetector(uc.settings.Detector)
+		for _, point := range toSeries(baseline, metric.value) {
+			detector.Observe(point)
+		}
+		point := anomaly.Point{Time: status.Timestamp, Value: metric.value(status)}
+		if result, anomalous := detector.Evaluate(point); anomalous {
+			anomalies = append(anomalies, deviationAnomaly(status.ServiceName, metric.name, result))
+		}
+	}
+	forecast, ok := uc.forecastDisk(status.ServiceName, history, status.Timestamp)
+	if ok && forecast.PredictedAt.Sub(status.Timestamp) <= uc.settings.ForecastHorizon {
+		anomalies = append(anomalies, forecast)
+	}
+
+	// 検知した異常をアラートとして発報
+	if uc.alerts != nil {
+		for _, a := range anomalies {
+			key := anomalyAlertKey{serviceName: a.ServiceName, metric: a.Metric, kind: a.Kind}
+			if !uc.shouldRaise(key, a.Timestamp) {
+				continue
+			}
+			if _, err := uc.alerts.Raise(toAlert(a)); err != nil {
+				return anomalies, err
+			}
+			uc.markRaised(key, a.Timestamp)
+		}
+	}
+	return anomalies, nil
+}
+
+// shouldRaiseは、最後の発報から AlertCooldown が経過しているかを返します。
+// key: サービス・メトリクス・種類
+// now: 評価したステータスの時刻
+func (uc *anomalyUseCase) shouldRaise(key anomalyAlertKey, now time.Time) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	last, ok := uc.lastRaised[key]
+	return !ok || now.Sub(last) >= uc.settings.AlertCooldown
+}
+
+// markRaisedは、アラートを発報した時刻を記録します。
+// key: サービス・メトリクス・種類
+// now: 評価したステータスの時刻
+func (uc *anomalyUseCase) markRaised(key anomalyAlertKey, now time.Time) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.lastRaised[key] = now
+}
+
+// forecastDiskは、ディスク使用量のトレンドから閾値に到達する時刻を予測します。
+// serviceName: サービス名
+// history: 時系列順のステータス履歴
+// now: 予測の基準時刻
+func (uc *anomalyUseCase) forecastDisk(serviceName string, history []entities.ServiceStatus, now time.Time) (entities.Anomaly, bool) {
+	// 予測に使用する期間の履歴に絞り込む
+	var recent []entities.ServiceStatus
+	for _, s := range history {
+		if !s.Timestamp.Before(now.Add(-uc.settings.ForecastWindow)) && !s.Timestamp.After(now) {
+			recent = append(recent, s)
+		}
+	}
+	trend, ok := anomaly.FitTrend(toSeries(recent, func(s entities.ServiceStatus) float64 { return s.DiskUsage }))
+	if !ok {
+		return entities.Anomaly{}, false
+	}
+	predictedAt, ok := trend.TimeToReach(uc.settings.DiskThreshold, now)
+	if !ok {
+		return entities.Anomaly{}, false
+	}
+	days := predictedAt.Sub(now).Hours() / 24
+	return entities.Anomaly{
+		ServiceName: serviceName,
+		Metric:      "disk",
+		Kind:        entities.AnomalyKindForecast,
+		Timestamp:   now,
+		Value:       trend.ValueAt(now),
+		Expected:    uc.settings.DiskThreshold,
+		PredictedAt: &predictedAt,
+		Message:     fmt.Sprintf("disk of %s will reach %.0f%% in %.1f days", serviceName, uc.settings.DiskThreshold, days),
+	}, true
+}
+
+// toSeriesは、ステータス履歴からメトリクスの時系列を取り出します。
+// メトリクスを持たないプローブの結果は除外します。
+// history: ステータス履歴
+// value: ステータスから値を取り出す関数
+func toSeries(history []entities.ServiceStatus, value func(entities.ServiceStatus) float64) []anomaly.Point {
+	series := make([]anomaly.Point, 0, len(history))
+	for _, s := range history {
+		if s.Probe != nil {
+			continue
+		}
+		series = append(series, anomaly.Point{Time: s.Timestamp, Value: value(s)})
+	}
+	return series
+}
+
+// deviationAnomalyは、検知結果をベースラインからの逸脱を表す異常に変換します。
+// serviceName: サービス名
+// metric: メトリクス名
+// result: 検知結果
+func deviationAnomaly(serviceName string, metric string, result anomaly.Result) entities.Anomaly {
+	return entities.Anomaly{
+		ServiceName: serviceName,
+		Metric:      metric,
+		Kind:        entities.AnomalyKindDeviation,
+		Timestamp:   result.Point.Time,
+		Value:       result.Point.Value,
+		Expected:    result.Expected,
+		ZScore:      result.ZScore,
+		Message:     fmt.Sprintf("%s of %s is %.1f (expected %.1f, z=%.1f)", metric, serviceName, result.Point.Value, result.Expected, result.ZScore),
+	}
+}
+
+// toAlertは、異常をアラートに変換します。
+// a: 異常
+func toAlert(a entities.Anomaly) entities.Alert {
+	return entities.Alert{
+		ServiceName: a.ServiceName,
+		Source:      "anomaly",
+		Severity:    entities.AlertSeverityWarning,
+		Message:     a.Message,
+		Timestamp:   a.Timestamp,
+	}
+}
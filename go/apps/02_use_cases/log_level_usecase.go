@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	entities "no-code-app/apps/03_entities"
+	logger "no-code-app/apps/10_utils/log"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type LogLevelUseCase interface {
+	// ユーザーがログレベルを参照、変更できるかを判定するメソッド
+	CanManage(userID string) bool
+	// 現在のログレベルを取得するメソッド
+	GetLogLevels() entities.LogLevels
+	// ログレベルを変更し、変更後のログレベルを返すメソッド
+	UpdateLogLevels(ctx context.Context, levels entities.LogLevels) (entities.LogLevels, error)
+}
+
+type logLevelUseCase struct {
+	// 変更を排他制御するミューテックス（同時の変更でコンポーネントの設定が失われないようにする）
+	mu sync.Mutex
+	// ログレベルの変更を許可するユーザー（空の場合は誰も変更できない）
+	adminUsers map[string]bool
+}
+
+// NewLogLevelUseCaseは、既定のロガーのログレベルを実行中に変更する LogLevelUseCase を初期化します。
+// adminUsers: ログレベルの変更を許可するユーザー（空の場合は誰も変更できない）
+func NewLogLevelUseCase(adminUsers []string) LogLevelUseCase {
+	uc := &logLevelUseCase{adminUsers: make(map[string]bool)}
+	for _, userID := range adminUsers {
+		uc.adminUsers[userID] = true
+	}
+	return uc
+}
+
+// CanManageは、ユーザーがログレベルを参照、変更できるかを判定します。
+// 許可するユーザーが設定されていない場合は、認証済みのユーザーでも許可しません。
+func (uc *logLevelUseCase) CanManage(userID string) bool {
+	if userID == "" {
+		return false
+	}
+	return uc.adminUsers[userID]
+}
+
+// GetLogLevelsは、既定のロガーのログレベルとコンポーネントごとのログレベルを取得します。
+func (uc *logLevelUseCase) GetLogLevels() entities.LogLevels {
+	levels := entities.LogLevels{
+		Level:      strings.ToLower(logger.GetLogLevel().String()),
+		Components: make(map[string]string),
+	}
+	for component, level := range logger.ComponentLevels() {
+		levels.Components[component] = strings.ToLower(level.String())
+	}
+	return levels
+}
+
+// UpdateLogLevelsは、既定のロガーのログレベルを変更します。
+// Level が空の場合はログレベルを変更しません。Components に指定したコンポーネントのみ変更し、
+// ログレベルが空のコンポーネントは設定を削除します（以降は Level を適用します）。
+// 不正なログレベルが含まれる場合は、何も変更せずにエラーを返します。
+func (uc *logLevelUseCase) UpdateLogLevels(ctx context.Context, levels entities.LogLevels) (entities.LogLevels, error) {
+	var level *logger.LogLevel
+	if levels.Level != "" {
+		parsed, err := logger.ParseLogLevel(levels.Level)
+		if err != nil {
+			return entities.LogLevels{}, err
+		}
+		level = &parsed
+	}
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	components := logger.ComponentLevels()
+	if components == nil {
+		components = make(map[string]logger.LogLevel)
+	}
+	for _, component := range slices.Sorted(maps.Keys(levels.Components)) {
+		name := levels.Components[component]
+		if component == "" {
+			return entities.LogLevels{}, fmt.Errorf("component name is required")
+		}
+		if name == "" {
+			delete(components, component)
+			continue
+		}
+		parsed, err := logger.ParseLogLevel(name)
+		if err != nil {
+			return entities.LogLevels{}, fmt.Errorf("component %s: %v", component, err)
+		}
+		components[component] = parsed
+	}
+	if level != nil {
+		logger.SetLogLevel(*level)
+	}
+	logger.SetComponentLevels(components)
+
+	updated := uc.GetLogLevels()
+	// 変更を追跡できるよう、INFOを抑制している場合も出力されるWARNで記録する
+	logger.Ctx(ctx).With("level", updated.Level, "components", updated.Components).Warn("log levels changed")
+	return updated, nil
+}
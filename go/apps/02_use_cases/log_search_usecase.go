@@ -0,0 +1,218 @@
+package usecases
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	logger "no-code-app/apps/10_utils/log"
+	"strconv"
+	"time"
+)
+
+// ログの検索の件数の上限
+const (
+	// 1ページの既定の件数
+	defaultLogPageSize = 100
+	// 1ページの最大件数
+	maxLogPageSize = 1000
+	// エクスポートの最大件数
+	maxLogExportSize = 100000
+)
+
+// ErrInvalidLogQueryは、ログの検索条件やカーソルが不正な場合のエラーです。
+var ErrInvalidLogQuery = errors.New("invalid log query")
+
+// ライブテールで新しいログを取得する間隔
+const logTailPollInterval = 2 * time.Second
+
+type LogSearchUseCase interface {
+	// ユーザーがログを検索できるかを判定するメソッド
+	CanSearch(userID string) bool
+	// 条件に一致するログを新しい順に1ページ検索するメソッド（cursor は前のページの NextCursor）
+	Search(ctx context.Context, query entities.LogQuery, cursor string) (entities.LogPage, error)
+	// 条件に一致するログを新しい順に全件（上限まで）書き込むメソッド
+	Export(ctx context.Context, query entities.LogQuery, write func(entities.LogEntry) error) error
+	// 条件に一致する新しいログを古い順に配信するメソッド（コンテキストがキャンセルされるとチャネルが閉じられる）
+	Tail(ctx context.Context, query entities.LogQuery) (<-chan entities.LogEntry, error)
+}
+
+type logSearchUseCase struct {
+	// ログのリポジトリ
+	repo interfaces.LogRepository
+	// 検索を許可するユーザー（空の場合は誰も検索できない）
+	allowedUsers map[string]bool
+}
+
+// NewLogSearchUseCaseは、cm_t_log テーブルのログを検索する LogSearchUseCase を初期化します。
+// repo: ログのリポジトリ
+// allowedUsers: 検索を許可するユーザー（空の場合は誰も検索できない）
+func NewLogSearchUseCase(repo interfaces.LogRepository, allowedUsers []string) LogSearchUseCase {
+	uc := &logSearchUseCase{repo: repo, allowedUsers: make(map[string]bool)}
+	for _, userID := range allowedUsers {
+		uc.allowedUsers[userID] = true
+	}
+	return uc
+}
+
+// CanSearchは、ユーザーがログを検索できるかを判定します。
+// 許可するユーザーが設定されていない場合は、認証済みのユーザーでも許可しません。
+func (uc *logSearchUseCase) CanSearch(userID string) bool {
+	if userID == "" {
+		return false
+	}
+	return uc.allowedUsers[userID]
+}
+
+// Searchは、条件に一致するログを新しい順に1ページ検索します。
+// 件数は query.Limit（省略時は100件、最大1000件）です。次のページがある場合は NextCursor を返します。
+func (uc *logSearchUseCase) Search(ctx context.Context, query entities.LogQuery, cursor string) (entities.LogPage, error) {
+	query, err := normalizeLogQuery(query)
+	if err != nil {
+		return entities.LogPage{}, err
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultLogPageSize
+	}
+	query.Limit = min(query.Limit, maxLogPageSize)
+	query.AfterID = 0
+	if query.BeforeID, err = decodeLogCursor(cursor); err != nil {
+		return entities.LogPage{}, err
+	}
+
+	// 次のページの有無を判定するため、1件多く検索する
+	limit := query.Limit
+	query.Limit++
+	entries, err := uc.repo.SearchLogs(ctx, query)
+	if err != nil {
+		return entities.LogPage{}, err
+	}
+	page := entities.LogPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextCursor = encodeLogCursor(page.Entries[limit-1].LogID)
+	}
+	return page, nil
+}
+
+// Exportは、条件に一致するログを新しい順に、最大100000件まで1件ずつ書き込みます。
+// 書き込みがエラーを返した場合は、検索を中止してエラーを返します。
+func (uc *logSearchUseCase) Export(ctx context.Context, query entities.LogQuery, write func(entities.LogEntry) error) error {
+	query, err := normalizeLogQuery(query)
+	if err != nil {
+		return err
+	}
+	query.AfterID = 0
+	for exported := 0; exported < maxLogExportSize; {
+		query.Limit = min(maxLogPageSize, maxLogExportSize-exported)
+		entries, err := uc.repo.SearchLogs(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := write(entry); err != nil {
+				return err
+			}
+		}
+		exported += len(entries)
+		if len(entries) < query.Limit {
+			return nil
+		}
+		query.BeforeID = entries[len(entries)-1].LogID
+	}
+	return nil
+}
+
+// Tailは、呼び出し以降に記録された条件に一致するログを、2秒ごとに検索して古い順に配信します。
+// 検索に失敗した場合は、ログに出力して次の間隔で再試行します。
+func (uc *logSearchUseCase) Tail(ctx context.Context, query entities.LogQuery) (<-chan entities.LogEntry, error) {
+	query, err := normalizeLogQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	// 呼び出し時点の最新のログより新しいログを配信する
+	if query.AfterID, err = uc.repo.LatestLogID(ctx); err != nil {
+		return nil, err
+	}
+	query.BeforeID = 0
+	query.Limit = maxLogPageSize
+
+	entries := make(chan entities.LogEntry)
+	go func() {
+		defer close(entries)
+		ticker := time.NewTicker(logTailPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// 1回の検索で取得しきれない場合は、続けて検索する
+			for {
+				found, err := uc.repo.SearchLogs(ctx, query)
+				if err != nil {
+					if ctx.Err() == nil {
+						logger.Ctx(ctx).With("error", err).Warn("failed to tail logs")
+					}
+					break
+				}
+				for _, entry := range found {
+					select {
+					case entries <- entry:
+					case <-ctx.Done():
+						return
+					}
+					query.AfterID = entry.LogID
+				}
+				if len(found) < query.Limit {
+					break
+				}
+			}
+		}
+	}()
+	return entries, nil
+}
+
+// normalizeLogQueryは、検索条件のログレベルを cm_t_log に記録する形式（大文字）に変換します。
+// 不正なログレベルや期間の場合は ErrInvalidLogQuery のエラーを返します。
+func normalizeLogQuery(query entities.LogQuery) (entities.LogQuery, error) {
+	levels := make([]string, 0, len(query.Levels))
+	for _, name := range query.Levels {
+		level, err := logger.ParseLogLevel(name)
+		if err != nil {
+			return query, fmt.Errorf("%w: %v", ErrInvalidLogQuery, err)
+		}
+		levels = append(levels, level.String())
+	}
+	query.Levels = levels
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return query, fmt.Errorf("%w: from must be before to", ErrInvalidLogQuery)
+	}
+	return query, nil
+}
+
+// encodeLogCursorは、ログIDを次のページのカーソルにします。
+// id: ページの最後のログID
+func encodeLogCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// decodeLogCursorは、カーソルからログIDを取得します。空のカーソルは0（最新から）とします。
+// cursor: 前のページの NextCursor
+func decodeLogCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid cursor", ErrInvalidLogQuery)
+	}
+	id, err := strconv.ParseInt(string(decoded), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: invalid cursor", ErrInvalidLogQuery)
+	}
+	return id, nil
+}
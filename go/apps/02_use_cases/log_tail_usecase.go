@@ -0,0 +1,219 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	"no-code-app/apps/10_utils/requestid"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ログの購読者ごとのチャネルのバッファサイズ
+const logSubscriberBufferSize = 256
+
+// 追従が切断された場合の再接続の最大待機時間
+const maxTailRetryDelay = time.Minute
+
+type LogTailUseCase interface {
+	// コンテキストがキャンセルされるまで対象サービスのログを追従するメソッド
+	Run(ctx context.Context)
+	// ユーザーがサービスのログを購読できるかを判定するメソッド
+	CanSubscribe(userID string, serviceName string) bool
+	// 絞り込み条件に一致するログを購読するメソッド（直近の履歴、新しい行のチャネル、購読解除の関数を返す）
+	Subscribe(serviceName string, filter entities.LogFilter) ([]entities.LogLine, <-chan entities.LogLine, func(), error)
+}
+
+// logSubscriberは、ログの購読者を表します。
+type logSubscriber struct {
+	// 行を受け取るチャネル
+	ch chan entities.LogLine
+	// 絞り込み条件
+	match func(string) bool
+}
+
+// logStreamは、サービスごとのログの履歴と購読者を表します。
+type logStream struct {
+	// 排他制御用のミューテックス
+	mu sync.Mutex
+	// 直近の行の履歴
+	history []entities.LogLine
+	// 購読者
+	subscribers map[*logSubscriber]struct{}
+}
+
+type logTailUseCase struct {
+	// ログ追従のリポジトリ
+	repo interfaces.LogTailRepository
+	// サービスごとに保持する履歴の行数
+	historySize int
+	// 購読を許可するユーザー（空の場合は誰も購読できない）
+	allowedUsers map[string]bool
+	// サービス名ごとのログ
+	streams map[string]*logStream
+}
+
+// NewLogTailUseCaseは、新しいLogTailUseCaseを初期化します。
+// repo: ログ追従のリポジトリ
+// serviceNames: ログを追従するサービス名
+// historySize: サービスごとに保持する履歴の行数
+// allowedUsers: 購読を許可するユーザー（空の場合は誰も購読できない）
+func NewLogTailUseCase(repo interfaces.LogTailRepository, serviceNames []string, historySize int, allowedUsers []string) LogTailUseCase {
+	uc := &logTailUseCase{
+		repo:         repo,
+		historySize:  historySize,
+		allowedUsers: make(map[string]bool),
+		streams:      make(map[string]*logStream),
+	}
+	for _, serviceName := range serviceNames {
+		uc.streams[serviceName] = &logStream{subscribers: make(map[*logSubscriber]struct{})}
+	}
+	for _, userID := range allowedUsers {
+		uc.allowedUsers[userID] = true
+	}
+	return uc
+}
+
+// Runは、コンテキストがキャンセルされるまで対象サービスのログを追従します。
+// 切断された場合は待機時間を延ばしながら再接続します。
+func (uc *logTailUseCase) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for serviceName, stream := range uc.streams {
+		wg.Add(1)
+		go func(serviceName string, stream *logStream) {
+			defer wg.Done()
+			delay := time.Second
+			for ctx.Err() == nil {
+				started := time.Now()
+				// エージェントのログと突き合わせられるよう接続ごとにリクエストIDを付与する
+				tailCtx := requestid.NewContext(ctx, requestid.New())
+				err := uc.repo.TailLogs(tailCtx, serviceName, stream.append(uc.historySize))
+				if ctx.Err() != nil {
+					return
+				}
+				// 一定時間以上接続できていた場合は待機時間を戻す
+				if time.Since(started) > maxTailRetryDelay {
+					delay = time.Second
+				}
+				monitorLogger(tailCtx).With("service", serviceName, "error", err, "retry_in", delay).Warn("log tail disconnected")
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+				if delay *= 2; delay > maxTailRetryDelay {
+					delay = maxTailRetryDelay
+				}
+			}
+		}(serviceName, stream)
+	}
+	wg.Wait()
+
+	// 全ての購読者に終了を通知
+	for _, stream := range uc.streams {
+		stream.closeAll()
+	}
+}
+
+// CanSubscribeは、ユーザーがサービスのログを購読できるかを判定します。
+// 許可するユーザーが設定されていない場合は、認証済みのユーザーでも許可しません。
+func (uc *logTailUseCase) CanSubscribe(userID string, serviceName string) bool {
+	if userID == "" {
+		return false
+	}
+	if _, ok := uc.streams[serviceName]; !ok {
+		return false
+	}
+	return uc.allowedUsers[userID]
+}
+
+// Subscribeは、絞り込み条件に一致するログを購読します。
+// 条件に一致する直近の履歴、新しい行を受け取るチャネル、購読を解除する関数を返します。
+func (uc *logTailUseCase) Subscribe(serviceName string, filter entities.LogFilter) ([]entities.LogLine, <-chan entities.LogLine, func(), error) {
+	stream, ok := uc.streams[serviceName]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("log tailing is not enabled for %s", serviceName)
+	}
+	match, err := compileLogFilter(filter)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	subscriber := &logSubscriber{ch: make(chan entities.LogLine, logSubscriberBufferSize), match: match}
+
+	stream.mu.Lock()
+	// 履歴の取得と購読の開始を同時に行い、行の取りこぼしを防ぐ
+	var history []entities.LogLine
+	for _, line := range stream.history {
+		if match(line.Line) {
+			history = append(history, line)
+		}
+	}
+	stream.subscribers[subscriber] = struct{}{}
+	stream.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			stream.mu.Lock()
+			defer stream.mu.Unlock()
+			if _, ok := stream.subscribers[subscriber]; ok {
+				delete(stream.subscribers, subscriber)
+				close(subscriber.ch)
+			}
+		})
+	}
+	return history, subscriber.ch, unsubscribe, nil
+}
+
+// appendは、行を履歴に追加して購読者に配信する関数を返します。
+// historySize: 保持する履歴の行数
+func (s *logStream) append(historySize int) func(entities.LogLine) {
+	return func(line entities.LogLine) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		// 上限を超えた古い行を破棄
+		s.history = append(s.history, line)
+		if len(s.history) > historySize {
+			s.history = s.history[len(s.history)-historySize:]
+		}
+		for subscriber := range s.subscribers {
+			if !subscriber.match(line.Line) {
+				continue
+			}
+			select {
+			case subscriber.ch <- line:
+			default:
+				// 受信が追いつかない購読者には配信しない
+			}
+		}
+	}
+}
+
+// closeAllは、全ての購読者のチャネルを閉じます。
+func (s *logStream) closeAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for subscriber := range s.subscribers {
+		close(subscriber.ch)
+		delete(s.subscribers, subscriber)
+	}
+}
+
+// compileLogFilterは、絞り込み条件を行の判定関数に変換します。
+// filter: 絞り込み条件
+func compileLogFilter(filter entities.LogFilter) (func(string) bool, error) {
+	if filter.Pattern == "" {
+		return func(string) bool { return !filter.Invert }, nil
+	}
+	pattern := filter.Pattern
+	if filter.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %v", err)
+	}
+	return func(line string) bool { return re.MatchString(line) != filter.Invert }, nil
+}
@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	"sort"
+	"sync"
+	"time"
+)
+
+type MaintenanceUseCase interface {
+	// メンテナンス期間をカレンダーから同期するメソッド
+	SyncMaintenanceWindows() error
+	// 定期的にメンテナンス期間を同期するメソッド
+	RunSync(ctx context.Context, interval time.Duration)
+	// 指定時刻にサービスがメンテナンス中かを判定するメソッド
+	IsUnderMaintenance(serviceName string, t time.Time) bool
+	// 指定期間に重なるサービスのメンテナンス期間を取得するメソッド
+	GetMaintenanceWindows(serviceName string, from time.Time, to time.Time) []entities.MaintenanceWindow
+}
+
+type maintenanceUseCase struct {
+	// リポジトリのインターフェース
+	repo interfaces.MaintenanceWindowRepository
+	// 同期対象とする過去の期間
+	lookBehind time.Duration
+	// 同期対象とする未来の期間
+	lookAhead time.Duration
+	// 排他制御用のミューテックス
+	mu sync.RWMutex
+	// 同期済みのメンテナンス期間
+	windows []entities.MaintenanceWindow
+}
+
+// NewMaintenanceUseCaseは、新しいMaintenanceUseCaseを初期化します。
+// repo: メンテナンス期間のリポジトリ
+// lookBehind: 同期対象とする過去の期間（稼働率の集計期間をカバーする長さ）
+// lookAhead: 同期対象とする未来の期間
+func NewMaintenanceUseCase(repo interfaces.MaintenanceWindowRepository, lookBehind time.Duration, lookAhead time.Duration) MaintenanceUseCase {
+	return &maintenanceUseCase{repo: repo, lookBehind: lookBehind, lookAhead: lookAhead}
+}
+
+// SyncMaintenanceWindowsは、メンテナンス期間をカレンダーから同期します。
+func (uc *maintenanceUseCase) SyncMaintenanceWindows() error {
+	now := time.Now()
+	windows, err := uc.repo.GetMaintenanceWindows(now.Add(-uc.lookBehind), now.Add(uc.lookAhead))
+	if err != nil {
+		return fmt.Errorf("failed to sync maintenance windows: %v", err)
+	}
+	// 開始日時順に並べ替えて保持
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+
+	uc.mu.Lock()
+	uc.windows = windows
+	uc.mu.Unlock()
+	monitorLogger(context.Background()).With("windows", len(windows)).Debug("synced maintenance windows")
+	return nil
+}
+
+// RunSyncは、コンテキストがキャンセルされるまで定期的にメンテナンス期間を同期します。
+// ctx: コンテキスト
+// interval: 同期間隔
+func (uc *maintenanceUseCase) RunSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// 同期に失敗した場合は前回の結果を保持したまま次回に再試行
+		if err := uc.SyncMaintenanceWindows(); err != nil {
+			monitorLogger(ctx).With("error", err).Warn("maintenance window sync failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsUnderMaintenanceは、指定時刻にサービスがメンテナンス中かを判定します。
+func (uc *maintenanceUseCase) IsUnderMaintenance(serviceName string, t time.Time) bool {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	for _, window := range uc.windows {
+		if window.Covers(serviceName, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMaintenanceWindowsは、指定期間に重なるサービスのメンテナンス期間を取得します。
+func (uc *maintenanceUseCase) GetMaintenanceWindows(serviceName string, from time.Time, to time.Time) []entities.MaintenanceWindow {
+	uc.mu.RLock()
+	defer uc.mu.RUnlock()
+	var result []entities.MaintenanceWindow
+	for _, window := range uc.windows {
+		if window.AppliesTo(serviceName) && window.Start.Before(to) && window.End.After(from) {
+			result = append(result, window)
+		}
+	}
+	return result
+}
@@ -1,26 +1,277 @@
 package usecases
 
 import (
+	"context"
+	"fmt"
 	entities "no-code-app/apps/03_entities"
 	interfaces "no-code-app/apps/05_interfaces"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/apps/10_utils/requestid"
+	"sort"
+	"sync"
+	"time"
 )
 
 type MonitoringUseCase interface {
-	// サービスステータスを取得するメソッド
-	GetServiceStatus(serviceName string) (entities.ServiceStatus, error)
+	// サービスステータスを取得するメソッド（履歴への記録やアラートの発報は行わない）
+	GetServiceStatus(ctx context.Context, serviceName string) (entities.ServiceStatus, error)
+	// サービスステータスを履歴に記録し、必要に応じてアラートを発報するメソッド
+	RecordServiceStatus(status entities.ServiceStatus) error
+	// 指定期間のサービスの稼働率を取得するメソッド
+	GetUptime(serviceName string, from time.Time, to time.Time) (entities.Uptime, error)
+	// 指定期間のメトリクスの異常を取得するメソッド
+	GetAnomalies(serviceName string, from time.Time, to time.Time) ([]entities.Anomaly, error)
+	// 記録されたサービスステータスを購読するメソッド（戻り値の関数で購読を解除）
+	Subscribe() (<-chan entities.ServiceStatus, func())
+	// コンテキストがキャンセルされるまでサービスステータスを定期的に取得するメソッド
+	RunPolling(ctx context.Context, serviceNames []string, interval time.Duration)
 }
 
+// 購読者ごとのチャネルのバッファサイズ
+const subscriberBufferSize = 64
+
 type monitoringUseCase struct {
 	// リポジトリのインターフェース
 	repo interfaces.MonitoringRepository
+	// ステータス履歴のリポジトリ
+	historyRepo interfaces.StatusHistoryRepository
+	// メンテナンスのユースケース（nilの場合はメンテナンス期間を考慮しない）
+	maintenance MaintenanceUseCase
+	// アラートのユースケース（nilの場合はアラートを発報しない）
+	alerts AlertUseCase
+	// 異常検知のユースケース（nilの場合は異常検知を行わない）
+	anomalies AnomalyUseCase
+	// 購読者を保護するミューテックス
+	mu sync.Mutex
+	// 記録されたサービスステータスの購読者
+	subscribers map[chan entities.ServiceStatus]struct{}
 }
 
 // NewMonitoringUseCaseは、新しいMonitoringUseCaseを初期化します。
-func NewMonitoringUseCase(repo interfaces.MonitoringRepository) MonitoringUseCase {
-	return &monitoringUseCase{repo: repo}
+// repo: サービスステータスのリポジトリ
+// historyRepo: ステータス履歴のリポジトリ
+// maintenance: メンテナンスのユースケース
+// alerts: アラートのユースケース
+// anomalies: 異常検知のユースケース
+func NewMonitoringUseCase(repo interfaces.MonitoringRepository, historyRepo interfaces.StatusHistoryRepository, maintenance MaintenanceUseCase, alerts AlertUseCase, anomalies AnomalyUseCase) MonitoringUseCase {
+	return &monitoringUseCase{
+		repo:        repo,
+		historyRepo: historyRepo,
+		maintenance: maintenance,
+		alerts:      alerts,
+		anomalies:   anomalies,
+		subscribers: make(map[chan entities.ServiceStatus]struct{}),
+	}
+}
+
+// GetServiceStatusは、指定されたサービスのステータスをエージェントから取得します。
+// 履歴への記録とアラートの発報は RunPolling で行うため、取得のみを行います。
+func (uc *monitoringUseCase) GetServiceStatus(ctx context.Context, serviceName string) (entities.ServiceStatus, error) {
+	return uc.repo.GetServiceStatus(ctx, serviceName)
+}
+
+// RecordServiceStatusは、サービスステータスを履歴に記録し、停止を検知すればアラートを発報します。
+// アラートの通知に失敗しても記録は成功しているため、エラーはログに出力して返しません。
+func (uc *monitoringUseCase) RecordServiceStatus(status entities.ServiceStatus) error {
+	if err := uc.historyRepo.SaveServiceStatus(status); err != nil {
+		return fmt.Errorf("failed to save service status: %v", err)
+	}
+	// 購読者に配信
+	uc.publish(status)
+	// サービス停止を検知した場合はアラートを発報
+	if !status.IsUp && uc.alerts != nil {
+		alert := entities.Alert{
+			ServiceName: status.ServiceName,
+			Source:      "status",
+			Severity:    entities.AlertSeverityCritical,
+			Message:     fmt.Sprintf("service %s on %s is down", status.ServiceName, status.PCName),
+			Timestamp:   status.Timestamp,
+		}
+		// プローブの場合は失敗理由を含める
+		if status.Probe != nil {
+			alert.Source = "probe"
+			alert.Message = fmt.Sprintf("%s probe of %s failed: %s", status.Probe.Type, status.ServiceName, status.Probe.FailureReason)
+		}
+		if _, err := uc.alerts.Raise(alert); err != nil {
+			monitorLogger(context.Background()).With("service", status.ServiceName, "error", err).Warn("failed to raise service down alert")
+		}
+	}
+	return nil
+}
+
+// GetUptimeは、指定期間のサービスの稼働率をメンテナンス期間を除外して算出します。
+func (uc *monitoringUseCase) GetUptime(serviceName string, from time.Time, to time.Time) (entities.Uptime, error) {
+	history, err := uc.historyRepo.GetServiceStatusHistory(serviceName, from, to)
+	if err != nil {
+		return entities.Uptime{}, err
+	}
+	var windows []entities.MaintenanceWindow
+	if uc.maintenance != nil {
+		windows = uc.maintenance.GetMaintenanceWindows(serviceName, from, to)
+	}
+	return calculateUptime(serviceName, history, windows, from, to), nil
+}
+
+// GetAnomaliesは、指定期間のメトリクスの異常を取得します。
+func (uc *monitoringUseCase) GetAnomalies(serviceName string, from time.Time, to time.Time) ([]entities.Anomaly, error) {
+	if uc.anomalies == nil {
+		return []entities.Anomaly{}, nil
+	}
+	return uc.anomalies.DetectAnomalies(serviceName, from, to)
+}
+
+// Subscribeは、記録されたサービスステータスを購読します。戻り値の関数で購読を解除します。
+// 受信が追いつかない購読者へのステータスは破棄されます。
+func (uc *monitoringUseCase) Subscribe() (<-chan entities.ServiceStatus, func()) {
+	ch := make(chan entities.ServiceStatus, subscriberBufferSize)
+	uc.mu.Lock()
+	uc.subscribers[ch] = struct{}{}
+	uc.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			uc.mu.Lock()
+			delete(uc.subscribers, ch)
+			uc.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// RunPollingは、コンテキストがキャンセルされるまでサービスステータスを定期的に取得して記録し、
+// 停止や異常を検知すればアラートを発報します。
+// serviceNames: 取得するサービス名
+// interval: 取得間隔
+func (uc *monitoringUseCase) RunPolling(ctx context.Context, serviceNames []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, serviceName := range serviceNames {
+			// エージェントのログと突き合わせられるよう取得ごとにリクエストIDを付与する
+			pollCtx := requestid.NewContext(ctx, requestid.New())
+			// 取得に失敗したサービスがあっても他のサービスの取得は継続する
+			status, err := uc.GetServiceStatus(pollCtx, serviceName)
+			if err != nil {
+				monitorLogger(pollCtx).With("service", serviceName, "error", err).Warn("failed to poll service status")
+				continue
+			}
+			if err := uc.RecordServiceStatus(status); err != nil {
+				monitorLogger(pollCtx).With("service", serviceName, "error", err).Warn("failed to record service status")
+				continue
+			}
+			// メトリクスの異常は取得間隔ごとに評価する（APIでの取得やプローブの結果では評価しない）
+			if uc.anomalies != nil {
+				if _, err := uc.anomalies.Evaluate(status); err != nil {
+					monitorLogger(pollCtx).With("service", serviceName, "error", err).Warn("failed to evaluate anomalies")
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishは、サービスステータスを全ての購読者に配信します。
+// status: 配信するサービスステータス
+func (uc *monitoringUseCase) publish(status entities.ServiceStatus) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	for ch := range uc.subscribers {
+		select {
+		case ch <- status:
+		default:
+			// バッファが一杯の購読者には配信しない
+		}
+	}
+}
+
+// calculateUptimeは、各ステータスが次のステータスまで継続したものとして稼働率を算出します。
+// serviceName: サービス名
+// history: 時系列順のステータス履歴
+// windows: 除外するメンテナンス期間
+// from: 集計開始日時
+// to: 集計終了日時
+func calculateUptime(serviceName string, history []entities.ServiceStatus, windows []entities.MaintenanceWindow, from time.Time, to time.Time) entities.Uptime {
+	uptime := entities.Uptime{ServiceName: serviceName, From: from, To: to, Ratio: 1}
+	for i, status := range history {
+		// 区間の開始と終了を集計期間内に収める
+		start := status.Timestamp
+		if start.Before(from) {
+			start = from
+		}
+		end := to
+		if i+1 < len(history) {
+			end = history[i+1].Timestamp
+		}
+		if !end.After(start) {
+			continue
+		}
+		// メンテナンス期間と重なる時間を除外
+		maintenance := overlapDuration(start, end, windows)
+		uptime.MaintenanceDuration += maintenance
+		if status.IsUp {
+			uptime.UpDuration += end.Sub(start) - maintenance
+		} else {
+			uptime.DownDuration += end.Sub(start) - maintenance
+		}
+	}
+	if total := uptime.UpDuration + uptime.DownDuration; total > 0 {
+		uptime.Ratio = float64(uptime.UpDuration) / float64(total)
+	}
+	return uptime
+}
+
+// overlapDurationは、区間とメンテナンス期間が重なる時間を重複を除いて算出します。
+// start: 区間の開始
+// end: 区間の終了
+// windows: メンテナンス期間
+func overlapDuration(start time.Time, end time.Time, windows []entities.MaintenanceWindow) time.Duration {
+	// 区間内に切り詰めたメンテナンス期間を収集
+	type interval struct{ start, end time.Time }
+	var intervals []interval
+	for _, window := range windows {
+		s, e := window.Start, window.End
+		if s.Before(start) {
+			s = start
+		}
+		if e.After(end) {
+			e = end
+		}
+		if e.After(s) {
+			intervals = append(intervals, interval{s, e})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+
+	// 重なり合う期間をまとめながら合計する
+	var total time.Duration
+	var current *interval
+	for i := range intervals {
+		if current != nil && !intervals[i].start.After(current.end) {
+			if intervals[i].end.After(current.end) {
+				current.end = intervals[i].end
+			}
+			continue
+		}
+		if current != nil {
+			total += current.end.Sub(current.start)
+		}
+		current = &intervals[i]
+	}
+	if current != nil {
+		total += current.end.Sub(current.start)
+	}
+	return total
 }
 
-// GetServiceStatusは、指定されたサービスのステータスを取得します。
-func (uc *monitoringUseCase) GetServiceStatus(serviceName string) (entities.ServiceStatus, error) {
-	return uc.repo.GetServiceStatus(serviceName)
+// monitorLoggerは、監視サービスのログを出力するロガー（component=monitor とコンテキストのリクエストIDなどを付与）を返します。
+// log.components.monitor でログレベルを変更できます。
+// ctx: コンテキスト
+func monitorLogger(ctx context.Context) *logger.Logger {
+	return logger.Ctx(ctx).With(logger.FieldComponent, "monitor")
 }
@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	"sync"
+	"time"
+)
+
+// 検証間隔が設定されていない場合のデフォルト値
+const defaultProbeInterval = time.Minute
+
+type ProbeUseCase interface {
+	// プローブを1回実行し、結果をサービスステータスとして記録するメソッド
+	RunProbe(ctx context.Context, target entities.ProbeTarget) (entities.ServiceStatus, error)
+	// コンテキストがキャンセルされるまで全ての検証対象を定期的に検証するメソッド
+	Run(ctx context.Context)
+}
+
+type probeUseCase struct {
+	// プローブの実行
+	checker interfaces.ProbeChecker
+	// 監視のユースケース（履歴・アラート・WebSocket配信）
+	monitoring MonitoringUseCase
+	// アラートのユースケース（nilの場合は証明書の期限切れ警告を発報しない）
+	alerts AlertUseCase
+	// 検証対象
+	targets []entities.ProbeTarget
+	// 証明書の有効期限がこの期間を切ったら警告を発報
+	certExpiryWarning time.Duration
+	// 警告を発報した証明書の有効期限を保護するミューテックス
+	mu sync.Mutex
+	// 検証対象ごとに警告を発報した証明書の有効期限
+	certWarned map[string]time.Time
+}
+
+// NewProbeUseCaseは、新しいProbeUseCaseを初期化します。
+// checker: プローブの実行
+// monitoring: 監視のユースケース
+// alerts: アラートのユースケース
+// targets: 検証対象
+// certExpiryWarning: 証明書の有効期限の警告を発報する残り期間
+func NewProbeUseCase(checker interfaces.ProbeChecker, monitoring MonitoringUseCase, alerts AlertUseCase, targets []entities.ProbeTarget, certExpiryWarning time.Duration) ProbeUseCase {
+	return &probeUseCase{checker: checker, monitoring: monitoring, alerts: alerts, targets: targets, certExpiryWarning: certExpiryWarning, certWarned: make(map[string]time.Time)}
+}
+
+// RunProbeは、プローブを1回実行し、結果をサービスステータスとして記録します。
+func (uc *probeUseCase) RunProbe(ctx context.Context, target entities.ProbeTarget) (entities.ServiceStatus, error) {
+	result := uc.checker.Check(ctx, target)
+	status := entities.ServiceStatus{
+		ServiceName: target.Name,
+		PCName:      target.Address,
+		IsUp:        result.Success,
+		Probe:       &result,
+		Timestamp:   time.Now(),
+	}
+	// エージェントのステータスと同じ履歴・アラート・配信に流す
+	if err := uc.monitoring.RecordServiceStatus(status); err != nil {
+		return status, err
+	}
+
+	// 証明書の有効期限が近い場合は警告を発報（同じ証明書の警告は検証対象ごとに1回のみ）
+	if uc.alerts != nil && result.CertExpiry != nil && uc.certExpiryWarning > 0 {
+		if remaining := result.CertExpiry.Sub(status.Timestamp); remaining <= uc.certExpiryWarning && !uc.certAlreadyWarned(target.Name, *result.CertExpiry) {
+			_, err := uc.alerts.Raise(entities.Alert{
+				ServiceName: target.Name,
+				Source:      "probe",
+				Severity:    entities.AlertSeverityWarning,
+				Message:     fmt.Sprintf("TLS certificate of %s expires in %.1f days", target.Address, remaining.Hours()/24),
+				Timestamp:   status.Timestamp,
+			})
+			if err == nil {
+				uc.markCertWarned(target.Name, *result.CertExpiry)
+			}
+			return status, err
+		}
+	}
+	return status, nil
+}
+
+// certAlreadyWarnedは、検証対象の同じ有効期限の証明書について警告を発報済みかを返します。
+// targetName: 検証対象の名前
+// expiry: 証明書の有効期限
+func (uc *probeUseCase) certAlreadyWarned(targetName string, expiry time.Time) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	warned, ok := uc.certWarned[targetName]
+	return ok && warned.Equal(expiry)
+}
+
+// markCertWarnedは、検証対象の証明書について警告を発報したことを記録します（証明書が更新されると再び警告する）。
+// targetName: 検証対象の名前
+// expiry: 証明書の有効期限
+func (uc *probeUseCase) markCertWarned(targetName string, expiry time.Time) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.certWarned[targetName] = expiry
+}
+
+// Runは、コンテキストがキャンセルされるまで全ての検証対象を定期的に検証します。
+func (uc *probeUseCase) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, target := range uc.targets {
+		wg.Add(1)
+		go func(target entities.ProbeTarget) {
+			defer wg.Done()
+			uc.runTarget(ctx, target)
+		}(target)
+	}
+	wg.Wait()
+}
+
+// runTargetは、1つの検証対象を検証間隔ごとに検証します。
+// ctx: コンテキスト
+// target: 検証対象
+func (uc *probeUseCase) runTarget(ctx context.Context, target entities.ProbeTarget) {
+	interval := target.Interval
+	if interval <= 0 {
+		interval = defaultProbeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// 記録に失敗しても次回の検証は継続する
+		if _, err := uc.RunProbe(ctx, target); err != nil {
+			monitorLogger(ctx).With("target", target.Name, "error", err).Warn("failed to record probe result")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package entities
+
+// エージェントへのリクエストの種類
+const (
+	// サービスステータスの取得
+	AgentRequestStatus = "status"
+	// ログファイルの追従（レスポンスは改行区切りのJSON形式のLogLine）
+	AgentRequestTail = "tail"
+)
+
+// AgentRequestは、監視サーバーからエージェントへのリクエストを表します。
+type AgentRequest struct {
+	// 監視サーバーとエージェントで共有する認証トークン
+	Token string
+	// リクエストの種類
+	Type string
+	// サービス名
+	ServiceName string
+	// 返却するプロセスの上限数（CPU使用率の高い順）
+	TopN int
+	// 監視サーバーのログと突き合わせるためのリクエストID
+	RequestID string
+	// 監視サーバーのスパンを親とするためのW3C Trace Context（traceparent、tracestate）
+	TraceContext map[string]string
+}
+
+// AgentResponseは、エージェントから監視サーバーへのレスポンスを表します。
+type AgentResponse struct {
+	// エラーメッセージ（成功した場合は空）
+	Error string
+	// サービスステータス
+	Status *ServiceStatus
+}
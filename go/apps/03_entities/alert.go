@@ -0,0 +1,32 @@
+package entities
+
+import "time"
+
+// アラートの重要度を表す型
+type AlertSeverity string
+
+// アラートの重要度の定数
+const (
+	// 情報
+	AlertSeverityInfo AlertSeverity = "info"
+	// 警告
+	AlertSeverityWarning AlertSeverity = "warning"
+	// 重大
+	AlertSeverityCritical AlertSeverity = "critical"
+)
+
+// Alertは、監視で検知されたアラートを表します。
+type Alert struct {
+	// サービス名
+	ServiceName string
+	// アラートの発生元（例: status, probe, anomaly）
+	Source string
+	// 重要度
+	Severity AlertSeverity
+	// メッセージ
+	Message string
+	// メンテナンス期間中のため抑止されたかどうか
+	Suppressed bool
+	// タイムスタンプ
+	Timestamp time.Time
+}
@@ -0,0 +1,36 @@
+package entities
+
+import "time"
+
+// 異常の種類を表す型
+type AnomalyKind string
+
+// 異常の種類の定数
+const (
+	// ベースラインからの逸脱
+	AnomalyKindDeviation AnomalyKind = "deviation"
+	// トレンドによる閾値到達の予測
+	AnomalyKindForecast AnomalyKind = "forecast"
+)
+
+// Anomalyは、メトリクスの時系列で検知された異常（APIの注釈）を表します。
+type Anomaly struct {
+	// サービス名
+	ServiceName string
+	// メトリクス名（cpu, memory, disk）
+	Metric string
+	// 異常の種類
+	Kind AnomalyKind
+	// 検知対象の時刻
+	Timestamp time.Time
+	// 計測値
+	Value float64
+	// ベースラインの期待値
+	Expected float64
+	// zスコア
+	ZScore float64
+	// 閾値に到達すると予測される時刻（予測の場合のみ）
+	PredictedAt *time.Time
+	// メッセージ
+	Message string
+}
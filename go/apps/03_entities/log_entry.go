@@ -0,0 +1,53 @@
+package entities
+
+import "time"
+
+// LogEntryは、cm_t_log テーブルに記録するログを表します。
+type LogEntry struct {
+	// ログID（記録時に採番される）
+	LogID int64
+	// プログラムID（例: monitor, login）
+	ProgramID string
+	// ログレベル（DEBUG, INFO, WARN, ERROR）
+	LogLevel string
+	// メッセージ
+	Message string
+	// クライアントIPアドレス
+	ClientIP string
+	// サーバーIPアドレス
+	ServerIP string
+	// 作成日時
+	CreatedAt time.Time
+	// 作成ユーザー
+	CreatedBy string
+}
+
+// LogQueryは、cm_t_log テーブルのログの検索条件を表します。空の条件では絞り込みません。
+type LogQuery struct {
+	// 作成日時の開始（この日時を含む）
+	From time.Time
+	// 作成日時の終了（この日時を含まない）
+	To time.Time
+	// ログレベル（いずれかに一致）
+	Levels []string
+	// プログラムID
+	ProgramID string
+	// クライアントIPアドレスまたはサーバーIPアドレス
+	IP string
+	// メッセージに含まれる文字列
+	Text string
+	// このログIDより古いログを新しい順に検索する（0の場合は最新から）
+	BeforeID int64
+	// このログIDより新しいログを古い順に検索する（ライブテールで使用。BeforeID より優先）
+	AfterID int64
+	// 最大件数
+	Limit int
+}
+
+// LogPageは、ログの検索結果の1ページを表します。
+type LogPage struct {
+	// ログ（新しい順）
+	Entries []LogEntry
+	// 次のページ（より古いログ）のカーソル（次のページがない場合は空）
+	NextCursor string
+}
@@ -0,0 +1,9 @@
+package entities
+
+// LogLevelsは、実行中のサービスのログレベルの設定を表します。
+type LogLevels struct {
+	// 出力する最小のログレベル（debug, info, warn, error）
+	Level string
+	// コンポーネント（ログの component フィールドの値）ごとの最小のログレベル
+	Components map[string]string
+}
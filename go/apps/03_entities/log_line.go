@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+// LogLineは、監視対象ホストのログファイルから読み取った1行を表します。
+type LogLine struct {
+	// サービス名
+	ServiceName string
+	// PC名
+	PCName string
+	// ログファイルのパス
+	File string
+	// 行の内容
+	Line string
+	// 読み取った時刻
+	Timestamp time.Time
+}
+
+// LogFilterは、grepと同様のログの絞り込み条件を表します。
+type LogFilter struct {
+	// 一致させる正規表現（空の場合は全ての行）
+	Pattern string
+	// 大文字と小文字を区別しないかどうか（grep -i）
+	IgnoreCase bool
+	// 一致しない行を対象とするかどうか（grep -v）
+	Invert bool
+}
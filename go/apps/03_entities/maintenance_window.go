@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// MaintenanceWindowは、アラートを抑止するメンテナンス期間を表します。
+type MaintenanceWindow struct {
+	// カレンダーイベントID
+	ID string
+	// 概要
+	Summary string
+	// 説明
+	Description string
+	// 対象サービス名のリスト（空の場合は概要・説明から判定）
+	ServiceNames []string
+	// 開始日時
+	Start time.Time
+	// 終了日時
+	End time.Time
+}
+
+// AppliesToは、メンテナンス期間が指定されたサービスを対象としているかを判定します。
+// serviceName: サービス名
+func (w MaintenanceWindow) AppliesTo(serviceName string) bool {
+	// 対象サービスが明示されている場合はリストで判定
+	if len(w.ServiceNames) > 0 {
+		for _, name := range w.ServiceNames {
+			if name == "*" || strings.EqualFold(name, serviceName) {
+				return true
+			}
+		}
+		return false
+	}
+	// 概要または説明にサービス名が含まれているかで判定
+	text := strings.ToLower(w.Summary + "\n" + w.Description)
+	return serviceName != "" && strings.Contains(text, strings.ToLower(serviceName))
+}
+
+// Coversは、指定された時刻が対象サービスのメンテナンス期間内かを判定します。
+// serviceName: サービス名
+// t: 判定する時刻
+func (w MaintenanceWindow) Covers(serviceName string, t time.Time) bool {
+	return w.AppliesTo(serviceName) && !t.Before(w.Start) && t.Before(w.End)
+}
@@ -0,0 +1,64 @@
+package entities
+
+import "time"
+
+// プローブの種類を表す型
+type ProbeType string
+
+// プローブの種類の定数
+const (
+	// HTTPのステータスコードとボディの検証
+	ProbeTypeHTTP ProbeType = "http"
+	// TCP接続の検証
+	ProbeTypeTCP ProbeType = "tcp"
+	// grpc.health.v1による検証
+	ProbeTypeGRPC ProbeType = "grpc"
+	// QUICハンドシェイクの検証
+	ProbeTypeQUIC ProbeType = "quic"
+)
+
+// ProbeTargetは、合成プローブの検証対象を表します。
+type ProbeTarget struct {
+	// サービス名（履歴やアラートのキーとなる）
+	Name string
+	// プローブの種類
+	Type ProbeType
+	// 検証先（HTTPの場合はURL、それ以外はhost:port）
+	Address string
+	// 検証間隔
+	Interval time.Duration
+	// タイムアウト
+	Timeout time.Duration
+	// HTTPメソッド
+	Method string
+	// 期待するHTTPステータスコード
+	ExpectedStatus int
+	// レスポンスボディが一致すべき正規表現
+	BodyPattern string
+	// gRPCのヘルスチェック対象サービス名
+	GRPCService string
+	// gRPCでTLSを使用するかどうか
+	TLS bool
+	// 証明書の検証を省略するかどうか
+	InsecureSkipVerify bool
+	// QUICのALPNで提示するプロトコル
+	NextProtos []string
+}
+
+// ProbeResultは、合成プローブの結果を表します。
+type ProbeResult struct {
+	// プローブの種類
+	Type ProbeType
+	// 検証先
+	Address string
+	// 成功したかどうか
+	Success bool
+	// 応答時間
+	Latency time.Duration
+	// TLS証明書の有効期限（TLSを使用しない場合はnil）
+	CertExpiry *time.Time
+	// HTTPステータスコード
+	StatusCode int
+	// 失敗理由
+	FailureReason string
+}
@@ -0,0 +1,15 @@
+package entities
+
+// ProcessInfoは、監視対象ホスト上のプロセスのリソース使用状況を表します。
+type ProcessInfo struct {
+	// プロセスID
+	PID int
+	// コマンドライン
+	Command string
+	// CPU使用率（%）
+	CPUPercent float64
+	// 常駐メモリサイズ（バイト）
+	RSS uint64
+	// 開いているファイルディスクリプタの数（取得できない場合は-1）
+	OpenFDs int
+}
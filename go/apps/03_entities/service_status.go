@@ -15,6 +15,14 @@ type ServiceStatus struct {
 	DiskUsage float64
 	// CPU使用量
 	CPUUsage float64
+	// 稼働中かどうか
+	IsUp bool
+	// リソース使用量の多いプロセス
+	Processes []ProcessInfo
+	// 起動しているべきなのに見つからなかったプロセス名
+	MissingProcesses []string
+	// 合成プローブの結果（エージェントから取得したステータスの場合はnil）
+	Probe *ProbeResult
 	// タイムスタンプ
 	Timestamp time.Time
 }
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// Uptimeは、指定期間におけるサービスの稼働率を表します。
+type Uptime struct {
+	// サービス名
+	ServiceName string
+	// 集計開始日時
+	From time.Time
+	// 集計終了日時
+	To time.Time
+	// 稼働時間
+	UpDuration time.Duration
+	// 停止時間
+	DownDuration time.Duration
+	// メンテナンスとして除外した時間
+	MaintenanceDuration time.Duration
+	// 稼働率（0〜1、集計対象がない場合は1）
+	Ratio float64
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	logger "no-code-app/apps/10_utils/log"
+)
+
+var _ interfaces.AlertNotifier = (*LogAlertNotifier)(nil)
+
+type LogAlertNotifier struct{}
+
+// NewLogAlertNotifierは、アラートをログに出力するLogAlertNotifierを初期化します。
+func NewLogAlertNotifier() *LogAlertNotifier {
+	return &LogAlertNotifier{}
+}
+
+// Notifyは、アラートを重要度に応じたレベルでログに出力します。
+func (n *LogAlertNotifier) Notify(alert entities.Alert) error {
+	message := fmt.Sprintf("alert: service=%s source=%s severity=%s message=%s", alert.ServiceName, alert.Source, alert.Severity, alert.Message)
+	switch alert.Severity {
+	case entities.AlertSeverityCritical:
+		logger.Error(message)
+	case entities.AlertSeverityWarning:
+		logger.Warn(message)
+	default:
+		logger.Info(message)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	logger "no-code-app/apps/10_utils/log"
+)
+
+var _ logger.BatchWriter = (*LogBatchWriter)(nil)
+
+// cm_t_log のカラムに記録するロガーのフィールドのキー
+const (
+	// プログラムID
+	logFieldProgramID = "program_id"
+	// クライアントIPアドレス
+	logFieldClientIP = "client_ip"
+	// サーバーIPアドレス
+	logFieldServerIP = "server_ip"
+	// 作成ユーザー（認証済みユーザーID）
+	logFieldUserID = "user_id"
+)
+
+type LogBatchWriter struct {
+	// ログのリポジトリ
+	logs interfaces.LogRepository
+	// フィールドにプログラムIDがない場合のプログラムID
+	programID string
+	// フィールドにサーバーIPアドレスがない場合のサーバーIPアドレス
+	serverIP string
+}
+
+// NewLogBatchWriterは、ロガーのログを cm_t_log テーブルに記録するLogBatchWriterを初期化します。
+// logger.NewBatchSink の書き込み先として使用します。
+// logs: ログのリポジトリ
+// programID: フィールドにプログラムIDがない場合のプログラムID（例: monitor）
+// serverIP: フィールドにサーバーIPアドレスがない場合のサーバーIPアドレス（空の場合はNULL）
+func NewLogBatchWriter(logs interfaces.LogRepository, programID, serverIP string) *LogBatchWriter {
+	return &LogBatchWriter{logs: logs, programID: programID, serverIP: serverIP}
+}
+
+// WriteBatchは、ロガーのログをまとめて記録します。
+// program_id、client_ip、server_ip、user_id のフィールドはカラムに記録し、それ以外のフィールドはメッセージに key=value 形式で付けます。
+func (w *LogBatchWriter) WriteBatch(ctx context.Context, entries []logger.Entry) error {
+	rows := make([]entities.LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		row := entities.LogEntry{
+			ProgramID: entry.FieldString(logFieldProgramID),
+			LogLevel:  entry.Level.String(),
+			Message:   entry.Text(logFieldProgramID, logFieldClientIP, logFieldServerIP, logFieldUserID),
+			ClientIP:  entry.FieldString(logFieldClientIP),
+			ServerIP:  entry.FieldString(logFieldServerIP),
+			CreatedAt: entry.Time,
+			CreatedBy: entry.FieldString(logFieldUserID),
+		}
+		if row.ProgramID == "" {
+			row.ProgramID = w.programID
+		}
+		if row.ServerIP == "" {
+			row.ServerIP = w.serverIP
+		}
+		rows = append(rows, row)
+	}
+	return w.logs.SaveLogs(ctx, rows)
+}
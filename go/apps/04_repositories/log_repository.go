@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	"strings"
+	"time"
+)
+
+var _ interfaces.LogRepository = (*MySQLLogRepository)(nil)
+
+// cm_t_log のカラムの最大長
+const (
+	// プログラムID
+	maxProgramIDLength = 50
+	// IPアドレス
+	maxIPLength = 45
+	// 作成ユーザー
+	maxCreatedByLength = 50
+)
+
+type MySQLLogRepository struct {
+	// DB接続
+	db *sql.DB
+}
+
+// NewMySQLLogRepositoryは、cm_t_log テーブルにログを記録するMySQLLogRepositoryを初期化します。
+// db: DB接続
+func NewMySQLLogRepository(db *sql.DB) *MySQLLogRepository {
+	return &MySQLLogRepository{db: db}
+}
+
+// SaveLogは、ログを cm_t_log テーブルに記録します。カラムの長さを超える値は切り詰めます。
+func (r *MySQLLogRepository) SaveLog(ctx context.Context, entry entities.LogEntry) error {
+	createdBy := entry.CreatedBy
+	if createdBy == "" {
+		createdBy = "system"
+	}
+	createdBy = truncate(createdBy, maxCreatedByLength)
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO cm_t_log (program_id, log_level, message, client_ip, server_ip, created_by, updated_by) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		truncate(entry.ProgramID, maxProgramIDLength), entry.LogLevel, entry.Message,
+		nullString(truncate(entry.ClientIP, maxIPLength)), nullString(truncate(entry.ServerIP, maxIPLength)),
+		createdBy, createdBy)
+	return err
+}
+
+// SaveLogsは、複数のログを1つのINSERT文で cm_t_log テーブルに記録します。
+// 作成日時にはログの作成日時（ゼロ値の場合は現在時刻）を記録します。
+func (r *MySQLLogRepository) SaveLogs(ctx context.Context, entries []entities.LogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	placeholders := make([]string, 0, len(entries))
+	args := make([]interface{}, 0, len(entries)*8)
+	now := time.Now()
+	for _, entry := range entries {
+		createdBy := entry.CreatedBy
+		if createdBy == "" {
+			createdBy = "system"
+		}
+		createdBy = truncate(createdBy, maxCreatedByLength)
+		createdAt := entry.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			truncate(entry.ProgramID, maxProgramIDLength), entry.LogLevel, entry.Message,
+			nullString(truncate(entry.ClientIP, maxIPLength)), nullString(truncate(entry.ServerIP, maxIPLength)),
+			createdAt, createdBy, createdBy)
+	}
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO cm_t_log (program_id, log_level, message, client_ip, server_ip, created_at, created_by, updated_by) VALUES "+strings.Join(placeholders, ", "),
+		args...)
+	return err
+}
+
+// SearchLogsは、cm_t_log テーブルから条件に一致するログを検索します。
+// AfterID を指定した場合は古い順、それ以外は新しい順に、最大 Limit 件を返します。
+func (r *MySQLLogRepository) SearchLogs(ctx context.Context, query entities.LogQuery) ([]entities.LogEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if !query.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, query.From)
+	}
+	if !query.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, query.To)
+	}
+	if len(query.Levels) > 0 {
+		conditions = append(conditions, "log_level IN (?"+strings.Repeat(", ?", len(query.Levels)-1)+")")
+		for _, level := range query.Levels {
+			args = append(args, level)
+		}
+	}
+	if query.ProgramID != "" {
+		conditions = append(conditions, "program_id = ?")
+		args = append(args, query.ProgramID)
+	}
+	if query.IP != "" {
+		conditions = append(conditions, "(client_ip = ? OR server_ip = ?)")
+		args = append(args, query.IP, query.IP)
+	}
+	if query.Text != "" {
+		conditions = append(conditions, "message LIKE ?")
+		args = append(args, "%"+escapeLike(query.Text)+"%")
+	}
+	order := "DESC"
+	switch {
+	case query.AfterID > 0:
+		conditions = append(conditions, "log_id > ?")
+		args = append(args, query.AfterID)
+		order = "ASC"
+	case query.BeforeID > 0:
+		conditions = append(conditions, "log_id < ?")
+		args = append(args, query.BeforeID)
+	}
+
+	statement := "SELECT log_id, program_id, log_level, message, client_ip, server_ip, created_at, created_by FROM cm_t_log"
+	if len(conditions) > 0 {
+		statement += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	statement += " ORDER BY log_id " + order + " LIMIT ?"
+	args = append(args, query.Limit)
+
+	rows, err := r.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	entries := make([]entities.LogEntry, 0, query.Limit)
+	for rows.Next() {
+		var entry entities.LogEntry
+		var clientIP, serverIP sql.NullString
+		if err := rows.Scan(&entry.LogID, &entry.ProgramID, &entry.LogLevel, &entry.Message, &clientIP, &serverIP, &entry.CreatedAt, &entry.CreatedBy); err != nil {
+			return nil, err
+		}
+		entry.ClientIP = clientIP.String
+		entry.ServerIP = serverIP.String
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// LatestLogIDは、cm_t_log テーブルの最新のログIDを返します。ログがない場合は0を返します。
+func (r *MySQLLogRepository) LatestLogID(ctx context.Context) (int64, error) {
+	var id sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, "SELECT MAX(log_id) FROM cm_t_log").Scan(&id); err != nil {
+		return 0, err
+	}
+	return id.Int64, nil
+}
+
+// escapeLikeは、LIKE の検索文字列の特殊文字（%、_、\）をエスケープします。
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// truncateは、文字列を指定した文字数に切り詰めます。
+// s: 文字列
+// max: 最大文字数
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
+
+// nullStringは、空文字をNULLとして扱うための値を返します。
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	"no-code-app/apps/10_utils/quic"
+	"no-code-app/apps/10_utils/requestid"
+	"no-code-app/apps/10_utils/tracing"
+)
+
+var _ interfaces.LogTailRepository = (*LogTailRepository)(nil)
+
+// 1行の最大サイズ（JSONエンコード後）
+const maxLogLineSize = 1 << 20
+
+type LogTailRepository struct {
+	// QUICクライアント
+	quicClient *quic.Client
+	// エージェントの認証トークン
+	token string
+}
+
+// NewLogTailRepositoryは、新しいLogTailRepositoryを初期化します。
+// client: エージェントに接続するQUICクライアント
+// token: エージェントの認証トークン
+func NewLogTailRepository(client *quic.Client, token string) *LogTailRepository {
+	return &LogTailRepository{quicClient: client, token: token}
+}
+
+// TailLogsは、エージェントにログの追従を要求し、受信した行を通知します。
+func (r *LogTailRepository) TailLogs(ctx context.Context, serviceName string, onLine func(entities.LogLine)) (err error) {
+	// エージェントのスパンの親となるスパンを開始し、トレースコンテキストをリクエストで伝搬する（スパンは切断まで継続する）
+	ctx, span := startAgentSpan(ctx, entities.AgentRequestTail, serviceName)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	request, err := json.Marshal(entities.AgentRequest{
+		Token:        r.token,
+		Type:         entities.AgentRequestTail,
+		ServiceName:  serviceName,
+		RequestID:    requestid.FromContext(ctx),
+		TraceContext: tracing.Inject(ctx),
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := r.quicClient.OpenRequestStream(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to request log tail of %s: %v", serviceName, err)
+	}
+
+	// 改行区切りのJSONを1行ずつ読み取る
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		// エラーレスポンスとログの行を判別
+		var response entities.AgentResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err == nil && response.Error != "" {
+			return fmt.Errorf("agent error: %s", response.Error)
+		}
+		var line entities.LogLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("invalid log line: %v", err)
+		}
+		onLine(line)
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return ctx.Err()
+}
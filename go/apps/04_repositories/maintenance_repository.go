@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	"no-code-app/apps/10_utils/google"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+var _ interfaces.MaintenanceWindowRepository = (*CalendarMaintenanceRepository)(nil)
+
+// 説明欄で対象サービスを指定する行の接頭辞
+var maintenanceServicePrefixes = []string{"services:", "service:", "サービス:", "サービス："}
+
+type CalendarMaintenanceRepository struct {
+	// Google Calendar APIクライアント
+	calendarClient *google.CalendarClient
+	// メンテナンス予定を登録するカレンダーのID
+	calendarID string
+}
+
+// NewCalendarMaintenanceRepositoryは、新しいCalendarMaintenanceRepositoryを初期化します。
+// client: Google Calendar APIクライアント
+// calendarID: メンテナンス予定を登録するカレンダーのID
+func NewCalendarMaintenanceRepository(client *google.CalendarClient, calendarID string) *CalendarMaintenanceRepository {
+	return &CalendarMaintenanceRepository{calendarClient: client, calendarID: calendarID}
+}
+
+// GetMaintenanceWindowsは、指定期間に重なるカレンダーイベントをメンテナンス期間として取得します。
+func (r *CalendarMaintenanceRepository) GetMaintenanceWindows(from time.Time, to time.Time) ([]entities.MaintenanceWindow, error) {
+	// 繰り返しイベントを展開した状態でイベントを取得
+	events, err := r.calendarClient.ListEvents(r.calendarID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make([]entities.MaintenanceWindow, 0, len(events))
+	for _, event := range events {
+		// キャンセルされたイベントは対象外
+		if event.Status == "cancelled" {
+			continue
+		}
+		window, err := toMaintenanceWindow(event)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// toMaintenanceWindowは、カレンダーイベントをメンテナンス期間に変換します。
+// event: カレンダーイベント
+func toMaintenanceWindow(event *calendar.Event) (entities.MaintenanceWindow, error) {
+	start, err := parseEventDateTime(event.Start)
+	if err != nil {
+		return entities.MaintenanceWindow{}, fmt.Errorf("invalid start of event %s: %v", event.Id, err)
+	}
+	end, err := parseEventDateTime(event.End)
+	if err != nil {
+		return entities.MaintenanceWindow{}, fmt.Errorf("invalid end of event %s: %v", event.Id, err)
+	}
+	return entities.MaintenanceWindow{
+		ID:           event.Id,
+		Summary:      event.Summary,
+		Description:  event.Description,
+		ServiceNames: parseServiceNames(event.Description),
+		Start:        start,
+		End:          end,
+	}, nil
+}
+
+// parseEventDateTimeは、イベントの日時（終日イベントを含む）を解析します。
+// dt: イベントの日時
+func parseEventDateTime(dt *calendar.EventDateTime) (time.Time, error) {
+	if dt == nil {
+		return time.Time{}, fmt.Errorf("date time is empty")
+	}
+	// 日時指定のイベント
+	if dt.DateTime != "" {
+		return time.Parse(time.RFC3339, dt.DateTime)
+	}
+	// 終日イベントはタイムゾーンを考慮して日付の0時とする
+	location := time.Local
+	if dt.TimeZone != "" {
+		if loc, err := time.LoadLocation(dt.TimeZone); err == nil {
+			location = loc
+		}
+	}
+	return time.ParseInLocation("2006-01-02", dt.Date, location)
+}
+
+// parseServiceNamesは、説明欄の「services: a, b」形式の行から対象サービス名を取り出します。
+// description: イベントの説明
+func parseServiceNames(description string) []string {
+	var names []string
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range maintenanceServicePrefixes {
+			if !strings.HasPrefix(strings.ToLower(line), prefix) {
+				continue
+			}
+			for _, name := range strings.Split(line[len(prefix):], ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
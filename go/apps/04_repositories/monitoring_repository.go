@@ -1,24 +1,87 @@
 package repositories
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	entities "no-code-app/apps/03_entities"
 	interfaces "no-code-app/apps/05_interfaces"
 	"no-code-app/apps/10_utils/quic"
+	"no-code-app/apps/10_utils/requestid"
+	"no-code-app/apps/10_utils/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ interfaces.MonitoringRepository = (*MonitoringRepository)(nil)
 
+// エージェントへのリクエストのタイムアウト
+const agentRequestTimeout = 10 * time.Second
+
 type MonitoringRepository struct {
 	// QUICクライアント
 	quicClient *quic.Client
+	// 取得するプロセスの上限数
+	topProcesses int
+	// エージェントの認証トークン
+	token string
+}
+
+// GetServiceStatusは、指定されたサービスのステータスをエージェントから取得します。
+func (m *MonitoringRepository) GetServiceStatus(ctx context.Context, serviceName string) (status entities.ServiceStatus, err error) {
+	// エージェントのスパンの親となるスパンを開始し、トレースコンテキストをリクエストで伝搬する
+	ctx, span := startAgentSpan(ctx, entities.AgentRequestStatus, serviceName)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	request, err := json.Marshal(entities.AgentRequest{
+		Token:        m.token,
+		Type:         entities.AgentRequestStatus,
+		ServiceName:  serviceName,
+		TopN:         m.topProcesses,
+		RequestID:    requestid.FromContext(ctx),
+		TraceContext: tracing.Inject(ctx),
+	})
+	if err != nil {
+		return entities.ServiceStatus{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, agentRequestTimeout)
+	defer cancel()
+	body, err := m.quicClient.SendRequest(ctx, request)
+	if err != nil {
+		return entities.ServiceStatus{}, fmt.Errorf("failed to request status of %s: %v", serviceName, err)
+	}
+
+	var response entities.AgentResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return entities.ServiceStatus{}, fmt.Errorf("invalid agent response: %v", err)
+	}
+	if response.Error != "" {
+		return entities.ServiceStatus{}, fmt.Errorf("agent error: %s", response.Error)
+	}
+	if response.Status == nil {
+		return entities.ServiceStatus{}, fmt.Errorf("agent returned no status for %s", serviceName)
+	}
+	return *response.Status, nil
 }
 
-// GetServiceStatusは、指定されたサービスのステータスを取得します。
-func (m *MonitoringRepository) GetServiceStatus(serviceName string) (entities.ServiceStatus, error) {
-	panic("unimplemented")
+// startAgentSpanは、エージェントへのリクエストのスパンを開始します。
+// ctx: コンテキスト
+// requestType: リクエストの種類
+// serviceName: サービス名
+func startAgentSpan(ctx context.Context, requestType string, serviceName string) (context.Context, trace.Span) {
+	return tracing.Tracer("no-code-app/agent").Start(ctx, "agent "+requestType,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("app.service_name", serviceName)),
+	)
 }
 
 // NewMonitoringRepositoryは、新しいMonitoringRepositoryを初期化します。
-func NewMonitoringRepository(client *quic.Client) *MonitoringRepository {
-	return &MonitoringRepository{quicClient: client}
+// client: エージェントに接続するQUICクライアント
+// topProcesses: 取得するプロセスの上限数
+// token: エージェントの認証トークン
+func NewMonitoringRepository(client *quic.Client, topProcesses int, token string) *MonitoringRepository {
+	return &MonitoringRepository{quicClient: client, topProcesses: topProcesses, token: token}
 }
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	"no-code-app/apps/10_utils/probe"
+	"time"
+)
+
+var _ interfaces.ProbeChecker = (*ProbeChecker)(nil)
+
+// タイムアウトが設定されていない場合の上限（検証間隔の方が短い場合は検証間隔）
+const defaultProbeTimeout = 10 * time.Second
+
+type ProbeChecker struct{}
+
+// NewProbeCheckerは、新しいProbeCheckerを初期化します。
+func NewProbeChecker() *ProbeChecker {
+	return &ProbeChecker{}
+}
+
+// Checkは、検証対象の種類に応じたプローブを実行します。
+func (p *ProbeChecker) Check(ctx context.Context, target entities.ProbeTarget) entities.ProbeResult {
+	// タイムアウトを設定（未設定の場合は検証間隔と10秒の短い方）
+	timeout := target.Timeout
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+		if target.Interval > 0 {
+			timeout = min(target.Interval, timeout)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var result probe.Result
+	switch target.Type {
+	case entities.ProbeTypeHTTP:
+		result = probe.HTTP(ctx, target.Address, probe.HTTPOptions{
+			Method:             target.Method,
+			ExpectedStatus:     target.ExpectedStatus,
+			BodyPattern:        target.BodyPattern,
+			InsecureSkipVerify: target.InsecureSkipVerify,
+		})
+	case entities.ProbeTypeTCP:
+		result = probe.TCP(ctx, target.Address)
+	case entities.ProbeTypeGRPC:
+		result = probe.GRPCHealth(ctx, target.Address, target.GRPCService, target.TLS, target.InsecureSkipVerify)
+	case entities.ProbeTypeQUIC:
+		result = probe.QUICHandshake(ctx, target.Address, target.NextProtos, target.InsecureSkipVerify)
+	default:
+		result = probe.Result{Err: fmt.Errorf("unknown probe type %q", target.Type)}
+	}
+
+	probeResult := entities.ProbeResult{
+		Type:       target.Type,
+		Address:    target.Address,
+		Success:    result.Err == nil,
+		Latency:    result.Latency,
+		StatusCode: result.StatusCode,
+	}
+	if !result.CertExpiry.IsZero() {
+		probeResult.CertExpiry = &result.CertExpiry
+	}
+	if result.Err != nil {
+		probeResult.FailureReason = result.Err.Error()
+	}
+	return probeResult
+}
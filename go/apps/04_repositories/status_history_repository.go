@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	"sort"
+	"sync"
+	"time"
+)
+
+var _ interfaces.StatusHistoryRepository = (*InMemoryStatusHistoryRepository)(nil)
+
+type InMemoryStatusHistoryRepository struct {
+	// 排他制御用のミューテックス
+	mu sync.RWMutex
+	// サービス名ごとのステータス履歴
+	history map[string][]entities.ServiceStatus
+	// サービスごとに保持する最大件数
+	maxEntries int
+}
+
+// NewInMemoryStatusHistoryRepositoryは、新しいInMemoryStatusHistoryRepositoryを初期化します。
+// maxEntries: サービスごとに保持する最大件数
+func NewInMemoryStatusHistoryRepository(maxEntries int) *InMemoryStatusHistoryRepository {
+	return &InMemoryStatusHistoryRepository{
+		history:    make(map[string][]entities.ServiceStatus),
+		maxEntries: maxEntries,
+	}
+}
+
+// SaveServiceStatusは、サービスステータスを履歴に保存します。
+func (r *InMemoryStatusHistoryRepository) SaveServiceStatus(status entities.ServiceStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := append(r.history[status.ServiceName], status)
+	// 時系列順を保つ（通常は末尾追加のため並べ替えは発生しない）
+	if n := len(entries); n > 1 && entries[n-1].Timestamp.Before(entries[n-2].Timestamp) {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Timestamp.Before(entries[j].Timestamp)
+		})
+	}
+	// 最大件数を超えた古い履歴を破棄
+	if r.maxEntries > 0 && len(entries) > r.maxEntries {
+		entries = append([]entities.ServiceStatus(nil), entries[len(entries)-r.maxEntries:]...)
+	}
+	r.history[status.ServiceName] = entries
+	return nil
+}
+
+// GetServiceStatusHistoryは、指定期間のサービスステータス履歴を時系列順に取得します。
+func (r *InMemoryStatusHistoryRepository) GetServiceStatusHistory(serviceName string, from time.Time, to time.Time) ([]entities.ServiceStatus, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []entities.ServiceStatus
+	for _, status := range r.history[serviceName] {
+		if status.Timestamp.Before(from) || status.Timestamp.After(to) {
+			continue
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
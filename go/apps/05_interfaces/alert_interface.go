@@ -0,0 +1,8 @@
+package interfaces
+
+import entities "no-code-app/apps/03_entities"
+
+type AlertNotifier interface {
+	// アラートを通知するメソッド
+	Notify(alert entities.Alert) error
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	entities "no-code-app/apps/03_entities"
+)
+
+type LogRepository interface {
+	// ログを記録するメソッド
+	SaveLog(ctx context.Context, entry entities.LogEntry) error
+	// 複数のログをまとめて記録するメソッド
+	SaveLogs(ctx context.Context, entries []entities.LogEntry) error
+	// 条件に一致するログを検索するメソッド
+	SearchLogs(ctx context.Context, query entities.LogQuery) ([]entities.LogEntry, error)
+	// 最新のログIDを取得するメソッド（ログがない場合は0）
+	LatestLogID(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+	entities "no-code-app/apps/03_entities"
+)
+
+type LogTailRepository interface {
+	// サービスのログの追従を開始し、コンテキストがキャンセルされるか接続が切れるまで行を通知するメソッド
+	TailLogs(ctx context.Context, serviceName string, onLine func(entities.LogLine)) error
+}
@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	entities "no-code-app/apps/03_entities"
+	"time"
+)
+
+type MaintenanceWindowRepository interface {
+	// 指定期間に重なるメンテナンス期間を取得するメソッド
+	GetMaintenanceWindows(from time.Time, to time.Time) ([]entities.MaintenanceWindow, error)
+}
@@ -1,8 +1,19 @@
 package interfaces
 
-import entities "no-code-app/apps/03_entities"
+import (
+	"context"
+	entities "no-code-app/apps/03_entities"
+	"time"
+)
 
 type MonitoringRepository interface {
-	// サービスステータスを取得するメソッド
-	GetServiceStatus(serviceName string) (entities.ServiceStatus, error)
+	// サービスステータスを取得するメソッド（コンテキストのリクエストIDをエージェントに引き継ぐ）
+	GetServiceStatus(ctx context.Context, serviceName string) (entities.ServiceStatus, error)
+}
+
+type StatusHistoryRepository interface {
+	// サービスステータスを履歴に保存するメソッド
+	SaveServiceStatus(status entities.ServiceStatus) error
+	// 指定期間のサービスステータス履歴を時系列順に取得するメソッド
+	GetServiceStatusHistory(serviceName string, from time.Time, to time.Time) ([]entities.ServiceStatus, error)
 }
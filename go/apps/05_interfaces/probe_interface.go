@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+	entities "no-code-app/apps/03_entities"
+)
+
+type ProbeChecker interface {
+	// 検証対象に対してプローブを実行するメソッド
+	Check(ctx context.Context, target entities.ProbeTarget) entities.ProbeResult
+}
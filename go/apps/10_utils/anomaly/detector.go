@@ -0,0 +1,157 @@
+package anomaly
+
+import (
+	"math"
+	"time"
+)
+
+// 1週間あたりの時間帯の数
+const hoursPerWeek = 7 * 24
+
+// Pointは、時系列の1点を表す構造体
+type Point struct {
+	// 計測時刻
+	Time time.Time
+	// 計測値
+	Value float64
+}
+
+// Resultは、異常と判定された点を表す構造体
+type Result struct {
+	// 異常と判定された点
+	Point Point
+	// ベースラインの期待値
+	Expected float64
+	// ベースラインの標準偏差
+	StdDev float64
+	// zスコア
+	ZScore float64
+}
+
+// Configは、検知器の設定を表す構造体
+type Config struct {
+	// EWMAの平滑化係数（0〜1、大きいほど直近の値を重視）
+	Alpha float64
+	// 異常と判定するzスコアの閾値
+	Threshold float64
+	// ベースラインを使用するのに必要な最小サンプル数
+	MinSamples int
+	// 標準偏差の下限（値がほぼ一定の時系列で微小な変動を異常としないため）
+	MinStdDev float64
+}
+
+// DefaultConfigは、検知器のデフォルト設定を返す関数
+func DefaultConfig() Config {
+	return Config{Alpha: 0.1, Threshold: 3, MinSamples: 10, MinStdDev: 0.5}
+}
+
+// Baselineは、EWMAによる平均と分散を保持する構造体
+type Baseline struct {
+	// 平均
+	Mean float64
+	// 分散
+	Variance float64
+	// 取り込んだサンプル数
+	Count int
+}
+
+// 値をベースラインに取り込む関数
+// value: 取り込む値
+// alpha: 平滑化係数
+func (b *Baseline) Update(value float64, alpha float64) {
+	// 最初の値はそのまま平均とする
+	if b.Count == 0 {
+		b.Mean = value
+		b.Count = 1
+		return
+	}
+	// 指数加重移動平均と分散を更新
+	diff := value - b.Mean
+	increment := alpha * diff
+	b.Mean += increment
+	b.Variance = (1 - alpha) * (b.Variance + diff*increment)
+	b.Count++
+}
+
+// 標準偏差を取得する関数
+func (b Baseline) StdDev() float64 {
+	return math.Sqrt(b.Variance)
+}
+
+// 値のzスコアを計算する関数
+// value: 評価する値
+func (b Baseline) ZScore(value float64) float64 {
+	stdDev := b.StdDev()
+	if stdDev == 0 {
+		// 分散がない場合は値が一致していれば0、異なれば無限大とする
+		if value == b.Mean {
+			return 0
+		}
+		return math.Copysign(math.Inf(1), value-b.Mean)
+	}
+	return (value - b.Mean) / stdDev
+}
+
+// Detectorは、曜日・時間帯ごとのベースラインで異常を検知する構造体
+type Detector struct {
+	// 検知器の設定
+	config Config
+	// 全期間のベースライン
+	global Baseline
+	// 曜日・時間帯ごとのベースライン
+	hourly [hoursPerWeek]Baseline
+}
+
+// 新しい検知器を作成する関数
+// config: 検知器の設定
+func NewDetector(config Config) *Detector {
+	return &Detector{config: config}
+}
+
+// 時刻から曜日・時間帯のインデックスを取得する関数
+// t: 時刻
+func HourOfWeek(t time.Time) int {
+	return int(t.Weekday())*24 + t.Hour()
+}
+
+// 点を評価し、異常であれば結果を返した後でベースラインに取り込む関数
+// point: 評価する点
+func (d *Detector) Observe(point Point) (Result, bool) {
+	result, anomalous := d.Evaluate(point)
+	// 評価後にベースラインを更新
+	d.global.Update(point.Value, d.config.Alpha)
+	d.hourly[HourOfWeek(point.Time)].Update(point.Value, d.config.Alpha)
+	return result, anomalous
+}
+
+// ベースラインを更新せずに点を評価する関数
+// point: 評価する点
+func (d *Detector) Evaluate(point Point) (Result, bool) {
+	// 同じ曜日・時間帯のサンプルが十分であればそのベースラインを、不足していれば全期間のベースラインを使用
+	baseline := d.hourly[HourOfWeek(point.Time)]
+	if baseline.Count < d.config.MinSamples {
+		baseline = d.global
+	}
+	if baseline.Count < d.config.MinSamples {
+		return Result{}, false
+	}
+	// 標準偏差に下限を設けてzスコアを計算
+	stdDev := math.Max(baseline.StdDev(), d.config.MinStdDev)
+	zScore := Baseline{Mean: baseline.Mean, Variance: stdDev * stdDev}.ZScore(point.Value)
+	result := Result{Point: point, Expected: baseline.Mean, StdDev: stdDev, ZScore: zScore}
+	return result, math.Abs(zScore) >= d.config.Threshold
+}
+
+// 時系列全体を時刻順に評価し、異常と判定された点を返す関数
+// config: 検知器の設定
+// series: 時刻順の時系列
+func Detect(config Config, series []Point) []Result {
+	detector := NewDetector(config)
+	var results []Result
+	for _, point := range series {
+		if result, anomalous := detector.Observe(point); anomalous {
+			results = append(results, result)
+		}
+	}
+	return results
+}
@@ -0,0 +1,178 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// 合成の時系列の開始時刻（月曜日の0時）
+var seriesStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// hourlySeriesは、開始時刻から1時間ごとの値で時系列を作成します。
+// values: 値
+func hourlySeries(values []float64) []Point {
+	series := make([]Point, len(values))
+	for i, value := range values {
+		series[i] = Point{Time: seriesStart.Add(time.Duration(i) * time.Hour), Value: value}
+	}
+	return series
+}
+
+// constantValuesは、同じ値を並べた値を作成し、指定した位置の値を置き換えます。
+// n: 値の数
+// value: 値
+// overrides: 位置と置き換える値
+func constantValues(n int, value float64, overrides map[int]float64) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = value
+		if override, ok := overrides[i]; ok {
+			values[i] = override
+		}
+	}
+	return values
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		// 異常と判定される点の位置
+		want []int
+	}{
+		{
+			name:   "一定の値の急増を検知する",
+			values: constantValues(48, 10, map[int]float64{40: 100}),
+			want:   []int{40},
+		},
+		{
+			name:   "一定の値の急減を検知する",
+			values: constantValues(48, 10, map[int]float64{30: 0}),
+			want:   []int{30},
+		},
+		{
+			name:   "一定の値は異常としない",
+			values: constantValues(48, 10, nil),
+			want:   nil,
+		},
+		{
+			name:   "標準偏差の下限未満の変動は異常としない",
+			values: constantValues(48, 10, map[int]float64{20: 11, 21: 9, 22: 11}),
+			want:   nil,
+		},
+		{
+			name:   "最小サンプル数に達するまでは判定しない",
+			values: constantValues(48, 10, map[int]float64{0: 100, 5: 100}),
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Detect(DefaultConfig(), hourlySeries(tt.values))
+			if len(results) != len(tt.want) {
+				t.Fatalf("Detect() = %d anomalies %+v, want %d", len(results), results, len(tt.want))
+			}
+			for i, result := range results {
+				want := seriesStart.Add(time.Duration(tt.want[i]) * time.Hour)
+				if !result.Point.Time.Equal(want) {
+					t.Errorf("anomaly %d at %v, want %v", i, result.Point.Time, want)
+				}
+				if math.Abs(result.ZScore) < DefaultConfig().Threshold {
+					t.Errorf("anomaly %d z-score = %v, want >= %v", i, result.ZScore, DefaultConfig().Threshold)
+				}
+			}
+		})
+	}
+}
+
+func TestBaselineZScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline Baseline
+		value    float64
+		want     float64
+	}{
+		{name: "分散がなく値が一致する場合は0", baseline: Baseline{Mean: 10, Count: 20}, value: 10, want: 0},
+		{name: "分散がなく値が大きい場合は+Inf", baseline: Baseline{Mean: 10, Count: 20}, value: 11, want: math.Inf(1)},
+		{name: "分散がなく値が小さい場合は-Inf", baseline: Baseline{Mean: 10, Count: 20}, value: 9, want: math.Inf(-1)},
+		{name: "標準偏差で正規化する", baseline: Baseline{Mean: 10, Variance: 4, Count: 20}, value: 16, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.baseline.ZScore(tt.value); got != tt.want {
+				t.Errorf("ZScore(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectorZeroVarianceWithoutMinStdDev(t *testing.T) {
+	// 標準偏差の下限がない場合、一定の値からのわずかな変化も無限大のzスコアで異常とする
+	config := DefaultConfig()
+	config.MinStdDev = 0
+	detector := NewDetector(config)
+	for _, point := range hourlySeries(constantValues(20, 10, nil)) {
+		detector.Observe(point)
+	}
+	result, anomalous := detector.Evaluate(Point{Time: seriesStart.Add(20 * time.Hour), Value: 10.001})
+	if !anomalous || !math.IsInf(result.ZScore, 1) {
+		t.Errorf("Evaluate() = %+v, %v, want +Inf anomaly", result, anomalous)
+	}
+	if _, anomalous := detector.Evaluate(Point{Time: seriesStart.Add(20 * time.Hour), Value: 10}); anomalous {
+		t.Error("Evaluate() of the baseline value is anomalous")
+	}
+}
+
+func TestDetectorHourOfWeekBaseline(t *testing.T) {
+	// 毎週月曜日の3時台のみ高負荷（バッチ処理）となる4週間の時系列
+	config := DefaultConfig()
+	config.MinSamples = 3
+	values := make([]float64, 4*hoursPerWeek)
+	for i := range values {
+		values[i] = 10
+		if i%hoursPerWeek == 3 {
+			values[i] = 100
+		}
+	}
+	detector := NewDetector(config)
+	for _, point := range hourlySeries(values) {
+		detector.Observe(point)
+	}
+	nextWeek := seriesStart.Add(4 * hoursPerWeek * time.Hour)
+
+	tests := []struct {
+		name  string
+		point Point
+		want  bool
+	}{
+		{name: "月曜日3時の高負荷は同じ時間帯のベースラインでは正常", point: Point{Time: nextWeek.Add(3 * time.Hour), Value: 100}, want: false},
+		{name: "月曜日3時の低負荷は同じ時間帯のベースラインでは異常", point: Point{Time: nextWeek.Add(3 * time.Hour), Value: 10}, want: true},
+		{name: "火曜日3時の高負荷は異常", point: Point{Time: nextWeek.Add(27 * time.Hour), Value: 100}, want: true},
+		{name: "火曜日3時の低負荷は正常", point: Point{Time: nextWeek.Add(27 * time.Hour), Value: 10}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, anomalous := detector.Evaluate(tt.point)
+			if anomalous != tt.want {
+				t.Errorf("Evaluate() = %+v, anomalous %v, want %v", result, anomalous, tt.want)
+			}
+		})
+	}
+}
+
+func TestHourOfWeek(t *testing.T) {
+	tests := []struct {
+		time time.Time
+		want int
+	}{
+		{time: time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), want: 0},     // 日曜日0時
+		{time: time.Date(2024, 1, 1, 3, 30, 0, 0, time.UTC), want: 27},   // 月曜日3時
+		{time: time.Date(2024, 1, 6, 23, 59, 0, 0, time.UTC), want: 167}, // 土曜日23時
+	}
+	for _, tt := range tests {
+		if got := HourOfWeek(tt.time); got != tt.want {
+			t.Errorf("HourOfWeek(%v) = %d, want %d", tt.time, got, tt.want)
+		}
+	}
+}
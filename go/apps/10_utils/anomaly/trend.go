@@ -0,0 +1,83 @@
+package anomaly
+
+import (
+	"math"
+	"time"
+)
+
+// Trendは、最小二乗法で求めた線形トレンドを表す構造体
+type Trend struct {
+	// 基準時刻
+	Origin time.Time
+	// 1秒あたりの変化量
+	Slope float64
+	// 基準時刻における値
+	Intercept float64
+	// 決定係数
+	R2 float64
+}
+
+// 時系列に線形トレンドを当てはめる関数
+// series: 時系列（2点以上、かつ時刻が異なる点を含む必要がある）
+func FitTrend(series []Point) (Trend, bool) {
+	if len(series) < 2 {
+		return Trend{}, false
+	}
+	// 桁落ちを避けるため最初の点を基準時刻とする
+	origin := series[0].Time
+	n := float64(len(series))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, point := range series {
+		x := point.Time.Sub(origin).Seconds()
+		sumX += x
+		sumY += point.Value
+		sumXY += x * point.Value
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return Trend{}, false
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+
+	// 決定係数を計算
+	meanY := sumY / n
+	var residual, total float64
+	for _, point := range series {
+		predicted := intercept + slope*point.Time.Sub(origin).Seconds()
+		residual += (point.Value - predicted) * (point.Value - predicted)
+		total += (point.Value - meanY) * (point.Value - meanY)
+	}
+	r2 := 1.0
+	if total > 0 {
+		r2 = 1 - residual/total
+	}
+	return Trend{Origin: origin, Slope: slope, Intercept: intercept, R2: r2}, true
+}
+
+// 指定時刻の予測値を取得する関数
+// at: 予測する時刻
+func (t Trend) ValueAt(at time.Time) float64 {
+	return t.Intercept + t.Slope*at.Sub(t.Origin).Seconds()
+}
+
+// トレンドが閾値に到達する時刻を予測する関数
+// threshold: 閾値
+// after: この時刻以降の到達のみを対象とする
+func (t Trend) TimeToReach(threshold float64, after time.Time) (time.Time, bool) {
+	current := t.ValueAt(after)
+	// 既に到達している場合は基準の時刻を返す
+	if current >= threshold {
+		return after, true
+	}
+	// 増加傾向でなければ到達しない
+	if t.Slope <= 0 {
+		return time.Time{}, false
+	}
+	seconds := (threshold - current) / t.Slope
+	if math.IsInf(seconds, 0) || seconds > math.MaxInt64/float64(time.Second) {
+		return time.Time{}, false
+	}
+	return after.Add(time.Duration(seconds * float64(time.Second))), true
+}
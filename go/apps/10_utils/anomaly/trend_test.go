@@ -0,0 +1,93 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// linearSeriesは、開始時刻から一定間隔で一定量ずつ変化する時系列を作成します。
+// n: 点の数
+// step: 間隔
+// start: 最初の値
+// increment: 1点あたりの変化量
+func linearSeries(n int, step time.Duration, start, increment float64) []Point {
+	series := make([]Point, n)
+	for i := range series {
+		series[i] = Point{Time: seriesStart.Add(time.Duration(i) * step), Value: start + float64(i)*increment}
+	}
+	return series
+}
+
+func TestFitTrend(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name   string
+		series []Point
+		wantOK bool
+		// 1日あたりの変化量
+		wantSlopePerDay float64
+	}{
+		{name: "1日1%ずつ増加するディスク使用率", series: linearSeries(240, time.Hour, 50, 1.0/24), wantOK: true, wantSlopePerDay: 1},
+		{name: "減少する時系列", series: linearSeries(10, day, 80, -2), wantOK: true, wantSlopePerDay: -2},
+		{name: "一定の時系列", series: linearSeries(10, day, 30, 0), wantOK: true, wantSlopePerDay: 0},
+		{name: "1点のみ", series: linearSeries(1, day, 30, 0), wantOK: false},
+		{name: "すべて同じ時刻（分母が0）", series: linearSeries(5, 0, 30, 1), wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trend, ok := FitTrend(tt.series)
+			if ok != tt.wantOK {
+				t.Fatalf("FitTrend() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got := trend.Slope * day.Seconds(); math.Abs(got-tt.wantSlopePerDay) > 1e-9 {
+				t.Errorf("slope per day = %v, want %v", got, tt.wantSlopePerDay)
+			}
+			if math.Abs(trend.R2-1) > 1e-9 {
+				t.Errorf("R2 = %v, want 1", trend.R2)
+			}
+		})
+	}
+}
+
+func TestTrendTimeToReach(t *testing.T) {
+	day := 24 * time.Hour
+	// 10日間で50%から60%まで、1日1%ずつ増加するディスク使用率
+	ramp, ok := FitTrend(linearSeries(241, time.Hour, 50, 1.0/24))
+	if !ok {
+		t.Fatal("FitTrend() failed")
+	}
+	now := seriesStart.Add(10 * day)
+	flat, _ := FitTrend(linearSeries(10, day, 30, 0))
+
+	tests := []struct {
+		name      string
+		trend     Trend
+		threshold float64
+		wantOK    bool
+		// 到達までの期間
+		want time.Duration
+	}{
+		{name: "40日後に100%に到達する", trend: ramp, threshold: 100, wantOK: true, want: 40 * day},
+		{name: "5日後に65%に到達する", trend: ramp, threshold: 65, wantOK: true, want: 5 * day},
+		{name: "既に到達している場合は基準の時刻", trend: ramp, threshold: 55, wantOK: true, want: 0},
+		{name: "増加しない場合は到達しない", trend: flat, threshold: 100, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, ok := tt.trend.TimeToReach(tt.threshold, now)
+			if ok != tt.wantOK {
+				t.Fatalf("TimeToReach() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got := at.Sub(now); (got - tt.want).Abs() > time.Minute {
+				t.Errorf("TimeToReach() in %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
@@ -20,6 +21,345 @@ type Config struct {
 		Port     int    `yaml:"port"`
 		Name     string `yaml:"name"`
 	} `yaml:"database"`
+	// 認証の設定
+	Auth struct {
+		// APIキーのリスト
+		APIKeys []struct {
+			// APIキー
+			Key string `yaml:"key"`
+			// APIキーに紐づくユーザーID
+			UserID string `yaml:"user_id"`
+		} `yaml:"api_keys"`
+	} `yaml:"auth"`
+	// 監視の設定
+	Monitoring struct {
+		// エージェントの設定
+		Agent struct {
+			// エージェントのアドレス
+			Address string `yaml:"address"`
+			// エージェントと共有する認証トークン
+			Token string `yaml:"token"`
+			// エージェントの証明書を検証するCA証明書ファイルのパス（空の場合はシステムの証明書）
+			CAFile string `yaml:"ca_file"`
+			// エージェントの証明書のホスト名（空の場合はアドレスのホスト名）
+			ServerName string `yaml:"server_name"`
+			// エージェントの証明書のSHA-256フィンガープリント（自己署名証明書を固定する場合に指定）
+			CertFingerprint string `yaml:"cert_fingerprint"`
+			// 取得するプロセスの上限数
+			TopProcesses int `yaml:"top_processes"`
+			// ステータスの取得間隔
+			PollInterval time.Duration `yaml:"poll_interval"`
+			// 定期的にステータスを取得するサービス名
+			Services []string `yaml:"services"`
+		} `yaml:"agent"`
+		// メンテナンス期間の設定
+		Maintenance struct {
+			// メンテナンス予定を登録するGoogleカレンダーのID（空の場合は無効）
+			CalendarID string `yaml:"calendar_id"`
+			// Google APIの認証情報ファイルのパス
+			CredentialsFile string `yaml:"credentials_file"`
+			// Google APIのトークンファイルのパス
+			TokenFile string `yaml:"token_file"`
+			// カレンダーとの同期間隔
+			SyncInterval time.Duration `yaml:"sync_interval"`
+			// 同期対象とする過去の期間
+			LookBehind time.Duration `yaml:"look_behind"`
+			// 同期対象とする未来の期間
+			LookAhead time.Duration `yaml:"look_ahead"`
+		} `yaml:"maintenance"`
+		// 異常検知の設定
+		Anomaly struct {
+			// EWMAの平滑化係数
+			Alpha float64 `yaml:"alpha"`
+			// 異常と判定するzスコアの閾値
+			Threshold float64 `yaml:"threshold"`
+			// ベースラインを使用するのに必要な最小サンプル数
+			MinSamples int `yaml:"min_samples"`
+			// 標準偏差の下限
+			MinStdDev float64 `yaml:"min_std_dev"`
+			// ベースラインの学習に使用する過去の期間
+			BaselineWindow time.Duration `yaml:"baseline_window"`
+			// トレンド予測に使用する過去の期間
+			ForecastWindow time.Duration `yaml:"forecast_window"`
+			// この期間内にディスクが閾値に到達すると予測された場合にアラートを発報
+			ForecastHorizon time.Duration `yaml:"forecast_horizon"`
+			// ディスク使用量の閾値
+			DiskThreshold float64 `yaml:"disk_threshold"`
+			// 同じサービス・メトリクス・種類のアラートを再発報しない期間
+			AlertCooldown time.Duration `yaml:"alert_cooldown"`
+		} `yaml:"anomaly"`
+		// 合成プローブの設定
+		Probes struct {
+			// 証明書の有効期限がこの期間を切ったら警告を発報
+			CertExpiryWarning time.Duration `yaml:"cert_expiry_warning"`
+			// 検証対象のリスト
+			Targets []struct {
+				// サービス名
+				Name string `yaml:"name"`
+				// プローブの種類（http, tcp, grpc, quic）
+				Type string `yaml:"type"`
+				// 検証先（HTTPの場合はURL、それ以外はhost:port）
+				Address string `yaml:"address"`
+				// 検証間隔
+				Interval time.Duration `yaml:"interval"`
+				// タイムアウト
+				Timeout time.Duration `yaml:"timeout"`
+				// HTTPメソッド
+				Method string `yaml:"method"`
+				// 期待するHTTPステータスコード
+				ExpectedStatus int `yaml:"expected_status"`
+				// レスポンスボディが一致すべき正規表現
+				BodyPattern string `yaml:"body_pattern"`
+				// gRPCのヘルスチェック対象サービス名
+				GRPCService string `yaml:"grpc_service"`
+				// gRPCでTLSを使用するかどうか
+				TLS bool `yaml:"tls"`
+				// 証明書の検証を省略するかどうか
+				InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+				// QUICのALPNで提示するプロトコル
+				NextProtos []string `yaml:"next_protos"`
+			} `yaml:"targets"`
+		} `yaml:"probes"`
+		// ログ追従の設定
+		LogTail struct {
+			// ログを追従するサービス名
+			Services []string `yaml:"services"`
+			// サービスごとに保持する履歴の行数
+			HistorySize int `yaml:"history_size"`
+			// 購読を許可するユーザーID（空の場合は誰も購読できない）
+			AllowedUsers []string `yaml:"allowed_users"`
+		} `yaml:"log_tail"`
+	} `yaml:"monitoring"`
+	// HTTPサーバーの設定（ゼロ値の項目にはデフォルト値を使用）
+	Server struct {
+		// リクエストヘッダーの読み取りのタイムアウト
+		ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+		// リクエスト全体の読み取りのタイムアウト
+		ReadTimeout time.Duration `yaml:"read_timeout"`
+		// レスポンスの書き込みのタイムアウト
+		WriteTimeout time.Duration `yaml:"write_timeout"`
+		// キープアライブ接続のアイドルタイムアウト
+		IdleTimeout time.Duration `yaml:"idle_timeout"`
+		// シャットダウン時に処理中のリクエストの完了を待つ期限
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+		// TLS証明書のパス（空の場合はHTTPで待ち受ける）
+		CertFile string `yaml:"cert_file"`
+		// TLS秘密鍵のパス
+		KeyFile string `yaml:"key_file"`
+	} `yaml:"server"`
+	// ログの設定
+	Log struct {
+		// ログ出力形式（text、json、logfmt、ecs、csv。空の場合はtext）
+		Format string `yaml:"format"`
+		// ログファイルの設定
+		File struct {
+			// ログファイルを出力するディレクトリ（空の場合はファイルに出力しない。ファイル名は サービス名.log）
+			Dir string `yaml:"dir"`
+			// ログファイルの出力形式（空の場合は log.format）
+			Format string `yaml:"format"`
+			// ローテーションするファイルサイズ（MB。0の場合はサイズでローテーションしない）
+			MaxSizeMB int `yaml:"max_size_mb"`
+			// 日付が変わったときにローテーションするかどうか
+			Daily bool `yaml:"daily"`
+			// バックアップを保持する期間（空の場合は期間で削除しない）
+			MaxAge time.Duration `yaml:"max_age"`
+			// 保持するバックアップの最大数（0の場合は数で削除しない）
+			MaxBackups int `yaml:"max_backups"`
+			// バックアップをgzipで圧縮するかどうか
+			Compress bool `yaml:"compress"`
+		} `yaml:"file"`
+		// cm_t_log テーブルへの記録の設定
+		DB struct {
+			// 記録するかどうか
+			Enabled bool `yaml:"enabled"`
+			// 記録する最小のログレベル（空の場合はwarn）
+			MinLevel string `yaml:"min_level"`
+			// 記録する間隔（空の場合は5秒）
+			FlushInterval time.Duration `yaml:"flush_interval"`
+			// 1回に記録する最大件数（空の場合は100件）
+			BatchSize int `yaml:"batch_size"`
+			// 記録を待つログの最大件数（空の場合は10000件）
+			BufferSize int `yaml:"buffer_size"`
+			// DBに記録できないログを退避するファイルのパス（空の場合は破棄する）
+			SpillPath string `yaml:"spill_path"`
+		} `yaml:"db"`
+		// syslog（RFC 5424）への送信の設定
+		Syslog struct {
+			// プロトコル（udp または tcp。空の場合はudp）
+			Network string `yaml:"network"`
+			// 送信先のアドレス（host:port。空の場合は送信しない）
+			Address string `yaml:"address"`
+			// TLSで接続するかどうか（tcpのみ）
+			TLS bool `yaml:"tls"`
+			// サーバー証明書を検証するCA証明書のファイル（空の場合はシステムのルート証明書）
+			CAFile string `yaml:"ca_file"`
+			// サーバー証明書を検証しないかどうか
+			InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+			// 送信する最小のログレベル（空の場合はinfo）
+			MinLevel string `yaml:"min_level"`
+			// 送信を待つログの最大件数（空の場合は1000件）
+			BufferSize int `yaml:"buffer_size"`
+			// ファシリティ（0〜23。空の場合はlocal0（16））
+			Facility int `yaml:"facility"`
+			// アプリケーション名（空の場合はサービス名）
+			AppName string `yaml:"app_name"`
+		} `yaml:"syslog"`
+		// Graylog（GELF 1.1）への送信の設定
+		GELF struct {
+			// プロトコル（udp または tcp。空の場合はudp）
+			Network string `yaml:"network"`
+			// 送信先のアドレス（host:port。空の場合は送信しない）
+			Address string `yaml:"address"`
+			// TLSで接続するかどうか（tcpのみ）
+			TLS bool `yaml:"tls"`
+			// サーバー証明書を検証するCA証明書のファイル（空の場合はシステムのルート証明書）
+			CAFile string `yaml:"ca_file"`
+			// サーバー証明書を検証しないかどうか
+			InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+			// 送信する最小のログレベル（空の場合はinfo）
+			MinLevel string `yaml:"min_level"`
+			// 送信を待つログの最大件数（空の場合は1000件）
+			BufferSize int `yaml:"buffer_size"`
+		} `yaml:"gelf"`
+		// コンポーネント（ログの component フィールド）ごとの最小のログレベル（例: grpc: warn）
+		Components map[string]string `yaml:"components"`
+		// ログを絞り込む規則（出力する規則がある場合はいずれかに一致したログのみ出力し、除外する規則に一致したログは出力しない）
+		Filters []struct {
+			// メッセージに含まれる文字列
+			Contains string `yaml:"contains"`
+			// メッセージに一致する正規表現
+			Pattern string `yaml:"pattern"`
+			// フィールドの条件（キーと値。値が空の場合はキーがあれば一致）
+			Fields map[string]string `yaml:"fields"`
+			// 一致したログを出力しないかどうか
+			Exclude bool `yaml:"exclude"`
+		} `yaml:"filters"`
+		// 同じメッセージのログを間引くサンプリングの設定（first が0の場合は間引かない）
+		Sampling struct {
+			// 集計する期間（空の場合は1秒）
+			Interval time.Duration `yaml:"interval"`
+			// 期間ごとに出力する最初の件数
+			First int `yaml:"first"`
+			// first 件を超えたログを何件に1件出力するか（0の場合は出力せず、件数のみまとめて出力する）
+			Thereafter int `yaml:"thereafter"`
+			// コンポーネント（ログの component フィールド）ごとのサンプリング（first が0の場合はそのコンポーネントを間引かない）
+			Components map[string]struct {
+				// 集計する期間（空の場合は1秒）
+				Interval time.Duration `yaml:"interval"`
+				// 期間ごとに出力する最初の件数
+				First int `yaml:"first"`
+				// first 件を超えたログを何件に1件出力するか
+				Thereafter int `yaml:"thereafter"`
+			} `yaml:"components"`
+		} `yaml:"sampling"`
+		// ログの機密情報を伏せ字にする設定
+		Redact struct {
+			// 値を伏せ字にするフィールドの名前（既定の password、token、authorization などに追加。キーに含まれる場合に一致）
+			Fields []string `yaml:"fields"`
+			// 伏せ字にする値の規則（既定のメールアドレス、Bearerトークン、電話番号などに追加）
+			Patterns []struct {
+				// 伏せ字にする部分に一致する正規表現
+				Pattern string `yaml:"pattern"`
+				// 一致した部分を置き換える文字列（空の場合は [REDACTED]。${1} などでグループを参照できる）
+				Replacement string `yaml:"replacement"`
+			} `yaml:"patterns"`
+			// 既定の名前と規則を使用しないかどうか
+			DisableDefaults bool `yaml:"disable_defaults"`
+			// 伏せ字にしないかどうか
+			Disabled bool `yaml:"disabled"`
+		} `yaml:"redact"`
+		// 管理API（ログレベルの変更、cm_t_log のログの検索）を許可するユーザーID（空の場合は誰も使用できない）
+		AdminUsers []string `yaml:"admin_users"`
+	} `yaml:"log"`
+	// トレーシングの設定
+	Tracing struct {
+		// エクスポーター（otlp、stdout、none。空の場合はnone）
+		Exporter string `yaml:"exporter"`
+		// OTLPの送信先（例: http://otel-collector:4318）
+		Endpoint string `yaml:"endpoint"`
+		// サンプリングする割合（空の場合は1）
+		SampleRatio float64 `yaml:"sample_ratio"`
+	} `yaml:"tracing"`
+	// レート制限の設定
+	RateLimit struct {
+		// バケットのストレージ（memory または mysql、空の場合はmemory）
+		Store string `yaml:"store"`
+		// 制限のポリシー
+		Policies []struct {
+			// ポリシー名
+			Name string `yaml:"name"`
+			// 制限のキーの種類（ip、user、api_key）
+			Key string `yaml:"key"`
+			// 期間あたりに許可するリクエスト数
+			Rate int `yaml:"rate"`
+			// 期間（空の場合は1分）
+			Period time.Duration `yaml:"period"`
+			// 連続して許可するリクエスト数（空の場合はrate）
+			Burst int `yaml:"burst"`
+			// 適用するルート名またはパスのテンプレート（空の場合は全てのルート）
+			Routes []string `yaml:"routes"`
+		} `yaml:"policies"`
+	} `yaml:"rate_limit"`
+	// 子プロセスを監視するスーパーバイザーの設定
+	Supervisor struct {
+		// シャットダウン時に子プロセスの終了を待つ期限
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+		// 起動するサービスのリスト
+		Services []struct {
+			// サービス名
+			Name string `yaml:"name"`
+			// 実行するバイナリのパス（省略した場合は自身のバイナリ）
+			Command string `yaml:"command"`
+			// コマンドライン引数
+			Args []string `yaml:"args"`
+			// 追加する環境変数（KEY=VALUE形式）
+			Env []string `yaml:"env"`
+			// 作業ディレクトリ
+			Dir string `yaml:"dir"`
+			// 再起動ポリシー（always, on-failure, never）
+			Restart string `yaml:"restart"`
+			// 最初の再起動までの待機時間
+			MinBackoff time.Duration `yaml:"min_backoff"`
+			// 再起動までの最大待機時間
+			MaxBackoff time.Duration `yaml:"max_backoff"`
+		} `yaml:"services"`
+	} `yaml:"supervisor"`
+	// 監視対象ホストで動作するエージェントの設定
+	Agent struct {
+		// 待ち受けアドレス
+		ListenAddress string `yaml:"listen_address"`
+		// 監視サーバーと共有する認証トークン（必須）
+		Token string `yaml:"token"`
+		// TLS証明書ファイルのパス（空の場合は自己署名証明書を使用）
+		CertFile string `yaml:"cert_file"`
+		// TLS秘密鍵ファイルのパス
+		KeyFile string `yaml:"key_file"`
+		// ディスク使用率を計測するパス
+		DiskPath string `yaml:"disk_path"`
+		// 監視するサービス
+		Services []struct {
+			// サービス名
+			Name string `yaml:"name"`
+			// 起動しているべきプロセス名
+			Processes []string `yaml:"processes"`
+			// 追従するログファイルのパス
+			LogFiles []string `yaml:"log_files"`
+		} `yaml:"services"`
+	} `yaml:"agent"`
+}
+
+// APIキーからユーザーIDへのマップを取得する関数
+func (c *Config) APIKeyMap() map[string]string {
+	keys := make(map[string]string)
+	for _, apiKey := range c.Auth.APIKeys {
+		keys[apiKey.Key] = apiKey.UserID
+	}
+	return keys
+}
+
+// MySQLの接続文字列を取得する関数
+func (c *Config) DataSourceName() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true", c.Database.User, c.Database.Password, c.Database.Host, c.Database.Port, c.Database.Name)
 }
 
 // 設定ファイルを読み込む関数
@@ -1,13 +1,23 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
+	"no-code-app/apps/10_utils/tracing"
+	"strings"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// クエリのスパンを作成するトレーサーの名前
+const tracerName = "no-code-app/orm"
+
 type ORM struct {
 	DB *sql.DB
+	// スパンに記録するデータベース名
+	dbName string
 }
 
 func NewORM(dataSourceName string) (*ORM, error) {
@@ -18,38 +28,81 @@ func NewORM(dataSourceName string) (*ORM, error) {
 	if err = db.Ping(); err != nil {
 		return nil, err
 	}
-	return &ORM{DB: db}, nil
+	orm := &ORM{DB: db}
+	if config, err := mysql.ParseDSN(dataSourceName); err == nil {
+		orm.dbName = config.DBName
+	}
+	return orm, nil
 }
 
 func (o *ORM) Create(query string, args ...interface{}) (sql.Result, error) {
-	stmt, err := o.DB.Prepare(query)
-	if err != nil {
-		return nil, err
-	}
-	defer stmt.Close()
-	return stmt.Exec(args...)
+	return o.ExecContext(context.Background(), query, args...)
 }
 
 func (o *ORM) Read(query string, args ...interface{}) (*sql.Rows, error) {
-	return o.DB.Query(query, args...)
+	return o.QueryContext(context.Background(), query, args...)
 }
 
 func (o *ORM) Update(query string, args ...interface{}) (sql.Result, error) {
-	stmt, err := o.DB.Prepare(query)
-	if err != nil {
-		return nil, err
-	}
-	defer stmt.Close()
-	return stmt.Exec(args...)
+	return o.ExecContext(context.Background(), query, args...)
 }
 
 func (o *ORM) Delete(query string, args ...interface{}) (sql.Result, error) {
-	stmt, err := o.DB.Prepare(query)
-	if err != nil {
-		return nil, err
+	return o.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContextは、INSERT、UPDATE、DELETEなどの結果を返さないクエリを実行します。
+// コンテキストのスパンの子としてクエリのスパンを作成します。
+func (o *ORM) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := o.startSpan(ctx, query)
+	result, err := o.DB.ExecContext(ctx, query, args...)
+	tracing.EndSpan(span, err)
+	return result, err
+}
+
+// QueryContextは、行を返すクエリを実行します。
+// コンテキストのスパンの子としてクエリのスパンを作成します（スパンは行の読み取りを含みません）。
+func (o *ORM) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := o.startSpan(ctx, query)
+	rows, err := o.DB.QueryContext(ctx, query, args...)
+	tracing.EndSpan(span, err)
+	return rows, err
+}
+
+// QueryRowContextは、1行を返すクエリを実行します。行がない場合のエラーは Scan で返されます。
+// コンテキストのスパンの子としてクエリのスパンを作成します。
+func (o *ORM) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := o.startSpan(ctx, query)
+	row := o.DB.QueryRowContext(ctx, query, args...)
+	err := row.Err()
+	if err == sql.ErrNoRows {
+		// 行がないことはクエリのエラーとしない
+		err = nil
+	}
+	tracing.EndSpan(span, err)
+	return row
+}
+
+// startSpanは、クエリのスパンを開始します。スパン名はSQLの操作（SELECTなど）とデータベース名です。
+// クエリの引数は個人情報を含む場合があるため記録しません。
+func (o *ORM) startSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := ""
+	if fields := strings.Fields(query); len(fields) > 0 {
+		operation = strings.ToUpper(fields[0])
+	}
+	name := operation
+	if o.dbName != "" {
+		name += " " + o.dbName
 	}
-	defer stmt.Close()
-	return stmt.Exec(args...)
+	return tracing.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMySQL,
+			semconv.DBNamespace(o.dbName),
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+		),
+	)
 }
 
 func (o *ORM) BeginTransaction() (*sql.Tx, error) {
@@ -0,0 +1,175 @@
+package errorhandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/apps/10_utils/requestid"
+)
+
+// エラーコードの定数
+const (
+	// リクエストが不正
+	CodeBadRequest = "bad_request"
+	// 入力値の検証エラー
+	CodeValidation = "validation_error"
+	// 認証されていない
+	CodeUnauthorized = "unauthorized"
+	// 権限がない
+	CodeForbidden = "forbidden"
+	// リソースが見つからない
+	CodeNotFound = "not_found"
+	// メソッドが許可されていない
+	CodeMethodNotAllowed = "method_not_allowed"
+	// リクエストの本文が大きすぎる
+	CodePayloadTooLarge = "payload_too_large"
+	// リクエスト数の制限を超えた
+	CodeRateLimited = "rate_limited"
+	// サーバー内部のエラー
+	CodeInternal = "internal_error"
+	// 依存するサービスが利用できない
+	CodeUnavailable = "service_unavailable"
+)
+
+// ErrorResponseは、全てのHTTPハンドラーで共通のエラーレスポンスの形式です。
+type ErrorResponse struct {
+	// エラーコード
+	Code string `json:"code"`
+	// メッセージ
+	Message string `json:"message"`
+	// 詳細（入力値の検証エラーのフィールドなど）
+	Details interface{} `json:"details,omitempty"`
+	// リクエストID
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// AppErrorは、HTTPステータスとエラーコードを持つエラーです。
+type AppError struct {
+	// HTTPステータスコード
+	Status int
+	// エラーコード
+	Code string
+	// クライアントに返すメッセージ
+	Message string
+	// 詳細
+	Details interface{}
+	// 原因のエラー（クライアントには返さない）
+	Err error
+}
+
+// Errorは、エラーメッセージを返します。
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrapは、原因のエラーを返します。
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// WithDetailsは、詳細を設定したエラーを返します。
+// details: 詳細
+func (e *AppError) WithDetails(details interface{}) *AppError {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// Newは、新しいAppErrorを作成します。
+// status: HTTPステータスコード
+// code: エラーコード
+// message: クライアントに返すメッセージ
+func New(status int, code string, message string) *AppError {
+	return &AppError{Status: status, Code: code, Message: message}
+}
+
+// BadRequestは、リクエストが不正であることを表すエラーを作成します。
+// message: メッセージ
+func BadRequest(message string) *AppError {
+	return New(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// Validationは、入力値の検証エラーを作成します。
+// details: フィールドごとの検証エラー
+func Validation(details interface{}) *AppError {
+	return New(http.StatusBadRequest, CodeValidation, "request validation failed").WithDetails(details)
+}
+
+// Unauthorizedは、認証されていないことを表すエラーを作成します。
+func Unauthorized() *AppError {
+	return New(http.StatusUnauthorized, CodeUnauthorized, "unauthorized")
+}
+
+// Forbiddenは、権限がないことを表すエラーを作成します。
+func Forbidden() *AppError {
+	return New(http.StatusForbidden, CodeForbidden, "forbidden")
+}
+
+// NotFoundは、リソースが見つからないことを表すエラーを作成します。
+// message: メッセージ
+func NotFound(message string) *AppError {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+// Internalは、原因のエラーを隠してサーバー内部のエラーを作成します。
+// err: 原因のエラー（ログにのみ出力する。出力済みの場合はnil）
+func Internal(err error) *AppError {
+	return &AppError{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// CodeForStatusは、HTTPステータスコードに対応するエラーコードを返します。
+// status: HTTPステータスコード
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// WriteErrorは、エラーを共通の形式でレスポンスに書き込みます。
+// AppError以外のエラーはサーバー内部のエラーとし、原因はクライアントに返さずログに出力します。
+// w: レスポンスの書き込み先
+// r: HTTPリクエスト
+// err: エラー
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		appErr = Internal(err)
+	}
+	id := requestid.FromContext(r.Context())
+	if appErr.Status >= 500 && appErr.Err != nil {
+		logger.Ctx(r.Context()).With("method", r.Method, "path", r.URL.Path, "error", appErr.Err).Error("request failed")
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(appErr.Status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Code:      appErr.Code,
+		Message:   appErr.Message,
+		Details:   appErr.Details,
+		RequestID: id,
+	})
+}
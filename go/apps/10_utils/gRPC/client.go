@@ -3,10 +3,13 @@ package grpc
 import (
 	"context"
 	"log"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/apps/10_utils/requestid"
+	"no-code-app/apps/10_utils/tracing"
 	"time"
 
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
-	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
@@ -78,8 +81,15 @@ func NewClientConn(address string, timeout time.Duration, maxRetries int, retryB
 		end := time.Now()
 		// エラーからステータスを取得
 		st, _ := status.FromError(err)
-		// ログ出力
-		log.Printf("method: %s, req: %+v, reply: %+v, duration: %s, error: %v, status: %v", method, req, reply, end.Sub(start), err, st)
+		// リクエストID、トレースIDを付与してログ出力（リクエストとレスポンスの機密情報は伏せ字にする）
+		logger.Ctx(ctx).With(
+			"component", "grpc",
+			"method", method,
+			"req", logger.Redact(req),
+			"reply", logger.Redact(reply),
+			"duration", end.Sub(start),
+			"code", st.Code(),
+		).Info("grpc call")
 		return err
 	}
 
@@ -100,21 +110,47 @@ func NewClientConn(address string, timeout time.Duration, maxRetries int, retryB
 		opts ...grpc.CallOption,
 	) error {
 		// トレーサーを取得
-		tracer := otel.Tracer("grpc-client")
-		// スパンを開始
-		ctx, span := tracer.Start(ctx, method)
-		// スパンを終了
-		defer span.End()
+		tracer := tracing.Tracer("grpc-client")
+		// クライアントのスパンを開始
+		ctx, span := tracer.Start(ctx, method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(rpcAttributes(method)...))
+		// トレースコンテキストをメタデータに付与してサーバーに伝搬
+		ctx = injectMetadata(ctx)
 
 		// 実際のRPC呼び出し
 		err := invoker(ctx, method, req, reply, cc, opts...)
-		if err != nil {
-			// エラーをスパンに記録
-			span.RecordError(err)
-		}
+		// エラーをスパンに記録してスパンを終了
+		endRPCSpan(span, err)
 		return err
 	}
 
+	// リクエストIDインターセプター
+	// コンテキストのリクエストIDをメタデータに付与するためのインターセプター
+	requestIDInterceptor := func(
+		// ctx: コンテキスト
+		ctx context.Context,
+		// method: 呼び出されるgRPCメソッドの名前
+		method string,
+		// req: gRPCリクエスト
+		req, reply interface{},
+		// cc: gRPCクライアント接続
+		cc *grpc.ClientConn,
+		// invoker: 実際のgRPC呼び出しを行う関数
+		invoker grpc.UnaryInvoker,
+		// opts: gRPC呼び出しのオプション
+		opts ...grpc.CallOption,
+	) error {
+		// リクエストIDがある場合はメタデータに追加
+		if id := requestid.FromContext(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, requestid.MetadataKey, id)
+		}
+		// 実際のRPC呼び出し
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	// サーバーの停止中に繰り返すエラーログを間引くサンプラー
+	// 1分ごとに同じログを10件まで出力し、以降は100件に1件出力する（log.sampling.components.grpc の設定がある場合はそちらを使用する）
+	errorSampler := logger.NewSampler(logger.SamplingConfig{Interval: time.Minute, First: 10, Thereafter: 100})
+
 	// エラーハンドリングインターセプター
 	// gRPC呼び出しのエラーハンドリングを行うためのインターセプター
 	errorHandlingInterceptor := func(
@@ -136,8 +172,8 @@ func NewClientConn(address string, timeout time.Duration, maxRetries int, retryB
 		if err != nil {
 			// エラーからステータスを取得
 			st, _ := status.FromError(err)
-			// エラーログを出力
-			log.Printf("RPC failed with status: %v", st)
+			// リクエストID、トレースIDを付与してエラーログを出力
+			logger.Ctx(ctx).WithSampler(errorSampler).With("component", "grpc", "method", method, "code", st.Code(), "error", logger.Redact(st.Message())).Error("RPC failed")
 		}
 		return err
 	}
@@ -146,14 +182,19 @@ func NewClientConn(address string, timeout time.Duration, maxRetries int, retryB
 	conn, err := grpc.DialContext(ctx, address,
 		// セキュリティ設定
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		// リトライインターセプター
-		grpc.WithUnaryInterceptor(grpc_retry.UnaryClientInterceptor(retryOpts...)),
-		// ロギングインターセプター
-		grpc.WithUnaryInterceptor(loggingInterceptor),
-		// トレーシングインターセプター
-		grpc.WithUnaryInterceptor(tracingInterceptor),
-		// エラーハンドリングインターセプター
-		grpc.WithUnaryInterceptor(errorHandlingInterceptor),
+		// インターセプター（WithUnaryInterceptorは最後の1つしか有効にならないため連結する）
+		grpc.WithChainUnaryInterceptor(
+			// リクエストIDインターセプター
+			requestIDInterceptor,
+			// リトライインターセプター
+			grpc_retry.UnaryClientInterceptor(retryOpts...),
+			// ロギングインターセプター
+			loggingInterceptor,
+			// トレーシングインターセプター
+			tracingInterceptor,
+			// エラーハンドリングインターセプター
+			errorHandlingInterceptor,
+		),
 		// ストリームリトライインターセプター
 		grpc.WithStreamInterceptor(grpc_retry.StreamClientInterceptor(retryOpts...)),
 		// KeepAliveパラメータ
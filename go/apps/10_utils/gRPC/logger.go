@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"fmt"
+	logger "no-code-app/apps/10_utils/log"
+	"os"
+
+	"google.golang.org/grpc/grpclog"
+)
+
+// grpcLoggerは、gRPCの内部のログを logger に出力する grpclog.LoggerV2 です。
+// gRPCのINFOは接続の状態遷移など詳細なログのため、DEBUGとして出力します。
+type grpcLogger struct {
+	// 出力先のロガー
+	log *logger.Logger
+	// 出力する詳細ログのレベル（V）
+	verbosity int
+}
+
+var _ grpclog.LoggerV2 = (*grpcLogger)(nil)
+
+// SetLoggerは、gRPCの内部のログを logger に出力するよう設定します。
+// gRPCの使用を開始する前（起動時）に1回呼び出します。
+// l: 出力先のロガー
+// verbosity: 出力する詳細ログのレベル（grpclog の V。通常は0）
+func SetLogger(l *logger.Logger, verbosity int) {
+	grpclog.SetLoggerV2(&grpcLogger{log: l.With("component", "grpc"), verbosity: verbosity})
+}
+
+// Infoは、INFOのログをDEBUGとして出力する
+func (g *grpcLogger) Info(args ...interface{}) {
+	g.log.Debug(fmt.Sprint(args...))
+}
+
+// Infolnは、INFOのログをDEBUGとして出力する
+func (g *grpcLogger) Infoln(args ...interface{}) {
+	g.log.Debug(sprintln(args...))
+}
+
+// Infofは、INFOのログをDEBUGとして出力する
+func (g *grpcLogger) Infof(format string, args ...interface{}) {
+	g.log.Debug(fmt.Sprintf(format, args...))
+}
+
+// Warningは、警告ログを出力する
+func (g *grpcLogger) Warning(args ...interface{}) {
+	g.log.Warn(fmt.Sprint(args...))
+}
+
+// Warninglnは、警告ログを出力する
+func (g *grpcLogger) Warningln(args ...interface{}) {
+	g.log.Warn(sprintln(args...))
+}
+
+// Warningfは、警告ログを出力する
+func (g *grpcLogger) Warningf(format string, args ...interface{}) {
+	g.log.Warn(fmt.Sprintf(format, args...))
+}
+
+// Errorは、エラーログを出力する
+func (g *grpcLogger) Error(args ...interface{}) {
+	g.log.Error(fmt.Sprint(args...))
+}
+
+// Errorlnは、エラーログを出力する
+func (g *grpcLogger) Errorln(args ...interface{}) {
+	g.log.Error(sprintln(args...))
+}
+
+// Errorfは、エラーログを出力する
+func (g *grpcLogger) Errorf(format string, args ...interface{}) {
+	g.log.Error(fmt.Sprintf(format, args...))
+}
+
+// Fatalは、エラーログを出力して終了する（grpclog の規約）
+func (g *grpcLogger) Fatal(args ...interface{}) {
+	g.log.Error(fmt.Sprint(args...))
+	os.Exit(1)
+}
+
+// Fatallnは、エラーログを出力して終了する（grpclog の規約）
+func (g *grpcLogger) Fatalln(args ...interface{}) {
+	g.log.Error(sprintln(args...))
+	os.Exit(1)
+}
+
+// Fatalfは、エラーログを出力して終了する（grpclog の規約）
+func (g *grpcLogger) Fatalf(format string, args ...interface{}) {
+	g.log.Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// Vは、詳細ログのレベルを出力するかを判定します。
+func (g *grpcLogger) V(level int) bool {
+	return level <= g.verbosity
+}
+
+// sprintlnは、fmt.Sprintln と同様に値を空白で区切り、末尾の改行を除いた文字列を返します。
+func sprintln(args ...interface{}) string {
+	s := fmt.Sprintln(args...)
+	return s[:len(s)-1]
+}
@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"context"
+	"no-code-app/apps/10_utils/tracing"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataCarrierは、gRPCのメタデータをトレースコンテキストのキャリアとして使用するための型です。
+type metadataCarrier metadata.MD
+
+// Getは、キーの最初の値を返します。
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Setは、キーの値を設定します。
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keysは、全てのキーを返します。
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// injectMetadataは、コンテキストのトレースコンテキストを送信するメタデータに付与します。
+// ctx: コンテキスト
+func injectMetadata(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// extractMetadataは、受信したメタデータのトレースコンテキストを親としたコンテキストを返します。
+// ctx: コンテキスト
+func extractMetadata(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+}
+
+// rpcAttributesは、/package.Service/Method 形式のメソッド名からスパンの属性を作成します。
+// method: gRPCのメソッド名
+func rpcAttributes(method string) []attribute.KeyValue {
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return []attribute.KeyValue{semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(name)}
+}
+
+// endRPCSpanは、gRPCのステータスコードを記録してスパンを終了します。
+// span: スパン
+// err: RPCのエラー
+func endRPCSpan(span trace.Span, err error) {
+	st, _ := status.FromError(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(st.Code())))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, st.Message())
+	}
+	span.End()
+}
+
+// TracingUnaryServerInterceptor は、受信したメタデータのトレースコンテキストを親としてサーバーのスパンを作成するインターセプターを返します
+func TracingUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// サーバーのスパンを開始
+		ctx, span := tracing.Tracer("grpc-server").Start(extractMetadata(ctx), info.FullMethod, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(rpcAttributes(info.FullMethod)...))
+		// 実際のハンドラー呼び出し
+		resp, err := handler(ctx, req)
+		// エラーをスパンに記録してスパンを終了
+		endRPCSpan(span, err)
+		return resp, err
+	}
+}
+
+// TracingStreamServerInterceptor は、ストリームのRPCごとにサーバーのスパンを作成するインターセプターを返します
+func TracingStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		// サーバーのスパンを開始
+		ctx, span := tracing.Tracer("grpc-server").Start(extractMetadata(ss.Context()), info.FullMethod, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(rpcAttributes(info.FullMethod)...))
+		// 実際のハンドラー呼び出し（スパンを含むコンテキストをハンドラーに渡す）
+		err := handler(srv, &tracedServerStream{ServerStream: ss, ctx: ctx})
+		// エラーをスパンに記録してスパンを終了
+		endRPCSpan(span, err)
+		return err
+	}
+}
+
+// tracedServerStreamは、スパンを含むコンテキストを返すServerStreamです。
+type tracedServerStream struct {
+	grpc.ServerStream
+	// スパンを含むコンテキスト
+	ctx context.Context
+}
+
+// Contextは、スパンを含むコンテキストを返します。
+func (s *tracedServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -77,17 +77,23 @@ func (client *CalendarClient) DeleteEvent(calendarID string, eventID string) err
 }
 
 // ListEvents は指定されたカレンダーのイベントをリストします
+// 1回のレスポンスに収まらない場合は NextPageToken をたどって全てのページを取得します
 // calendarID: カレンダーのID
 // timeMin: 開始時間の最小値
 // timeMax: 終了時間の最大値
 func (client *CalendarClient) ListEvents(calendarID string, timeMin time.Time, timeMax time.Time) ([]*calendar.Event, error) {
-	events, err := client.Service.Events.List(calendarID).ShowDeleted(false).
-		SingleEvents(true).TimeMin(timeMin.Format(time.RFC3339)).TimeMax(timeMax.Format(time.RFC3339)).OrderBy("startTime").Do()
+	var items []*calendar.Event
+	err := client.Service.Events.List(calendarID).ShowDeleted(false).
+		SingleEvents(true).TimeMin(timeMin.Format(time.RFC3339)).TimeMax(timeMax.Format(time.RFC3339)).OrderBy("startTime").
+		MaxResults(2500).Pages(context.Background(), func(events *calendar.Events) error {
+		items = append(items, events.Items...)
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve events: %v", err)
 	}
 
-	return events.Items, nil
+	return items, nil
 }
 
 // WatchEvents はカレンダーのイベントの変更を監視します
@@ -2,12 +2,17 @@ package httpclient
 
 import (
 	"net/http"
+	"no-code-app/apps/10_utils/requestid"
+	"no-code-app/apps/10_utils/tracing"
 	"time"
 )
 
 // HTTPクライアントの初期化
+// リクエストのコンテキストにリクエストIDがある場合は X-Request-ID ヘッダーを付与し、
+// クライアントのスパンを作成してトレースコンテキストを traceparent ヘッダーで伝搬する
 func NewHTTPClient(timeout time.Duration) *http.Client {
 	return &http.Client{
-		Timeout: timeout,
+		Timeout:   timeout,
+		Transport: &requestid.Transport{Base: &tracing.Transport{}},
 	}
 }
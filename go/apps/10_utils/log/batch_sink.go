@@ -0,0 +1,274 @@
+package logger
+
+import (
+	"bufio"         // バッファ付きI/O用パッケージ
+	"context"       // コンテキスト用パッケージ
+	"encoding/json" // JSONエンコーディング/デコーディング用パッケージ
+	"errors"        // エラー操作用パッケージ
+	"fmt"           // フォーマットI/O用パッケージ
+	"maps"          // マップ操作用パッケージ
+	"os"            // OS機能用パッケージ
+	"slices"        // スライス操作用パッケージ
+	"sync"          // 排他制御用パッケージ
+	"sync/atomic"   // アトミック操作用パッケージ
+	"time"          // 時間操作用パッケージ
+)
+
+// BatchWriterは、ログをまとめて書き込む出力先です（データベースのテーブルなど）。
+type BatchWriter interface {
+	// ログをまとめて書き込む（エラーの場合は全件が書き込まれていないものとして扱う）
+	WriteBatch(ctx context.Context, entries []Entry) error
+}
+
+// BatchSinkConfigは、BatchSink の設定です。ゼロ値の項目には既定値を使用します。
+type BatchSinkConfig struct {
+	// 書き込む最小のログレベル（ゼロ値はDEBUG）
+	MinLevel LogLevel
+	// 書き込む間隔（既定値は5秒）
+	FlushInterval time.Duration
+	// 1回に書き込む最大件数（既定値は100件。達した場合は間隔を待たずに書き込む）
+	BatchSize int
+	// 書き込みを待つログの最大件数（既定値は10000件。超えた分は破棄し、件数を次の書き込みで記録する）
+	BufferSize int
+	// 1回の書き込みのタイムアウト（既定値は10秒）
+	WriteTimeout time.Duration
+	// 書き込めなかったログを退避するファイルのパス（空の場合は破棄する）
+	// 退避したログは、次に書き込みが成功したときに書き込み直す
+	SpillPath string
+}
+
+// BatchSinkは、ログをバックグラウンドでまとめて BatchWriter に書き込むシンクです。
+// ログの呼び出し元はブロックされず、書き込みに失敗したログはファイルに退避します。
+type BatchSink struct {
+	// 書き込み先
+	writer BatchWriter
+	// 設定
+	config BatchSinkConfig
+	// 書き込みを待つログ
+	entries chan Entry
+	// 書き込みを待つログが上限を超えて破棄した件数
+	dropped atomic.Int64
+	// 停止を通知するチャネル
+	done chan struct{}
+	// バックグラウンドの処理の終了を待つ
+	wg sync.WaitGroup
+	// Close を1回だけ実行する
+	closeOnce sync.Once
+}
+
+// spillRecordは、退避ファイルに1行ずつJSONで記録するログです。
+type spillRecord struct {
+	// 出力時刻
+	Time time.Time `json:"time"`
+	// ログレベル
+	Level string `json:"level"`
+	// ログメッセージ
+	Message string `json:"message"`
+	// フィールド（値は文字列にする）
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// NewBatchSinkは、BatchSink を作成し、バックグラウンドの書き込みを開始します。
+// 停止時は Close で書き込みを待つログを書き込みます。
+// writer: 書き込み先
+// cfg: 設定
+func NewBatchSink(writer BatchWriter, cfg BatchSinkConfig) *BatchSink {
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	s := &BatchSink{
+		writer:  writer,
+		config:  cfg,
+		entries: make(chan Entry, cfg.BufferSize),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+// WriteEntryは、ログを書き込みの待ちに追加します。待ちが上限に達している場合は破棄します。
+// entry: ログ
+func (s *BatchSink) WriteEntry(entry Entry) {
+	if entry.Level < s.config.MinLevel {
+		return
+	}
+	select {
+	case s.entries <- entry:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Closeは、バックグラウンドの書き込みを停止し、書き込みを待つログを書き込みます。
+// Close の後に追加されたログは書き込まれません。
+func (s *BatchSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+	})
+	return nil
+}
+
+// runは、間隔ごとまたは件数に達したときにログを書き込みます。
+func (s *BatchSink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Entry, 0, s.config.BatchSize)
+	flush := func() {
+		if n := s.dropped.Swap(0); n > 0 {
+			batch = append(batch, Entry{Time: time.Now(), Level: WARN, Message: fmt.Sprintf("dropped %d log entries because the log buffer was full", n)})
+		}
+		if len(batch) > 0 {
+			s.flush(batch)
+			batch = make([]Entry, 0, s.config.BatchSize)
+		}
+	}
+	for {
+		select {
+		case entry := <-s.entries:
+			batch = append(batch, entry)
+			if len(batch) >= s.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-s.done:
+			// 停止までに追加されたログを書き込む
+			for {
+				select {
+				case entry := <-s.entries:
+					batch = append(batch, entry)
+					if len(batch) >= s.config.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// flushは、ログを書き込みます。失敗した場合は退避ファイルに追記し、成功した場合は退避したログを書き込み直します。
+// ロガー自身に出力すると書き込みが再帰するため、エラーは標準エラー出力に出力します。
+// batch: ログ
+func (s *BatchSink) flush(batch []Entry) {
+	if err := s.write(batch); err != nil {
+		if spillErr := s.spill(batch); spillErr != nil {
+			fmt.Fprintf(os.Stderr, "logger: failed to write %d log entries: %v (spill: %v)\n", len(batch), err, spillErr)
+		}
+		return
+	}
+	if err := s.replaySpill(); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: failed to replay spilled log entries: %v\n", err)
+	}
+}
+
+// writeは、タイムアウトを指定してログを書き込みます。
+// batch: ログ
+func (s *BatchSink) write(batch []Entry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.WriteTimeout)
+	defer cancel()
+	return s.writer.WriteBatch(ctx, batch)
+}
+
+// spillは、ログを退避ファイルに追記します。
+// batch: ログ
+func (s *BatchSink) spill(batch []Entry) error {
+	if s.config.SpillPath == "" {
+		return errors.New("no spill file configured")
+	}
+	file, err := os.OpenFile(s.config.SpillPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, entry := range batch {
+		record := spillRecord{Time: entry.Time, Level: entry.Level.String(), Message: entry.Message}
+		if len(entry.Fields) > 0 {
+			record.Fields = make(map[string]string, len(entry.Fields))
+			for _, field := range entry.Fields {
+				record.Fields[field.Key] = fmt.Sprint(field.Value)
+			}
+		}
+		if err := encoder.Encode(record); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// replaySpillは、退避ファイルのログを書き込み、全件を書き込めた場合は退避ファイルを削除します。
+// 途中で失敗した場合は、退避ファイルを残して次の機会に書き込み直します（書き込み済みの分が重複する場合があります）。
+func (s *BatchSink) replaySpill() error {
+	if s.config.SpillPath == "" {
+		return nil
+	}
+	file, err := os.Open(s.config.SpillPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	batch := make([]Entry, 0, s.config.BatchSize)
+	scanner := bufio.NewScanner(file)
+	// メッセージにはスタックトレースなどの長い行が含まれる
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var record spillRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// 壊れた行（書き込み中の停止など）は読み飛ばす
+			continue
+		}
+		batch = append(batch, record.entry())
+		if len(batch) >= s.config.BatchSize {
+			if err := s.write(batch); err != nil {
+				file.Close()
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return err
+	}
+	file.Close()
+	if len(batch) > 0 {
+		if err := s.write(batch); err != nil {
+			return err
+		}
+	}
+	return os.Remove(s.config.SpillPath)
+}
+
+// entryは、退避したログを Entry に戻します。
+func (r spillRecord) entry() Entry {
+	level, _ := ParseLogLevel(r.Level)
+	entry := Entry{Time: r.Time, Level: level, Message: r.Message}
+	for _, key := range slices.Sorted(maps.Keys(r.Fields)) {
+		entry.Fields = append(entry.Fields, Field{Key: key, Value: r.Fields[key]})
+	}
+	return entry
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context" // コンテキスト用パッケージ
+
+	"no-code-app/apps/10_utils/requestid" // リクエストID用パッケージ
+
+	"go.opentelemetry.io/otel/trace" // トレースコンテキスト用パッケージ
+)
+
+// コンテキストから付与するフィールドのキー
+const (
+	// リクエストID
+	FieldRequestID = "request_id"
+	// 認証済みユーザーID
+	FieldUserID = "user_id"
+	// トレースID
+	FieldTraceID = "trace_id"
+	// スパンID
+	FieldSpanID = "span_id"
+)
+
+// フィールドをコンテキストに格納するキーの型
+type contextKey struct{}
+
+// NewContextは、Ctx で付与するフィールドを格納したコンテキストを返します。
+// 親のコンテキストに格納されたフィールドに追加されます。
+// ctx: 親のコンテキスト
+// keyValues: キーと値を交互に並べたもの（例: FieldUserID, userID）
+func NewContext(ctx context.Context, keyValues ...interface{}) context.Context {
+	// 親のコンテキストのフィールドは変更しない
+	parent := contextFields(ctx)
+	fields := appendFields(append(make([]Field, 0, len(parent)+(len(keyValues)+1)/2), parent...), keyValues)
+	return context.WithValue(ctx, contextKey{}, fields)
+}
+
+// Ctxは、コンテキストのリクエストID、ユーザーID、トレースID、スパンID、NewContext で格納したフィールドを付与した
+// 既定のロガーの子のロガーを返します。
+// ctx: コンテキスト
+func Ctx(ctx context.Context) *Logger {
+	return Default().Ctx(ctx)
+}
+
+// Ctxは、コンテキストのリクエストID、ユーザーID、トレースID、スパンID、NewContext で格納したフィールドを付与した子のロガーを返します。
+// 値がないフィールドは付与しません。
+// ctx: コンテキスト
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	var keyValues []interface{}
+	if id := requestid.FromContext(ctx); id != "" {
+		keyValues = append(keyValues, FieldRequestID, id)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		keyValues = append(keyValues, FieldTraceID, spanContext.TraceID().String(), FieldSpanID, spanContext.SpanID().String())
+	}
+	for _, field := range contextFields(ctx) {
+		keyValues = append(keyValues, field.Key, field.Value)
+	}
+	return l.With(keyValues...)
+}
+
+// contextFieldsは、NewContext でコンテキストに格納したフィールドを返します。
+// ctx: コンテキスト
+func contextFields(ctx context.Context) []Field {
+	fields, _ := ctx.Value(contextKey{}).([]Field)
+	return fields
+}
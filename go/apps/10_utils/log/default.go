@@ -0,0 +1,326 @@
+package logger
+
+import (
+	"encoding/json" // JSONエンコーディング/デコーディング用パッケージ
+	"fmt"           // フォーマットI/O用パッケージ
+	"io"            // I/Oインターフェース用パッケージ
+	"maps"          // マップ操作用パッケージ
+	"os"            // OS機能用パッケージ
+	"sync"          // 排他制御用パッケージ
+	"sync/atomic"   // アトミック操作用パッケージ
+	"time"          // 時間操作用パッケージ
+)
+
+// パッケージの関数が使用する既定のロガーの変数
+var (
+	// 既定のロガー（設定を変更する関数は、このロガーの設定を置き換える）
+	defaultLogger atomic.Pointer[Logger]
+	// 既定のロガーの置き換えとログファイルを排他制御するミューテックス
+	defaultMu sync.Mutex
+	// SetLogFile で開いたログファイル
+	logFile *os.File
+)
+
+// 初期化関数
+func init() {
+	// INFO以上をテキスト形式で標準出力に出力するロガーを既定とする
+	defaultLogger.Store(New(DefaultConfig()))
+}
+
+// Defaultは、パッケージの関数が使用する既定のロガーを返します。
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+// SetDefaultは、パッケージの関数が使用する既定のロガーを置き換えます。
+// 以降の SetLogLevel などは、このロガーの設定を変更します。
+// l: 既定とするロガー
+func SetDefault(l *Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger.Store(l)
+}
+
+// updateDefaultは、既定のロガーの設定を、変更した設定の複製に置き換えます。
+// With で作成した子のロガーにも反映されます。
+// update: 設定の複製を変更する関数
+func updateDefault(update func(cfg *Config)) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	updateDefaultLocked(update)
+}
+
+// updateDefaultLockedは、defaultMu を取得した状態で既定のロガーの設定を置き換えます。
+// 出力中のログは置き換え前の設定を使用します。
+// update: 設定の複製を変更する関数
+func updateDefaultLocked(update func(cfg *Config)) {
+	core := defaultLogger.Load().core
+	cfg := core.config.Load().clone()
+	update(&cfg)
+	core.config.Store(normalizeConfig(cfg))
+}
+
+// Withは、既定のロガーにフィールドを追加した子のロガーを返します。
+// keyValues: キーと値を交互に並べたもの
+func With(keyValues ...interface{}) *Logger {
+	return Default().With(keyValues...)
+}
+
+// 同じメッセージのログを間引くサンプラーを設定（nilの場合は間引かない）
+// sampler: すべてのログに適用するサンプラー
+func SetLogSampler(sampler *Sampler) {
+	updateDefault(func(cfg *Config) { cfg.Sampler = sampler })
+}
+
+// コンポーネントごとのサンプラーを置き換える（nilのサンプラーはそのコンポーネントを間引かない）
+// samplers: コンポーネントとサンプラーのマップ
+func SetComponentSamplers(samplers map[string]*Sampler) {
+	updateDefault(func(cfg *Config) { cfg.ComponentSamplers = maps.Clone(samplers) })
+}
+
+// 機密情報を伏せ字にする Redactor を設定（nilの場合は伏せ字にしない）
+// redactor: ログのメッセージとフィールドを伏せ字にする Redactor
+func SetLogRedactor(redactor *Redactor) {
+	updateDefault(func(cfg *Config) { cfg.Redactor = redactor })
+}
+
+// Redactは、値を "%+v" でフォーマットし、既定のロガーの Redactor で機密情報を伏せ字にした文字列を返します。
+// リクエストやレスポンスの構造体をそのままログに出力する場合に使用します。
+// value: 値
+func Redact(value interface{}) string {
+	return Default().core.config.Load().Redactor.String(fmt.Sprintf("%+v", value))
+}
+
+// ログレベルを設定
+func SetLogLevel(level LogLevel) {
+	updateDefault(func(cfg *Config) { cfg.Level = level })
+}
+
+// ログ出力形式を設定
+func SetLogOutputFormat(format LogOutputFormat) {
+	updateDefault(func(cfg *Config) { cfg.OutputFormat = format })
+}
+
+// エンコーダーを設定（nilの場合は SetLogOutputFormat の出力形式のエンコーダー）
+// encoder: 出力先に書き込むエンコーダー
+func SetLogEncoder(encoder Encoder) {
+	updateDefault(func(cfg *Config) { cfg.Encoder = encoder })
+}
+
+// ログ出力先を設定
+// level: ログレベル
+// w: ログ出力先のio.Writer
+func SetLogOutput(level LogLevel, w io.Writer) {
+	updateDefault(func(cfg *Config) {
+		// 既存の出力先に追加
+		cfg.Outputs[level] = append(cfg.Outputs[level], w)
+	})
+}
+
+// シンクを追加
+// sink: ログの項目単位で出力する出力先
+func AddSink(sink Sink) {
+	updateDefault(func(cfg *Config) { cfg.Sinks = append(cfg.Sinks, sink) })
+}
+
+// ログファイルを設定
+// 以前に設定したログファイルは出力先から外して閉じる
+// level: ログレベル
+// filePath: ログファイルのパス
+func SetLogFile(level LogLevel, filePath string) error {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	return setLogFileLocked(level, filePath)
+}
+
+// defaultMu を取得した状態でログファイルを設定
+// level: ログレベル
+// filePath: ログファイルのパス
+func setLogFileLocked(level LogLevel, filePath string) error {
+	// ログファイルを開く
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	// エラーが発生した場合、エラーを返す
+	if err != nil {
+		return err
+	}
+	previous := logFile
+	logFile = file
+	updateDefaultLocked(func(cfg *Config) {
+		// 以前のログファイルを出力先から外す
+		if previous != nil {
+			for l, writers := range cfg.Outputs {
+				kept := writers[:0]
+				for _, w := range writers {
+					if w != io.Writer(previous) {
+						kept = append(kept, w)
+					}
+				}
+				cfg.Outputs[l] = kept
+			}
+		}
+		// ログ出力先にファイルを追加
+		cfg.Outputs[level] = append(cfg.Outputs[level], file)
+	})
+	// 以前のログファイルを閉じる（置き換え前の設定で出力中のログの書き込みはエラーとなり無視される）
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// ログファイルをローテーション
+// ローテーションは呼び出し元が行う必要があり、古いファイルは圧縮も削除もされないため、
+// 自動でローテーションする場合は SetRotatingLogFile を使用する
+// level: ログレベル
+// filePath: ログファイルのパス
+//
+// Deprecated: SetRotatingLogFile を使用してください。
+func RotateLogFile(level LogLevel, filePath string) error {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	// バックアップファイルのパスを生成
+	backupPath := fmt.Sprintf("%s.%s", filePath, time.Now().Format("20060102T150405"))
+	// ログファイルをバックアップ
+	err := os.Rename(filePath, backupPath)
+	// エラーが発生した場合、エラーを返す
+	if err != nil {
+		return err
+	}
+	// 新しいログファイルを設定
+	return setLogFileLocked(level, filePath)
+}
+
+// SetRotatingLogFileは、サイズと日付でローテーションするログファイルを作成し、ログレベルの出力先に追加します。
+// 1つのファイルを複数のログレベルで共有します。停止時は戻り値の RotatingWriter を Close で閉じます。
+// cfg: ローテーションの設定
+// levels: 出力するログレベル（省略した場合はすべてのレベル）
+func SetRotatingLogFile(cfg RotateConfig, levels ...LogLevel) (*RotatingWriter, error) {
+	writer, err := NewRotatingWriter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(levels) == 0 {
+		levels = logLevels
+	}
+	updateDefault(func(c *Config) {
+		for _, level := range levels {
+			c.Outputs[level] = append(c.Outputs[level], writer)
+		}
+	})
+	return writer, nil
+}
+
+// ログプレフィックスを設定
+// level: ログレベル
+// prefix: ログプレフィックス
+func SetLogPrefix(level LogLevel, prefix string) {
+	updateDefault(func(cfg *Config) {
+		if cfg.Prefixes == nil {
+			cfg.Prefixes = make(map[LogLevel]string)
+		}
+		cfg.Prefixes[level] = prefix
+	})
+}
+
+// ログフォーマットを設定
+// format: ログフォーマット
+func SetLogFormat(format string) {
+	updateDefault(func(cfg *Config) { cfg.Format = format })
+}
+
+// ログカラーを設定
+// level: ログレベル
+// color: ログカラー
+func SetLogColor(level LogLevel, color string) {
+	updateDefault(func(cfg *Config) { cfg.Colors[level] = color })
+}
+
+// ログフィルターキーワードを設定
+// keyword: ログフィルターキーワード
+func SetLogFilterKeyword(keyword string) {
+	updateDefault(func(cfg *Config) { cfg.FilterKeyword = keyword })
+}
+
+// ログフィルターを設定
+// filter: ログを絞り込むフィルター（nilの場合は絞り込まない）
+func SetLogFilter(filter *Filter) {
+	updateDefault(func(cfg *Config) { cfg.Filter = filter })
+}
+
+// ログレベルを取得
+func GetLogLevel() LogLevel {
+	return Default().core.config.Load().Level
+}
+
+// コンポーネントごとのログレベルの複製を取得
+func ComponentLevels() map[string]LogLevel {
+	return maps.Clone(Default().core.config.Load().ComponentLevels)
+}
+
+// コンポーネントのログレベルを設定（実行中の変更は With で作成済みのロガーにも反映される）
+// component: コンポーネント（component フィールドの値）
+// level: ログレベル
+func SetComponentLevel(component string, level LogLevel) {
+	updateDefault(func(cfg *Config) {
+		if cfg.ComponentLevels == nil {
+			cfg.ComponentLevels = make(map[string]LogLevel)
+		}
+		cfg.ComponentLevels[component] = level
+	})
+}
+
+// コンポーネントのログレベルを削除（以降は Level を適用する）
+// component: コンポーネント（component フィールドの値）
+func RemoveComponentLevel(component string) {
+	updateDefault(func(cfg *Config) { delete(cfg.ComponentLevels, component) })
+}
+
+// コンポーネントごとのログレベルを置き換え
+// levels: コンポーネントごとのログレベル
+func SetComponentLevels(levels map[string]LogLevel) {
+	updateDefault(func(cfg *Config) { cfg.ComponentLevels = maps.Clone(levels) })
+}
+
+// デバッグログを出力
+// message: ログメッセージ
+func Debug(message string) {
+	Default().Debug(message)
+}
+
+// インフォログを出力
+// message: ログメッセージ
+func Info(message string) {
+	Default().Info(message)
+}
+
+// 警告ログを出力
+// message: ログメッセージ
+func Warn(message string) {
+	Default().Warn(message)
+}
+
+// エラーログを出力
+// message: ログメッセージ
+func Error(message string) {
+	Default().Error(message)
+}
+
+// ログメッセージをJSON形式で出力
+// level: ログレベル
+// message: ログメッセージ
+func LogMessageAsJSON(level LogLevel, message string) {
+	l := Default()
+	// 現在のログレベル以上の場合
+	if l.Enabled(level) {
+		// ログエントリをマップとして作成
+		logEntry := map[string]string{
+			"timestamp": time.Now().Format(time.RFC3339),
+			"level":     getLevelString(level),
+			"message":   message,
+		}
+		// ログエントリをJSON形式に変換
+		jsonMessage, _ := json.Marshal(logEntry)
+		// JSON形式のメッセージを出力
+		l.log(level, string(jsonMessage))
+	}
+}
@@ -0,0 +1,271 @@
+package logger
+
+import (
+	"bytes"         // バイト列操作用パッケージ
+	"encoding/csv"  // CSVエンコーディング用パッケージ
+	"encoding/json" // JSONエンコーディング/デコーディング用パッケージ
+	"fmt"           // フォーマットI/O用パッケージ
+	"io"            // I/Oインターフェース用パッケージ
+	"os"            // OS機能用パッケージ
+	"strconv"       // 文字列変換用パッケージ
+	"strings"       // 文字列操作用パッケージ
+	"sync"          // 排他制御用パッケージ
+	"time"          // 時間操作用パッケージ
+)
+
+// 出力するECS（Elastic Common Schema）のバージョン
+const ecsVersion = "8.11.0"
+
+// ECSのフィールドに対応付けるフィールドのキー（それ以外のフィールドは labels に出力する）
+var ecsFieldNames = map[string]string{
+	FieldRequestID: "http.request.id",
+	FieldUserID:    "user.id",
+	FieldTraceID:   "trace.id",
+	FieldSpanID:    "span.id",
+	FieldComponent: "log.logger",
+	"client_ip":    "client.ip",
+	"server_ip":    "server.ip",
+	"error":        "error.message",
+}
+
+// Encoderは、ログを1行（末尾の改行を含む）のバイト列に変換するエンコーダーです。
+// 複数のゴルーチンから同時に呼び出されるため、Encode で状態を変更してはいけません。
+type Encoder interface {
+	// ログを1行に変換する
+	Encode(entry Entry) []byte
+}
+
+// NewEncoderは、ログ出力形式のエンコーダーを返します（テキスト形式はカラーを付けない既定のフォーマット）。
+// format: ログ出力形式
+func NewEncoder(format LogOutputFormat) Encoder {
+	switch format {
+	case JSON:
+		return JSONEncoder{}
+	case CSV:
+		return CSVEncoder{}
+	case LOGFMT:
+		return LogfmtEncoder{}
+	case ECS:
+		return ECSEncoder{}
+	default:
+		return &TextEncoder{}
+	}
+}
+
+// TextEncoderは、標準のlogパッケージと同じ日時に続けて、プレフィックスとフォーマットしたメッセージを出力するエンコーダーです。
+// フィールドはメッセージの後に key=value の形式で付けます。
+type TextEncoder struct {
+	// フォーマット（タイムスタンプ、ログレベル、メッセージの順。空の場合は "[%s] [%s] %s"）
+	Format string
+	// ログレベルごとのプレフィックス
+	Prefixes map[LogLevel]string
+	// ログレベルごとのカラー（nilの場合はカラーを付けない）
+	Colors map[LogLevel]string
+}
+
+// Encodeは、ログをテキスト形式の1行に変換します。
+// entry: ログ
+func (e *TextEncoder) Encode(entry Entry) []byte {
+	format := e.Format
+	if format == "" {
+		format = defaultLogFormat
+	}
+	var b bytes.Buffer
+	b.WriteString(entry.Time.Format("2006/01/02 15:04:05 "))
+	color := e.Colors[entry.Level]
+	b.WriteString(color)
+	b.WriteString(e.Prefixes[entry.Level])
+	fmt.Fprintf(&b, format, entry.Time.Format(time.RFC3339), getLevelString(entry.Level), entry.Message+formatFields(entry.Fields))
+	if color != "" {
+		b.WriteString("\033[0m")
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// JSONEncoderは、タイムスタンプ（timestamp）、ログレベル（level）、メッセージ（message）とフィールドを
+// 1つのJSONオブジェクトとして出力するエンコーダーです。
+type JSONEncoder struct{}
+
+// Encodeは、ログをJSONの1行に変換します。フィールドは既定のキーを上書きしません。
+// entry: ログ
+func (JSONEncoder) Encode(entry Entry) []byte {
+	object := make(map[string]interface{}, len(entry.Fields)+3)
+	for _, field := range entry.Fields {
+		object[field.Key] = jsonValue(field.Value)
+	}
+	object["timestamp"] = entry.Time.Format(time.RFC3339)
+	object["level"] = getLevelString(entry.Level)
+	object["message"] = entry.Message
+	return marshalLine(object, entry.Fields, func(field Field) string { return field.Key })
+}
+
+// ECSEncoderは、Elastic Common Schema の形式のJSONを出力するエンコーダーです。
+// リクエストID、ユーザーID、トレースIDなどはECSのフィールドに対応付け、それ以外のフィールドは labels に文字列で出力します。
+type ECSEncoder struct{}
+
+// Encodeは、ログをECSのJSONの1行に変換します。
+// entry: ログ
+func (ECSEncoder) Encode(entry Entry) []byte {
+	object := map[string]interface{}{
+		"@timestamp":  entry.Time.UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		"log.level":   strings.ToLower(getLevelString(entry.Level)),
+		"message":     entry.Message,
+		"ecs.version": ecsVersion,
+	}
+	labels := make(map[string]string)
+	for _, field := range entry.Fields {
+		if name, ok := ecsFieldNames[field.Key]; ok {
+			object[name] = jsonValue(field.Value)
+			continue
+		}
+		// labels のキーにはドットを使用できない
+		labels[strings.ReplaceAll(field.Key, ".", "_")] = fmt.Sprint(field.Value)
+	}
+	if len(labels) > 0 {
+		object["labels"] = labels
+	}
+	return marshalLine(object, entry.Fields, func(field Field) string { return ecsFieldNames[field.Key] })
+}
+
+// marshalLineは、JSONオブジェクトを改行を付けた1行に変換します。
+// フィールドの値を変換できない場合は、フィールドの値を文字列にして変換し直します。
+// object: JSONオブジェクト
+// fields: オブジェクトに含めたフィールド
+// name: フィールドのオブジェクトのキー（空の場合は変換し直さない）
+func marshalLine(object map[string]interface{}, fields []Field, name func(Field) string) []byte {
+	line, err := json.Marshal(object)
+	if err != nil {
+		for _, field := range fields {
+			if key := name(field); key != "" {
+				object[key] = fmt.Sprint(field.Value)
+			}
+		}
+		line, _ = json.Marshal(object)
+	}
+	return append(line, '\n')
+}
+
+// LogfmtEncoderは、time、level、msg とフィールドを key=value の形式で出力するエンコーダー（logfmt）です。
+// 空白、引用符、"=" や制御文字を含む値は引用符で囲み、エスケープします。
+type LogfmtEncoder struct{}
+
+// Encodeは、ログをlogfmtの1行に変換します。
+// entry: ログ
+func (LogfmtEncoder) Encode(entry Entry) []byte {
+	var b bytes.Buffer
+	writeLogfmt(&b, "time", entry.Time.Format(time.RFC3339Nano))
+	writeLogfmt(&b, "level", strings.ToLower(getLevelString(entry.Level)))
+	writeLogfmt(&b, "msg", entry.Message)
+	for _, field := range entry.Fields {
+		writeLogfmt(&b, field.Key, fmt.Sprint(field.Value))
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// writeLogfmtは、key=value を書き込みます。キーに使用できない文字は "_" に置き換えます。
+// b: 書き込み先
+// key: キー
+// value: 値
+func writeLogfmt(b *bytes.Buffer, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	key = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, key)
+	if key == "" {
+		key = "_"
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+	if value == "" || strings.IndexFunc(value, func(r rune) bool { return r <= ' ' || r == '=' || r == '"' || r == 0x7f }) >= 0 {
+		value = strconv.Quote(value)
+	}
+	b.WriteString(value)
+}
+
+// CSVEncoderは、タイムスタンプ、ログレベル、メッセージ、フィールド（key=value の空白区切り）の4列を
+// RFC 4180 に従って出力するエンコーダーです。カンマ、引用符、改行を含む列は引用符で囲みます。
+type CSVEncoder struct{}
+
+// Encodeは、ログをCSVの1行（CRLFで終わる）に変換します。
+// entry: ログ
+func (CSVEncoder) Encode(entry Entry) []byte {
+	var b bytes.Buffer
+	writer := csv.NewWriter(&b)
+	writer.UseCRLF = true
+	writer.Write([]string{entry.Time.Format(time.RFC3339), getLevelString(entry.Level), entry.Message, strings.TrimPrefix(formatFields(entry.Fields), " ")})
+	writer.Flush()
+	return b.Bytes()
+}
+
+// WriterSinkは、ログをエンコーダーで変換して io.Writer に書き込むシンクです。
+// ロガーの出力形式によらず、出力先ごとにエンコーダーを選択できます。
+type WriterSink struct {
+	// 書き込み先
+	writer io.Writer
+	// エンコーダー
+	encoder Encoder
+	// 書き込む最小のログレベル
+	minLevel LogLevel
+	// 書き込み先への書き込みを排他制御するミューテックス
+	mu sync.Mutex
+}
+
+// NewWriterSinkは、WriterSink を作成します。
+// w: 書き込み先
+// encoder: エンコーダー（nilの場合はカラーを付けないテキスト形式）
+// minLevel: 書き込む最小のログレベル
+func NewWriterSink(w io.Writer, encoder Encoder, minLevel LogLevel) *WriterSink {
+	if encoder == nil {
+		encoder = &TextEncoder{}
+	}
+	return &WriterSink{writer: w, encoder: encoder, minLevel: minLevel}
+}
+
+// WriteEntryは、ログを変換して書き込みます。書き込みのエラーはログの呼び出し元に返せないため無視します。
+// entry: ログ
+func (s *WriterSink) WriteEntry(entry Entry) {
+	if entry.Level < s.minLevel {
+		return
+	}
+	line := s.encoder.Encode(entry)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writer.Write(line)
+}
+
+// isTerminalは、書き込み先が端末（キャラクターデバイス）かを判定します。
+// w: 書き込み先
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ParseLogOutputFormatは、文字列をログ出力形式に変換します。
+// s: ログ出力形式の文字列（text, json, csv, logfmt, ecs）
+func ParseLogOutputFormat(s string) (LogOutputFormat, error) {
+	switch strings.ToLower(s) {
+	case "text":
+		return TEXT, nil
+	case "json":
+		return JSON, nil
+	case "csv":
+		return CSV, nil
+	case "logfmt":
+		return LOGFMT, nil
+	case "ecs":
+		return ECS, nil
+	default:
+		return TEXT, fmt.Errorf("unknown log format %q", s)
+	}
+}
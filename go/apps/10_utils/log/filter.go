@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"fmt"     // フォーマットI/O用パッケージ
+	"maps"    // マップ操作用パッケージ
+	"regexp"  // 正規表現用パッケージ
+	"slices"  // スライス操作用パッケージ
+	"strings" // 文字列操作用パッケージ
+)
+
+// FilterRuleは、ログを絞り込む規則です。設定した条件をすべて満たすログに一致します。
+type FilterRule struct {
+	// メッセージに含まれる文字列（空の場合は条件にしない）
+	Contains string
+	// メッセージに一致する正規表現（空の場合は条件にしない）
+	Pattern string
+	// フィールドの条件（キーと値。値が空の場合はキーがあれば一致）
+	Fields map[string]string
+	// 一致したログを出力しないかどうか（false の場合は、出力するログの条件とする）
+	Exclude bool
+}
+
+// Filterは、規則を組み合わせてログを絞り込むフィルターです。作成後は変更されないため、複数のゴルーチンから同時に使用できます。
+// 出力する規則がある場合はいずれかに一致したログのみ出力し、そのうち除外する規則のいずれかに一致したログは出力しません。
+type Filter struct {
+	// 出力する規則
+	includes []compiledRule
+	// 除外する規則
+	excludes []compiledRule
+	// 作成に使用した規則（Rules で返す）
+	rules []FilterRule
+}
+
+// compiledRuleは、正規表現をコンパイルした規則です。
+type compiledRule struct {
+	// 規則
+	FilterRule
+	// メッセージに一致する正規表現
+	pattern *regexp.Regexp
+}
+
+// NewFilterは、規則からフィルターを作成します。正規表現が不正な場合はエラーを返します。
+// rules: 規則（空の場合はすべてのログを出力する）
+func NewFilter(rules ...FilterRule) (*Filter, error) {
+	f := &Filter{}
+	for i, rule := range rules {
+		compiled := compiledRule{FilterRule: rule}
+		compiled.Fields = maps.Clone(rule.Fields)
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid log filter rule %d: %v", i, err)
+			}
+			compiled.pattern = pattern
+		}
+		if rule.Exclude {
+			f.excludes = append(f.excludes, compiled)
+		} else {
+			f.includes = append(f.includes, compiled)
+		}
+		f.rules = append(f.rules, compiled.FilterRule)
+	}
+	return f, nil
+}
+
+// Rulesは、フィルターの規則の複製を返します。
+func (f *Filter) Rules() []FilterRule {
+	if f == nil {
+		return nil
+	}
+	rules := make([]FilterRule, len(f.rules))
+	for i, rule := range f.rules {
+		rule.Fields = maps.Clone(rule.Fields)
+		rules[i] = rule
+	}
+	return rules
+}
+
+// Matchは、ログを出力するかを判定します。nil のフィルターはすべてのログを出力します。
+// message: ログメッセージ
+// fields: ログのフィールド
+func (f *Filter) Match(message string, fields []Field) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.includes) > 0 && !slices.ContainsFunc(f.includes, func(rule compiledRule) bool { return rule.match(message, fields) }) {
+		return false
+	}
+	return !slices.ContainsFunc(f.excludes, func(rule compiledRule) bool { return rule.match(message, fields) })
+}
+
+// matchは、ログが規則の条件をすべて満たすかを判定します。
+// message: ログメッセージ
+// fields: ログのフィールド
+func (r compiledRule) match(message string, fields []Field) bool {
+	if r.Contains != "" && !strings.Contains(message, r.Contains) {
+		return false
+	}
+	if r.pattern != nil && !r.pattern.MatchString(message) {
+		return false
+	}
+	for key, want := range r.Fields {
+		value, ok := fieldValue(fields, key)
+		if !ok || (want != "" && fmt.Sprint(value) != want) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldValueは、キーが一致する最後のフィールドの値を返します（With で上書きしたフィールドを優先する）。
+// fields: フィールド
+// key: キー
+func fieldValue(fields []Field, key string) (interface{}, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == key {
+			return fields[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// ParseComponentLevelsは、"grpc=WARN,monitor=DEBUG" の形式の文字列をコンポーネントごとのログレベルに変換します。
+// s: コンポーネントとログレベルを "=" でつなぎ、カンマで区切った文字列（空の場合は空のマップ）
+func ParseComponentLevels(s string) (map[string]LogLevel, error) {
+	levels := make(map[string]LogLevel)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		component, levelName, ok := strings.Cut(item, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" {
+			return nil, fmt.Errorf("invalid component log level %q", item)
+		}
+		level, err := ParseLogLevel(strings.TrimSpace(levelName))
+		if err != nil {
+			return nil, err
+		}
+		levels[component] = level
+	}
+	return levels, nil
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"crypto/rand" // 乱数生成用パッケージ
+	"fmt"         // フォーマットI/O用パッケージ
+	"net"         // ネットワーク用パッケージ
+	"os"          // OS機能用パッケージ
+	"regexp"      // 正規表現用パッケージ
+	"strings"     // 文字列操作用パッケージ
+)
+
+// UDPで送信するGELFのチャンクの大きさの既定値（バイト。ヘッダーを含む）
+const defaultGELFChunkSize = 1420
+
+// GELFのチャンクの上限
+const (
+	// チャンクのヘッダーの大きさ（マジックバイト2、メッセージID8、シーケンス番号1、チャンク数1）
+	gelfChunkHeaderSize = 12
+	// 1つのメッセージのチャンク数の上限
+	maxGELFChunks = 128
+)
+
+// GELFの追加フィールドの名前に使用できない文字
+var gelfFieldNamePattern = regexp.MustCompile(`[^\w.\-]`)
+
+// GELFConfigは、GELF（Graylog Extended Log Format 1.1）で送信するシンクの設定です。
+type GELFConfig struct {
+	// 接続の設定（TCPではメッセージをヌル文字で区切り、TLSConfig でTLSで接続する）
+	NetworkConfig
+	// 送信元のホスト名（空の場合は os.Hostname）
+	Host string
+	// UDPで送信するチャンクの大きさ（バイト。既定値は1420。超えるメッセージは最大128個のチャンクに分割する）
+	ChunkSize int
+}
+
+// NewGELFSinkは、ログをGELF（Graylog Extended Log Format 1.1）で送信する NetworkSink を作成します。
+// フィールドは "_" を付けた追加フィールドとして送信し、ログレベルはsyslogの重大度で送信します。
+// UDPではチャンクに分割して送信し、128個のチャンクに収まらないメッセージは破棄します。
+// cfg: 設定
+func NewGELFSink(cfg GELFConfig) (*NetworkSink, error) {
+	if cfg.Host == "" {
+		cfg.Host, _ = os.Hostname()
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = defaultGELFChunkSize
+	}
+	if cfg.ChunkSize <= gelfChunkHeaderSize {
+		return nil, fmt.Errorf("invalid GELF chunk size %d", cfg.ChunkSize)
+	}
+
+	encode := func(entry Entry) []byte {
+		shortMessage, _, _ := strings.Cut(entry.Message, "\n")
+		object := map[string]interface{}{
+			"version":       "1.1",
+			"host":          cfg.Host,
+			"short_message": shortMessage,
+			"timestamp":     float64(entry.Time.UnixMicro()) / 1e6,
+			"level":         syslogSeverity(entry.Level),
+		}
+		if shortMessage != entry.Message {
+			object["full_message"] = entry.Message
+		}
+		for _, field := range entry.Fields {
+			object[gelfFieldName(field)] = jsonValue(field.Value)
+		}
+		// marshalLine が付ける改行は、フレーミングに含めない
+		line := marshalLine(object, entry.Fields, gelfFieldName)
+		return line[:len(line)-1]
+	}
+	write := func(conn net.Conn, message []byte) error {
+		if cfg.Network == "udp" {
+			return writeGELFChunks(conn, message, cfg.ChunkSize)
+		}
+		// TCPではメッセージをヌル文字で区切る
+		_, err := conn.Write(append(message, 0))
+		return err
+	}
+	return newNetworkSink(cfg.NetworkConfig, encode, write)
+}
+
+// gelfFieldNameは、フィールドのキーをGELFの追加フィールドの名前にします。
+// 使用できない文字は "_" に置き換え、予約されている _id は __id にします。
+// field: フィールド
+func gelfFieldName(field Field) string {
+	name := "_" + gelfFieldNamePattern.ReplaceAllString(field.Key, "_")
+	if name == "_id" {
+		name = "__id"
+	}
+	return name
+}
+
+// writeGELFChunksは、メッセージをUDPのデータグラムで送信します。
+// チャンクの大きさを超える場合は、マジックバイト、メッセージID、シーケンス番号、チャンク数のヘッダーを付けて分割します。
+// conn: 接続
+// message: メッセージ
+// chunkSize: チャンクの大きさ（ヘッダーを含む）
+func writeGELFChunks(conn net.Conn, message []byte, chunkSize int) error {
+	if len(message) <= chunkSize {
+		_, err := conn.Write(message)
+		return err
+	}
+	dataSize := chunkSize - gelfChunkHeaderSize
+	count := (len(message) + dataSize - 1) / dataSize
+	if count > maxGELFChunks {
+		return fmt.Errorf("%w: %d bytes exceeds %d GELF chunks", errMessageTooLarge, len(message), maxGELFChunks)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		chunk := make([]byte, 0, chunkSize)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, message[i*dataSize:min((i+1)*dataSize, len(message))]...)
+		if _, err := conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGELFSinkTCP(t *testing.T) {
+	ln := listenTCP(t)
+	sink, err := NewGELFSink(GELFConfig{NetworkConfig: NetworkConfig{Network: "tcp", Address: ln.Addr().String()}, Host: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sink.WriteEntry(testEntry(ERROR, "failed\nstack trace", Field{Key: "id", Value: 7}, Field{Key: "user name", Value: "alice"}))
+	sink.WriteEntry(testEntry(INFO, "second"))
+
+	// TCPではメッセージをヌル文字で区切る
+	r := bufio.NewReader(accept(t, ln))
+	first, err := readNULTerminated(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(first), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", first, err)
+	}
+	want := map[string]interface{}{
+		"version":       "1.1",
+		"host":          "host",
+		"short_message": "failed",
+		"full_message":  "failed\nstack trace",
+		"timestamp":     1714564800.123456,
+		"level":         float64(3),
+		"__id":          float64(7),
+		"_user_name":    "alice",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+
+	second, err := readNULTerminated(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(second, `"short_message":"second"`) {
+		t.Errorf("second message = %q", second)
+	}
+}
+
+func TestGELFSinkUDP(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		wantChunks int
+	}{
+		{name: "チャンクサイズ以下", message: "short", wantChunks: 0},
+		{name: "チャンクに分割", message: strings.Repeat("x", 500), wantChunks: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := listenUDP(t)
+			sink, err := NewGELFSink(GELFConfig{NetworkConfig: NetworkConfig{Network: "udp", Address: pc.LocalAddr().String()}, Host: "host", ChunkSize: 112})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sink.Close()
+			sink.WriteEntry(testEntry(INFO, tt.message))
+
+			buf := make([]byte, 2048)
+			n, _, err := pc.ReadFrom(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			message := append([]byte(nil), buf[:n]...)
+			if tt.wantChunks > 0 {
+				// マジックバイト、メッセージID（8バイト）、シーケンス番号、チャンク数のヘッダーを付けて分割する
+				if n > 112 || !bytes.HasPrefix(message, []byte{0x1e, 0x0f}) {
+					t.Fatalf("chunk = %q", message)
+				}
+				id, count := message[2:10], int(message[11])
+				if count != tt.wantChunks || message[10] != 0 {
+					t.Fatalf("chunk header seq=%d count=%d, want 0/%d", message[10], count, tt.wantChunks)
+				}
+				message = message[12:]
+				for seq := 1; seq < count; seq++ {
+					n, _, err := pc.ReadFrom(buf)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !bytes.Equal(buf[2:10], id) || int(buf[10]) != seq || int(buf[11]) != count {
+						t.Fatalf("chunk %d header = %x", seq, buf[:12])
+					}
+					message = append(message, buf[12:n]...)
+				}
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(message, &got); err != nil {
+				t.Fatalf("unmarshal %q: %v", message, err)
+			}
+			if got["short_message"] != tt.message {
+				t.Errorf("short_message = %v, want %q", got["short_message"], tt.message)
+			}
+		})
+	}
+}
+
+func TestGELFSinkUDPTooLarge(t *testing.T) {
+	pc := listenUDP(t)
+	sink, err := NewGELFSink(GELFConfig{NetworkConfig: NetworkConfig{Network: "udp", Address: pc.LocalAddr().String()}, Host: "host", ChunkSize: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	// 128個のチャンクに収まらないメッセージは破棄し、次のログを送信する
+	sink.WriteEntry(testEntry(INFO, strings.Repeat("x", 8*maxGELFChunks)))
+	sink.WriteEntry(testEntry(INFO, "ok"))
+
+	// 最初に受信するのは "ok" のメッセージの最初のチャンクになる
+	buf := make([]byte, 2048)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(buf[:n], []byte{0x1e, 0x0f}) || buf[10] != 0 || int(buf[11]) > maxGELFChunks {
+		t.Fatalf("first chunk header = %x, want the first chunk of the next message", buf[:12])
+	}
+}
@@ -1,12 +1,15 @@
 package logger
 
 import (
-	"encoding/json" // JSONエンコーディング/デコーディング用パッケージ
-	"fmt"           // フォーマットI/O用パッケージ
-	"io"            // I/Oインターフェース用パッケージ
-	"log"           // ログ出力用パッケージ
-	"os"            // OS機能用パッケージ
-	"time"          // 時間操作用パッケージ
+	"fmt"         // フォーマットI/O用パッケージ
+	"io"          // I/Oインターフェース用パッケージ
+	"maps"        // マップ操作用パッケージ
+	"os"          // OS機能用パッケージ
+	"strconv"     // 文字列変換用パッケージ
+	"strings"     // 文字列操作用パッケージ
+	"sync"        // 排他制御用パッケージ
+	"sync/atomic" // アトミック操作用パッケージ
+	"time"        // 時間操作用パッケージ
 )
 
 // ログレベルを表す型
@@ -33,246 +36,412 @@ const (
 	TEXT LogOutputFormat = iota
 	// JSON形式
 	JSON
-	// CSV形式
+	// CSV形式（RFC 4180）
 	CSV
+	// logfmt形式
+	LOGFMT
+	// ECS（Elastic Common Schema）のJSON形式
+	ECS
 )
 
-// グローバル変数の宣言
-var (
-	// 現在のログレベル
-	currentLogLevel = INFO
-	// 現在のログ出力形式
-	currentLogOutputFormat = TEXT
-	// ロガーのマップ
-	loggers = make(map[LogLevel]*log.Logger)
-	// ログファイル
-	logFile *os.File
-	// ログ出力先のマップ
-	logOutputs = make(map[LogLevel][]io.Writer)
-	// ログプレフィックスのマップ
-	logPrefixes = make(map[LogLevel]string)
-	// ログフォーマット
-	logFormat = "[%s] [%s] %s"
-	// ログレベルごとのカラー
-	logColors = map[LogLevel]string{
-		DEBUG: "\033[34m", // 青
-		INFO:  "\033[32m", // 緑
-		WARN:  "\033[33m", // 黄
-		ERROR: "\033[31m", // 赤
-	}
-	// ログフィルターキーワード
-	logFilterKeyword string
-)
+// 既定のログフォーマット（タイムスタンプ、ログレベル、メッセージの順）
+const defaultLogFormat = "[%s] [%s] %s"
+
+// 値がないキー（With の引数が奇数の場合）のフィールド名
+const badKey = "!BADKEY"
+
+// FieldComponentは、コンポーネントごとのログレベル（Config.ComponentLevels）を適用するフィールドのキーです。
+const FieldComponent = "component"
+
+// 出力するすべてのログレベル
+var logLevels = []LogLevel{DEBUG, INFO, WARN, ERROR}
+
+// 既定のログレベルごとのカラー
+var defaultLogColors = map[LogLevel]string{
+	DEBUG: "\033[34m", // 青
+	INFO:  "\033[32m", // 緑
+	WARN:  "\033[33m", // 黄
+	ERROR: "\033[31m", // 赤
+}
 
-// 初期化関数
-func init() {
-	// 各ログレベルに対してデフォルトのロガーと出力先を設定
-	for _, level := range []LogLevel{DEBUG, INFO, WARN, ERROR} {
-		// デフォルトのロガーを標準出力に設定
-		loggers[level] = log.New(os.Stdout, "", log.LstdFlags)
-		// デフォルトの出力先を標準出力に設定
-		logOutputs[level] = []io.Writer{os.Stdout}
+// Fieldは、ログに付与する構造化されたフィールドです。
+type Field struct {
+	// キー
+	Key string
+	// 値
+	Value interface{}
+}
+
+// Configは、ロガーの設定です。
+type Config struct {
+	// 出力する最小のログレベル（ゼロ値はDEBUG）
+	Level LogLevel
+	// ログ出力形式
+	OutputFormat LogOutputFormat
+	// 出力先に書き込むエンコーダー（nilの場合は OutputFormat のエンコーダー）
+	Encoder Encoder
+	// ログレベルごとの出力先（nilの場合はすべてのレベルを標準出力に出力）
+	Outputs map[LogLevel][]io.Writer
+	// ログレベルごとのプレフィックス
+	Prefixes map[LogLevel]string
+	// テキスト形式のフォーマット（タイムスタンプ、ログレベル、メッセージの順。空の場合は "[%s] [%s] %s"）
+	Format string
+	// テキスト形式のログレベルごとのカラー（nilの場合は既定のカラー。カラーを付けない場合は空のマップ）
+	// カラーは端末の出力先にのみ付ける
+	Colors map[LogLevel]string
+	// ログフィルターキーワード（このキーワードをメッセージに含むログのみ出力）
+	FilterKeyword string
+	// ログを絞り込むフィルター（nilの場合は絞り込まない）
+	// FilterKeyword とともに、出力形式によらずすべての出力先とシンクに適用する
+	Filter *Filter
+	// ログのメッセージとフィールドの機密情報を伏せ字にする Redactor（nilの場合は伏せ字にしない）
+	// 出力形式によらずすべての出力先とシンクに適用する
+	Redactor *Redactor
+	// コンポーネント（component フィールドの値）ごとの最小のログレベル（設定がないコンポーネントは Level）
+	ComponentLevels map[string]LogLevel
+	// 同じメッセージのログを間引くサンプラー（nilの場合は間引かない）
+	Sampler *Sampler
+	// コンポーネント（component フィールドの値）ごとのサンプラー（WithSampler で設定したサンプラーと Sampler より優先する）
+	ComponentSamplers map[string]*Sampler
+	// ログの項目単位で出力する出力先（Level 以上のログを出力形式によらず渡す）
+	Sinks []Sink
+
+	// 出力先に書き込むエンコーダー（normalizeConfig で設定する）
+	encoder Encoder
+	// 端末の出力先に書き込むエンコーダー（テキスト形式の場合はカラーを付ける）
+	terminalEncoder Encoder
+	// ログレベルごとの出力先が端末かどうか（Outputs と同じ順）
+	terminals map[LogLevel][]bool
+}
+
+// DefaultConfigは、INFO以上をテキスト形式で標準出力に出力し、既定の規則で機密情報を伏せ字にする設定を返します。
+func DefaultConfig() Config {
+	return Config{Level: INFO, OutputFormat: TEXT, Redactor: DefaultRedactor()}
+}
+
+// cloneは、マップとスライスを複製した設定を返します。
+func (c Config) clone() Config {
+	if c.Outputs != nil {
+		outputs := make(map[LogLevel][]io.Writer, len(c.Outputs))
+		for level, writers := range c.Outputs {
+			outputs[level] = append([]io.Writer(nil), writers...)
+		}
+		c.Outputs = outputs
+	}
+	c.Sinks = append([]Sink(nil), c.Sinks...)
+	if c.Prefixes != nil {
+		prefixes := make(map[LogLevel]string, len(c.Prefixes))
+		for level, prefix := range c.Prefixes {
+			prefixes[level] = prefix
+		}
+		c.Prefixes = prefixes
+	}
+	if c.Colors != nil {
+		colors := make(map[LogLevel]string, len(c.Colors))
+		for level, color := range c.Colors {
+			colors[level] = color
+		}
+		c.Colors = colors
 	}
+	c.ComponentLevels = maps.Clone(c.ComponentLevels)
+	c.ComponentSamplers = maps.Clone(c.ComponentSamplers)
+	return c
 }
 
-// ログレベルを設定
-func SetLogLevel(level LogLevel) {
-	// 現在のログレベルを更新
-	currentLogLevel = level
+// minLevelは、コンポーネントのログに適用する最小のログレベルを返します。
+// component: コンポーネント（空の場合は Level）
+func (c *Config) minLevel(component string) LogLevel {
+	if level, ok := c.ComponentLevels[component]; ok && component != "" {
+		return level
+	}
+	return c.Level
 }
 
-// ログ出力形式を設定
-func SetLogOutputFormat(format LogOutputFormat) {
-	currentLogOutputFormat = format
+// samplerは、ロガーのログに適用するサンプラーを返します。
+// コンポーネントのサンプラー、ロガーのサンプラー（WithSampler）、Sampler の順に優先します。
+// l: ロガー
+func (c *Config) sampler(l *Logger) *Sampler {
+	if sampler, ok := c.ComponentSamplers[l.component]; ok && l.component != "" {
+		return sampler
+	}
+	if l.sampler != nil {
+		return l.sampler
+	}
+	return c.Sampler
 }
 
-// ログ出力先を設定
-// level: ログレベル
-// w: ログ出力先のio.Writer
-func SetLogOutput(level LogLevel, w io.Writer) {
-	// 指定されたログレベルのロガーが存在する場合
-	if logger, exists := loggers[level]; exists {
-		// 出力先を追加
-		logOutputs[level] = append(logOutputs[level], w)
-		// 複数の出力先をまとめる
-		multiWriter := io.MultiWriter(logOutputs[level]...)
-		// ロガーの出力先を更新
-		logger.SetOutput(multiWriter)
+// matchは、ログがフィルターキーワードとフィルターの条件を満たすかを判定します。
+// message: ログメッセージ
+// fields: ログのフィールド
+func (c *Config) match(message string, fields []Field) bool {
+	if c.FilterKeyword != "" && !strings.Contains(message, c.FilterKeyword) {
+		return false
 	}
+	return c.Filter.Match(message, fields)
 }
 
-// ログファイルを設定
-// level: ログレベル
-// filePath: ログファイルのパス
-func SetLogFile(level LogLevel, filePath string) error {
-	// 既存のログファイルがある場合
-	if logFile != nil {
-		// ログファイルを閉じる
-		logFile.Close()
+// Loggerは、設定とフィールドを持つロガーです。
+// 設定とフィールドは作成後に変更されないため、複数のゴルーチンから同時に使用できます。
+type Logger struct {
+	// 設定と書き込みの排他制御（With で作成した子のロガーと共有する）
+	core *loggerCore
+	// ログに付与するフィールド（作成後は変更しない）
+	fields []Field
+	// component フィールドの値（コンポーネントごとのログレベルの判定に使用）
+	component string
+	// 同じメッセージのログを間引くサンプラー（nilの場合は設定のサンプラー）
+	sampler *Sampler
+}
+
+// loggerCoreは、親子のロガーで共有する設定と書き込みの排他制御です。
+type loggerCore struct {
+	// 設定（変更せず、既定のロガーの設定を変更する関数は新しい設定に置き換える）
+	config atomic.Pointer[Config]
+	// 出力先への書き込みを排他制御するミューテックス
+	mu sync.Mutex
+}
+
+// Newは、設定からロガーを作成します。設定は複製されるため、作成後に設定のマップを変更しても影響しません。
+// cfg: ロガーの設定
+func New(cfg Config) *Logger {
+	core := &loggerCore{}
+	core.config.Store(normalizeConfig(cfg))
+	return &Logger{core: core}
+}
+
+// normalizeConfigは、設定を複製し、省略された項目に既定値を設定します。
+// cfg: ロガーの設定
+func normalizeConfig(cfg Config) *Config {
+	config := cfg.clone()
+	if config.Outputs == nil {
+		config.Outputs = make(map[LogLevel][]io.Writer, len(logLevels))
+		for _, level := range logLevels {
+			config.Outputs[level] = []io.Writer{os.Stdout}
+		}
+	}
+	if config.Colors == nil {
+		config.Colors = Config{Colors: defaultLogColors}.clone().Colors
+	}
+	if config.Format == "" {
+		config.Format = defaultLogFormat
 	}
-	var err error
-	// ログファイルを開く
-	logFile, err = os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-	// エラーが発生した場合、エラーを返す
-	if err != nil {
-		return err
+	config.encoder = config.Encoder
+	config.terminalEncoder = config.Encoder
+	if config.Encoder == nil {
+		config.encoder = NewEncoder(config.OutputFormat)
+		config.terminalEncoder = config.encoder
+		if config.OutputFormat == TEXT {
+			config.encoder = &TextEncoder{Format: config.Format, Prefixes: config.Prefixes}
+			config.terminalEncoder = &TextEncoder{Format: config.Format, Prefixes: config.Prefixes, Colors: config.Colors}
+		}
+	}
+	config.terminals = make(map[LogLevel][]bool, len(config.Outputs))
+	for level, writers := range config.Outputs {
+		terminals := make([]bool, len(writers))
+		for i, w := range writers {
+			terminals[i] = isTerminal(w)
+		}
+		config.terminals[level] = terminals
 	}
-	// ログ出力先にファイルを追加
-	SetLogOutput(level, logFile)
-	return nil
+	return &config
 }
 
-// ログファイルをローテーション
-// level: ログレベル
-// filePath: ログファイルのパス
-func RotateLogFile(level LogLevel, filePath string) error {
-	// 既存のログファイルがある場合
-	if logFile != nil {
-		// ログファイルを閉じる
-		logFile.Close()
+// Withは、キーと値を交互に並べたフィールドを追加した子のロガーを返します。
+// 子のロガーは親の設定と出力先を共有し（親の設定の変更は子にも反映されます）、親のロガーは変更されません。
+// keyValues: キーと値を交互に並べたもの（例: "component", "monitor", "service", name）
+func (l *Logger) With(keyValues ...interface{}) *Logger {
+	if len(keyValues) == 0 {
+		return l
 	}
-	// バックアップファイルのパスを生成
-	backupPath := fmt.Sprintf("%s.%s", filePath, time.Now().Format("20060102T150405"))
-	// ログファイルをバックアップ
-	err := os.Rename(filePath, backupPath)
-	// エラーが発生した場合、エラーを返す
-	if err != nil {
-		return err
+	fields := make([]Field, 0, len(l.fields)+(len(keyValues)+1)/2)
+	fields = append(fields, l.fields...)
+	fields = appendFields(fields, keyValues)
+	// 追加したフィールドに component がある場合は、親のコンポーネントを上書きする
+	component := l.component
+	if value, ok := fieldValue(fields[len(l.fields):], FieldComponent); ok {
+		component = fmt.Sprint(value)
 	}
-	// 新しいログファイルを設定
-	return SetLogFile(level, filePath)
+	return &Logger{core: l.core, fields: fields, component: component, sampler: l.sampler}
 }
 
-// ログプレフィックスを設定
-// level: ログレベル
-// prefix: ログプレフィックス
-func SetLogPrefix(level LogLevel, prefix string) {
-	// 指定されたログレベルのプレフィックスを設定
-	logPrefixes[level] = prefix
+// WithSamplerは、同じメッセージのログをサンプラーで間引く子のロガーを返します。
+// 子のロガーから With で作成したロガーも同じサンプラーを使用します。
+// 設定にコンポーネントのサンプラー（Config.ComponentSamplers）がある場合は、そちらを優先します。
+// sampler: サンプラー（nilの場合は設定のサンプラー）
+func (l *Logger) WithSampler(sampler *Sampler) *Logger {
+	return &Logger{core: l.core, fields: l.fields, component: l.component, sampler: sampler}
+}
+
+// appendFieldsは、キーと値を交互に並べたものをフィールドとして追加します。
+// 文字列でないキーは文字列に変換し、値がない最後のキーは "!BADKEY" の値とします。
+// fields: 追加先のフィールド
+// keyValues: キーと値を交互に並べたもの
+func appendFields(fields []Field, keyValues []interface{}) []Field {
+	for i := 0; i < len(keyValues); i += 2 {
+		if i+1 == len(keyValues) {
+			fields = append(fields, Field{Key: badKey, Value: keyValues[i]})
+			break
+		}
+		key, ok := keyValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyValues[i])
+		}
+		fields = append(fields, Field{Key: key, Value: keyValues[i+1]})
+	}
+	return fields
 }
 
-// ログフォーマットを設定
-// format: ログフォーマット
-func SetLogFormat(format string) {
-	// ログフォーマットを更新
-	logFormat = format
+// Fieldsは、ロガーに付与されたフィールドの複製を返します。
+func (l *Logger) Fields() []Field {
+	return append([]Field(nil), l.fields...)
 }
 
-// ログカラーを設定
+// Enabledは、ログレベルのログを出力するかを判定します。
+// ロガーに component フィールドがある場合は、コンポーネントのログレベルで判定します。
 // level: ログレベル
-// color: ログカラー
-func SetLogColor(level LogLevel, color string) {
-	// 指定されたログレベルのカラーを設定
-	logColors[level] = color
+func (l *Logger) Enabled(level LogLevel) bool {
+	return level >= l.core.config.Load().minLevel(l.component)
+}
+
+// デバッグログを出力
+// message: ログメッセージ
+func (l *Logger) Debug(message string) {
+	l.log(DEBUG, message)
+}
+
+// インフォログを出力
+// message: ログメッセージ
+func (l *Logger) Info(message string) {
+	l.log(INFO, message)
 }
 
-// ログフィルターキーワードを設定
-// keyword: ログフィルターキーワード
-func SetLogFilterKeyword(keyword string) {
-	// ログフィルターキーワードを設定
-	logFilterKeyword = keyword
+// 警告ログを出力
+// message: ログメッセージ
+func (l *Logger) Warn(message string) {
+	l.log(WARN, message)
+}
+
+// エラーログを出力
+// message: ログメッセージ
+func (l *Logger) Error(message string) {
+	l.log(ERROR, message)
 }
 
 // ログメッセージを出力
 // level: ログレベル
 // message: ログメッセージ
-func logMessage(level LogLevel, message string) {
-	switch currentLogOutputFormat {
-	case TEXT:
-		logMessageAsText(level, message)
-	case JSON:
-		logMessageAsJSON(level, message)
-	case CSV:
-		logMessageAsCSV(level, message)
-	}
+func (l *Logger) log(level LogLevel, message string) {
+	l.logAt(time.Now(), level, message)
 }
 
-// テキスト形式でログメッセージを出力
+// 出力時刻を指定してログメッセージを出力（slog のレコードなど、出力時刻が決まっているログに使用）
+// now: 出力時刻
 // level: ログレベル
 // message: ログメッセージ
-func logMessageAsText(level LogLevel, message string) {
-	// 現在のログレベル以上の場合
-	if level >= currentLogLevel {
-		// フィルターキーワードが設定されている場合
-		if logFilterKeyword != "" && !containsKeyword(message, logFilterKeyword) {
-			// キーワードが含まれていない場合、ログを出力しない
+func (l *Logger) logAt(now time.Time, level LogLevel, message string) {
+	// 出力中に設定が置き換えられても、1件のログには同じ設定を使用する
+	config := l.core.config.Load()
+	// 現在のログレベル（コンポーネントのログレベル）未満の場合は出力しない
+	if level < config.minLevel(l.component) {
+		return
+	}
+	// フィルターキーワードとフィルターの条件を満たさない場合は、出力形式とシンクによらず出力しない
+	if !config.match(message, l.fields) {
+		return
+	}
+	// サンプラーで間引く場合は、出力しなかったログの件数をまとめて出力する
+	if sampler := config.sampler(l); sampler != nil {
+		ok, summaries := sampler.sample(l, now, level, message)
+		writeSummaries(now, summaries)
+		if !ok {
 			return
 		}
-		// タイムスタンプを取得
-		timestamp := time.Now().Format(time.RFC3339)
-		// ログレベルを文字列に変換
-		levelStr := getLevelString(level)
-		// プレフィックスを取得
-		prefix := logPrefixes[level]
-		// カラーを取得
-		color := logColors[level]
-		// フォーマットされたメッセージを生成
-		formattedMessage := fmt.Sprintf(logFormat, timestamp, levelStr, message)
-		// 指定されたログレベルのロガーが存在する場合
-		if logger, exists := loggers[level]; exists {
-			// カラーとプレフィックスを付けてメッセージを出力
-			logger.Printf("%s%s%s\033[0m", color, prefix, formattedMessage)
-		}
 	}
+	l.emit(config, Entry{Time: now, Level: level, Message: message, Fields: l.fields})
 }
 
-// JSON形式でログメッセージを出力
-// level: ログレベル
-// message: ログメッセージ
-func logMessageAsJSON(level LogLevel, message string) {
-	// 現在のログレベル以上の場合
-	if level >= currentLogLevel {
-		// タイムスタンプを取得
-		timestamp := time.Now().Format(time.RFC3339)
-		// ログレベルを文字列に変換
-		levelStr := getLevelString(level)
-		// ログエントリをマップとして作成
-		logEntry := map[string]string{
-			"timestamp": timestamp,
-			"level":     levelStr,
-			"message":   message,
+// ログを出力先とシンクに出力する
+// 機密情報を伏せ字にしてから、すべての出力先とシンクに渡す
+// config: ロガーの設定
+// entry: ログ
+func (l *Logger) emit(config *Config, entry Entry) {
+	entry = config.Redactor.Entry(entry)
+	l.write(config, entry)
+	for _, sink := range config.Sinks {
+		sink.WriteEntry(entry)
+	}
+}
+
+// ログをエンコーダーで変換し、ログレベルの出力先に書き込む
+// 端末の出力先と端末でない出力先で、それぞれ必要な場合のみ変換する
+// 出力先のエラーはログの呼び出し元に返せないため無視する
+// config: ロガーの設定
+// entry: ログ
+func (l *Logger) write(config *Config, entry Entry) {
+	writers := config.Outputs[entry.Level]
+	if len(writers) == 0 {
+		return
+	}
+	var line, terminalLine []byte
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+	for i, w := range writers {
+		if config.terminals[entry.Level][i] {
+			if terminalLine == nil {
+				terminalLine = config.terminalEncoder.Encode(entry)
+			}
+			w.Write(terminalLine)
+			continue
 		}
-		// ログエントリをJSON形式に変換
-		jsonMessage, _ := json.Marshal(logEntry)
-		// JSON形式のメッセージを出力
-		logMessageAsText(level, string(jsonMessage))
+		if line == nil {
+			line = config.encoder.Encode(entry)
+		}
+		w.Write(line)
 	}
 }
 
-// CSV形式でログメッセージを出力
-// level: ログレベル
-// message: ログメッセージ
-func logMessageAsCSV(level LogLevel, message string) {
-	// 現在のログレベル以上の場合
-	if level >= currentLogLevel {
-		// タイムスタンプを取得
-		timestamp := time.Now().Format(time.RFC3339)
-		// ログレベルを文字列に変換
-		levelStr := getLevelString(level)
-		// CSV形式のメッセージを生成
-		csvMessage := fmt.Sprintf("%s,%s,%s", timestamp, levelStr, message)
-		// 指定されたログレベルのロガーが存在する場合
-		if logger, exists := loggers[level]; exists {
-			// メッセージを出力
-			logger.Println(csvMessage)
+// フィールドを " key=value" の形式の文字列にする
+// 空の値や空白、引用符、"=" を含む値は引用符で囲む
+// fields: フィールド
+func formatFields(fields []Field) string {
+	var b strings.Builder
+	for _, field := range fields {
+		value := fmt.Sprint(field.Value)
+		if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+			value = strconv.Quote(value)
 		}
+		b.WriteByte(' ')
+		b.WriteString(field.Key)
+		b.WriteByte('=')
+		b.WriteString(value)
 	}
+	return b.String()
 }
 
-// メッセージがキーワードを含むか確認
-// message: ログメッセージ
-// keyword: フィルターキーワード
-func containsKeyword(message, keyword string) bool {
-	// キーワードが含まれているか確認
-	return keyword == "" || (keyword != "" && contains(message, keyword))
+// フィールドの値をJSONに変換できる値にする（エラーはメッセージの文字列にする）
+// value: フィールドの値
+func jsonValue(value interface{}) interface{} {
+	if err, ok := value.(error); ok {
+		return err.Error()
+	}
+	return value
 }
 
-// メッセージがキーワードを含むか確認（ヘルパー関数）
-// message: ログメッセージ
-// keyword: フィルターキーワード
-func contains(message, keyword string) bool {
-	// メッセージの先頭にキーワードが含まれているか確認
-	return len(message) >= len(keyword) && message[:len(keyword)] == keyword
+// 文字列をログレベルに変換
+// s: ログレベルの文字列（debug, info, warn, error）
+func ParseLogLevel(s string) (LogLevel, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return DEBUG, nil
+	case "info":
+		return INFO, nil
+	case "warn", "warning":
+		return WARN, nil
+	case "error":
+		return ERROR, nil
+	default:
+		return INFO, fmt.Errorf("unknown log level %q", s)
+	}
 }
 
 // ログレベルを文字列に変換
@@ -296,54 +465,3 @@ func getLevelString(level LogLevel) string {
 		return "UNKNOWN"
 	}
 }
-
-// デバッグログを出力
-// message: ログメッセージ
-func Debug(message string) {
-	// デバッグレベルのメッセージを出力
-	logMessage(DEBUG, message)
-}
-
-// インフォログを出力
-// message: ログメッセージ
-func Info(message string) {
-	// 情報レベルのメッセージを出力
-	logMessage(INFO, message)
-}
-
-// 警告ログを出力
-// message: ログメッセージ
-func Warn(message string) {
-	// 警告レベルのメッセージを出力
-	logMessage(WARN, message)
-}
-
-// エラーログを出力
-// message: ログメッセージ
-func Error(message string) {
-	// エラーレベルのメッセージを出力
-	logMessage(ERROR, message)
-}
-
-// ログメッセージをJSON形式で出力
-// level: ログレベル
-// message: ログメッセージ
-func LogMessageAsJSON(level LogLevel, message string) {
-	// 現在のログレベル以上の場合
-	if level >= currentLogLevel {
-		// タイムスタンプを取得
-		timestamp := time.Now().Format(time.RFC3339)
-		// ログレベルを文字列に変換
-		levelStr := getLevelString(level)
-		// ログエントリをマップとして作成
-		logEntry := map[string]string{
-			"timestamp": timestamp,
-			"level":     levelStr,
-			"message":   message,
-		}
-		// ログエントリをJSON形式に変換
-		jsonMessage, _ := json.Marshal(logEntry)
-		// JSON形式のメッセージを出力
-		logMessage(level, string(jsonMessage))
-	}
-}
@@ -0,0 +1,261 @@
+package logger
+
+import (
+	"crypto/tls"  // TLS用パッケージ
+	"errors"      // エラー操作用パッケージ
+	"fmt"         // フォーマットI/O用パッケージ
+	"net"         // ネットワーク用パッケージ
+	"os"          // OS機能用パッケージ
+	"sync"        // 排他制御用パッケージ
+	"sync/atomic" // アトミック操作用パッケージ
+	"time"        // 時間操作用パッケージ
+)
+
+// errMessageTooLarge は、送信できる大きさを超えたため、再接続しても送信できないメッセージのエラーです。
+var errMessageTooLarge = errors.New("log message too large")
+
+// NetworkConfigは、ネットワークのシンク（NetworkSink）の接続の設定です。ゼロ値の項目には既定値を使用します。
+type NetworkConfig struct {
+	// プロトコル（udp または tcp）
+	Network string
+	// 送信先のアドレス（host:port）
+	Address string
+	// TLSの設定（nilでない場合はTCPをTLSで接続する。UDPでは使用できない）
+	TLSConfig *tls.Config
+	// 送信する最小のログレベル（ゼロ値はDEBUG）
+	MinLevel LogLevel
+	// 送信を待つログの最大件数（既定値は1000件。超えた分は破棄し、件数を次の送信で通知する）
+	BufferSize int
+	// 接続のタイムアウト（既定値は5秒）
+	DialTimeout time.Duration
+	// 1件の書き込みのタイムアウト（既定値は5秒）
+	WriteTimeout time.Duration
+	// 再接続の最大待機時間（既定値は30秒。1秒から倍に延ばす）
+	MaxRetryDelay time.Duration
+	// 停止時に送信を待つログを送信する期限（既定値は5秒。接続と書き込みのタイムアウトもこの期限までに短縮する）
+	CloseTimeout time.Duration
+}
+
+// NetworkSinkは、ログをバックグラウンドでネットワークの送信先に送信するシンクです。
+// ログの呼び出し元はブロックされず、切断された場合は待機時間を延ばしながら再接続します。
+// 送信を待つログは BufferSize 件までで、超えた分は破棄して次の送信で件数を通知します。
+// NewSyslogSink または NewGELFSink で作成します。
+type NetworkSink struct {
+	// 接続の設定
+	config NetworkConfig
+	// ログをメッセージに変換する関数
+	encode func(entry Entry) []byte
+	// メッセージをフレーミングして接続に書き込む関数
+	write func(conn net.Conn, message []byte) error
+	// 送信を待つログ
+	entries chan Entry
+	// 送信を待つログが上限を超えて破棄した件数
+	dropped atomic.Int64
+	// 接続（バックグラウンドのゴルーチンのみが使用する）
+	conn net.Conn
+	// 停止を通知するチャネル
+	done chan struct{}
+	// バックグラウンドの処理の終了を待つ
+	wg sync.WaitGroup
+	// Close を1回だけ実行する
+	closeOnce sync.Once
+}
+
+// newNetworkSinkは、NetworkSink を作成し、バックグラウンドの送信を開始します。
+// 接続はバックグラウンドで行うため、送信先が停止していても作成できます。
+// cfg: 接続の設定
+// encode: ログをメッセージに変換する関数
+// write: メッセージをフレーミングして接続に書き込む関数
+func newNetworkSink(cfg NetworkConfig, encode func(Entry) []byte, write func(net.Conn, []byte) error) (*NetworkSink, error) {
+	switch cfg.Network {
+	case "tcp":
+	case "udp":
+		if cfg.TLSConfig != nil {
+			return nil, errors.New("TLS is not supported over UDP")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported network %q", cfg.Network)
+	}
+	if cfg.Address == "" {
+		return nil, errors.New("log server address is required")
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1000
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 5 * time.Second
+	}
+	if cfg.MaxRetryDelay <= 0 {
+		cfg.MaxRetryDelay = 30 * time.Second
+	}
+	if cfg.CloseTimeout <= 0 {
+		cfg.CloseTimeout = 5 * time.Second
+	}
+	s := &NetworkSink{
+		config:  cfg,
+		encode:  encode,
+		write:   write,
+		entries: make(chan Entry, cfg.BufferSize),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// WriteEntryは、ログを送信の待ちに追加します。待ちが上限に達している場合は破棄します。
+// entry: ログ
+func (s *NetworkSink) WriteEntry(entry Entry) {
+	if entry.Level < s.config.MinLevel {
+		return
+	}
+	select {
+	case s.entries <- entry:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Closeは、バックグラウンドの送信を停止します。
+// 送信を待つログは CloseTimeout まで送信し、送信できなかったログは破棄します。
+func (s *NetworkSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+	})
+	return nil
+}
+
+// runは、送信を待つログを順に送信します。停止時は期限まで残りのログを送信してから接続を閉じます。
+func (s *NetworkSink) run() {
+	defer s.wg.Done()
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}()
+	for {
+		select {
+		case entry := <-s.entries:
+			if !s.send(entry, time.Time{}) {
+				// 停止が要求されたため、送信できなかったログを破棄して残りのログを送信する
+				s.dropped.Add(1)
+				s.drain()
+				return
+			}
+		case <-s.done:
+			s.drain()
+			return
+		}
+	}
+}
+
+// drainは、停止時に送信を待つログを期限まで送信します。送信できなかったログは件数を標準エラー出力に出力します。
+func (s *NetworkSink) drain() {
+	deadline := time.Now().Add(s.config.CloseTimeout)
+	for {
+		select {
+		case entry := <-s.entries:
+			if !s.send(entry, deadline) {
+				s.dropped.Add(int64(len(s.entries)) + 1)
+				fmt.Fprintf(os.Stderr, "logger: dropped %d log entries for %s on close\n", s.dropped.Load(), s.config.Address)
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// sendは、ログを送信します。接続や書き込みに失敗した場合は、再接続して同じログを送信し直します。
+// 停止が要求された場合（deadline がゼロ値の場合）や期限を過ぎた場合は、送信を諦めて false を返します。
+// 停止時は、接続と書き込みも期限までに打ち切ります。
+// entry: ログ
+// deadline: 停止時の送信の期限（ゼロ値の場合は停止が要求されるまで再試行する）
+func (s *NetworkSink) send(entry Entry, deadline time.Time) bool {
+	message := s.encode(entry)
+	delay := time.Second
+	for {
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return false
+		}
+		err := s.connect(deadline)
+		if err == nil {
+			// 破棄したログがある場合は、件数を先に通知する
+			if n := s.dropped.Swap(0); n > 0 {
+				notice := Entry{Time: time.Now(), Level: WARN, Message: fmt.Sprintf("dropped %d log entries because the log buffer was full", n)}
+				if err = s.writeMessage(s.encode(notice), deadline); err != nil {
+					s.dropped.Add(n)
+				}
+			}
+			if err == nil {
+				err = s.writeMessage(message, deadline)
+			}
+			if err == nil {
+				return true
+			}
+			if errors.Is(err, errMessageTooLarge) {
+				// 再接続しても送信できないため、破棄する
+				fmt.Fprintf(os.Stderr, "logger: dropped a log entry for %s: %v\n", s.config.Address, err)
+				return true
+			}
+			s.conn.Close()
+			s.conn = nil
+		}
+		// ロガー自身に出力すると送信が再帰するため、標準エラー出力に出力する
+		fmt.Fprintf(os.Stderr, "logger: failed to send log to %s (retrying in %s): %v\n", s.config.Address, delay, err)
+
+		// 停止時は期限まで、それ以外は停止が要求されるまで待機して再試行する
+		if !deadline.IsZero() {
+			if time.Now().Add(delay).After(deadline) {
+				return false
+			}
+			time.Sleep(delay)
+		} else {
+			select {
+			case <-time.After(delay):
+			case <-s.done:
+				return false
+			}
+		}
+		delay = min(delay*2, s.config.MaxRetryDelay)
+	}
+}
+
+// connectは、接続していない場合に送信先に接続します。
+// deadline: 停止時の送信の期限（ゼロ値でない場合は接続をこの期限までに打ち切る）
+func (s *NetworkSink) connect(deadline time.Time) error {
+	if s.conn != nil {
+		return nil
+	}
+	dialer := &net.Dialer{Timeout: s.config.DialTimeout, Deadline: deadline}
+	var conn net.Conn
+	var err error
+	if s.config.TLSConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, s.config.Network, s.config.Address, s.config.TLSConfig)
+	} else {
+		conn, err = dialer.Dial(s.config.Network, s.config.Address)
+	}
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+// writeMessageは、書き込みのタイムアウトを設定してメッセージを書き込みます。
+// message: メッセージ
+// deadline: 停止時の送信の期限（ゼロ値でない場合は書き込みのタイムアウトをこの期限までに短縮する）
+func (s *NetworkSink) writeMessage(message []byte, deadline time.Time) error {
+	writeDeadline := time.Now().Add(s.config.WriteTimeout)
+	if !deadline.IsZero() && deadline.Before(writeDeadline) {
+		writeDeadline = deadline
+	}
+	if err := s.conn.SetWriteDeadline(writeDeadline); err != nil {
+		return err
+	}
+	return s.write(s.conn, message)
+}
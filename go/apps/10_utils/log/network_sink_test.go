@@ -0,0 +1,292 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// テストで送信するログの時刻
+var testEntryTime = time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+
+// testEntryは、テストで送信するログを作成します。
+func testEntry(level LogLevel, message string, fields ...Field) Entry {
+	return Entry{Time: testEntryTime, Level: level, Message: message, Fields: fields}
+}
+
+// listenTCPは、ローカルのTCPで待ち受け、テストの終了時に閉じます。
+func listenTCP(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	return ln
+}
+
+// listenUDPは、ローカルのUDPで待ち受け、テストの終了時に閉じます。
+func listenUDP(t *testing.T) net.PacketConn {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	pc.SetReadDeadline(time.Now().Add(10 * time.Second))
+	return pc
+}
+
+// unusedAddressは、待ち受けていないローカルのTCPのアドレスを返します（後で同じアドレスで待ち受けられる）。
+func unusedAddress(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// acceptは、接続を受け入れ、読み取りのタイムアウトを設定します。接続はテストの終了時に閉じます。
+func accept(t *testing.T, ln net.Listener) net.Conn {
+	t.Helper()
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	accepted := make(chan result, 1)
+	go func() {
+		conn, err := ln.Accept()
+		accepted <- result{conn, err}
+	}()
+	select {
+	case r := <-accepted:
+		if r.err != nil {
+			t.Fatal(r.err)
+		}
+		t.Cleanup(func() { r.conn.Close() })
+		r.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		return r.conn
+	case <-time.After(10 * time.Second):
+		t.Fatal("no connection accepted")
+		return nil
+	}
+}
+
+// readOctetCountedは、オクテットカウンティング（RFC 6587）でフレーミングされたメッセージを1件読み取ります。
+func readOctetCounted(r *bufio.Reader) (string, error) {
+	prefix, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	length, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+	if err != nil {
+		return "", fmt.Errorf("invalid message length %q", prefix)
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(r, message); err != nil {
+		return "", err
+	}
+	return string(message), nil
+}
+
+// readNULTerminatedは、ヌル文字で区切られたメッセージを1件読み取ります。
+func readNULTerminated(r *bufio.Reader) (string, error) {
+	message, err := r.ReadString(0)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(message, "\x00"), nil
+}
+
+func TestNewNetworkSinkValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config NetworkConfig
+	}{
+		{name: "不明なプロトコル", config: NetworkConfig{Network: "sctp", Address: "127.0.0.1:514"}},
+		{name: "アドレスなし", config: NetworkConfig{Network: "udp"}},
+		{name: "UDPのTLS", config: NetworkConfig{Network: "udp", Address: "127.0.0.1:514", TLSConfig: &tls.Config{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := NewSyslogSink(SyslogConfig{NetworkConfig: tt.config})
+			if err == nil {
+				sink.Close()
+				t.Fatal("NewSyslogSink() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestNetworkSinkReconnect(t *testing.T) {
+	ln := listenTCP(t)
+	address := ln.Addr().String()
+	sink, err := NewSyslogSink(SyslogConfig{
+		NetworkConfig: NetworkConfig{Network: "tcp", Address: address, MaxRetryDelay: time.Second},
+		Hostname:      "host",
+		AppName:       "app",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	sink.WriteEntry(testEntry(INFO, "before"))
+	conn := accept(t, ln)
+	if got, err := readOctetCounted(bufio.NewReader(conn)); err != nil || !strings.HasSuffix(got, " before") {
+		t.Fatalf("first message = %q, %v", got, err)
+	}
+
+	// 送信先を停止し、同じアドレスで再開する
+	conn.Close()
+	ln.Close()
+	reopened, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Skipf("cannot listen on %s again: %v", address, err)
+	}
+	t.Cleanup(func() { reopened.Close() })
+	received := make(chan error, 1)
+	go func() {
+		conn, err := reopened.Accept()
+		if err != nil {
+			received <- err
+			return
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(20 * time.Second))
+		r := bufio.NewReader(conn)
+		for {
+			message, err := readOctetCounted(r)
+			if err != nil || strings.HasSuffix(message, " after") {
+				received <- err
+				return
+			}
+		}
+	}()
+
+	// 切断を検知するまでに送信したログは失われうるため、再接続先で受信するまで送信を続ける
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(20 * time.Second)
+	for {
+		select {
+		case err := <-received:
+			if err != nil {
+				t.Fatal(err)
+			}
+			return
+		case <-ticker.C:
+			sink.WriteEntry(testEntry(INFO, "after"))
+		case <-timeout:
+			t.Fatal("no message received after reconnecting")
+		}
+	}
+}
+
+func TestNetworkSinkDroppedNotice(t *testing.T) {
+	address := unusedAddress(t)
+	sink, err := NewGELFSink(GELFConfig{NetworkConfig: NetworkConfig{Network: "tcp", Address: address, BufferSize: 2}, Host: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	// 送信先が停止している間に、送信を待つログの上限を超えて追加する
+	const total = 6
+	for i := 0; i < total; i++ {
+		sink.WriteEntry(testEntry(INFO, fmt.Sprintf("entry %d", i)))
+	}
+	// 接続に失敗して再試行を待つまで待機してから、送信先を開始する
+	time.Sleep(100 * time.Millisecond)
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Skipf("cannot listen on %s: %v", address, err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	r := bufio.NewReader(accept(t, ln))
+
+	// 再接続後、最初に破棄した件数を WARN で通知し、続けて残りのログを送信する
+	notice, err := readNULTerminated(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dropped int
+	if _, err := fmt.Sscanf(notice[strings.Index(notice, "dropped "):], "dropped %d log entries because the log buffer was full", &dropped); err != nil {
+		t.Fatalf("first message = %q, want dropped notice: %v", notice, err)
+	}
+	if !strings.Contains(notice, `"level":4`) {
+		t.Errorf("dropped notice level in %q, want 4 (warning)", notice)
+	}
+	// 送信中の1件と待ちの2件以外は破棄される
+	if dropped < total-3 || dropped > total-2 {
+		t.Errorf("dropped = %d, want %d or %d", dropped, total-3, total-2)
+	}
+	for i := dropped; i < total; i++ {
+		got, err := readNULTerminated(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(got, `"short_message":"entry `) {
+			t.Errorf("message = %q, want a remaining entry", got)
+		}
+	}
+}
+
+func TestNetworkSinkTLS(t *testing.T) {
+	// httptest のサーバーの自己署名証明書で待ち受ける
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: server.TLS.Certificates})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	sink, err := NewSyslogSink(SyslogConfig{
+		NetworkConfig: NetworkConfig{Network: "tcp", Address: ln.Addr().String(), TLSConfig: &tls.Config{RootCAs: roots}},
+		Hostname:      "host",
+		AppName:       "app",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sink.WriteEntry(testEntry(ERROR, "over tls"))
+
+	got, err := readOctetCounted(bufio.NewReader(accept(t, ln)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "<131>1 ") || !strings.HasSuffix(got, " over tls") {
+		t.Errorf("message = %q", got)
+	}
+}
+
+func TestNetworkSinkCloseTimeout(t *testing.T) {
+	// 送信先に接続できない場合も、Close は CloseTimeout 程度で終了する
+	sink, err := NewSyslogSink(SyslogConfig{NetworkConfig: NetworkConfig{Network: "tcp", Address: unusedAddress(t), CloseTimeout: 200 * time.Millisecond}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		sink.WriteEntry(testEntry(INFO, "entry"))
+	}
+	started := time.Now()
+	sink.Close()
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("Close() took %v, want about 200ms", elapsed)
+	}
+}
@@ -0,0 +1,332 @@
+package logger
+
+import (
+	"compress/gzip" // gzip圧縮用パッケージ
+	"errors"        // エラー操作用パッケージ
+	"fmt"           // フォーマットI/O用パッケージ
+	"io"            // I/Oインターフェース用パッケージ
+	"os"            // OS機能用パッケージ
+	"path/filepath" // ファイルパス操作用パッケージ
+	"sort"          // ソート用パッケージ
+	"strings"       // 文字列操作用パッケージ
+	"sync"          // 排他制御用パッケージ
+	"time"          // 時間操作用パッケージ
+)
+
+// バックアップファイル名の日時の形式（ログファイル名.20060102T150405.000）
+const backupTimeFormat = "20060102T150405.000"
+
+// 圧縮したバックアップファイルの拡張子
+const compressSuffix = ".gz"
+
+// RotateConfigは、RotatingWriter の設定です。
+type RotateConfig struct {
+	// ログファイルのパス
+	Filename string
+	// ローテーションするファイルサイズ（バイト。0の場合はサイズでローテーションしない）
+	MaxSize int64
+	// 日付が変わったときにローテーションするかどうか（ローカル時刻）
+	Daily bool
+	// バックアップを保持する期間（0の場合は期間で削除しない）
+	MaxAge time.Duration
+	// 保持するバックアップの最大数（0の場合は数で削除しない）
+	MaxBackups int
+	// バックアップをgzipで圧縮するかどうか
+	Compress bool
+}
+
+// RotatingWriterは、ファイルサイズと日付の変わり目でログファイルをローテーションする io.WriteCloser です。
+// ローテーションではログファイルをバックアップ（ログファイル名.日時）に名前を変更して新しいファイルを開き、
+// バックグラウンドで古いバックアップの圧縮と削除を行います。複数のゴルーチンから同時に書き込めます。
+type RotatingWriter struct {
+	// 設定
+	config RotateConfig
+	// ファイルとサイズを排他制御するミューテックス
+	mu sync.Mutex
+	// 現在のログファイル
+	file *os.File
+	// 現在のログファイルのサイズ
+	size int64
+	// 現在のログファイルを開いた日（日付の変わり目の判定に使用）
+	openedDay time.Time
+	// バックアップの圧縮と削除の要求（バッファ1で、処理中の要求がある場合はまとめる）
+	millRequests chan struct{}
+	// バックアップの圧縮と削除の終了を待つ
+	millDone sync.WaitGroup
+}
+
+// NewRotatingWriterは、ログファイルを開いて RotatingWriter を作成します。
+// ログファイルのディレクトリがない場合は作成します。
+// cfg: 設定
+func NewRotatingWriter(cfg RotateConfig) (*RotatingWriter, error) {
+	if cfg.Filename == "" {
+		return nil, errors.New("log file name is required")
+	}
+	w := &RotatingWriter{
+		config:       cfg,
+		millRequests: make(chan struct{}, 1),
+	}
+	if err := w.openExisting(); err != nil {
+		return nil, err
+	}
+	w.millDone.Add(1)
+	go w.mill()
+	// 起動前に残っていたバックアップも保持の設定に従って整理する
+	w.requestMill()
+	return w, nil
+}
+
+// Writeは、ログを書き込みます。書き込むとサイズを超える場合や日付が変わった場合は、先にローテーションします。
+// p: 書き込むバイト列
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotateは、サイズや日付によらずローテーションします（SIGHUPを受け取った場合など）。
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Closeは、ログファイルを閉じ、実行中のバックアップの圧縮と削除の終了を待ちます。
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	if w.file == nil {
+		w.mu.Unlock()
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	close(w.millRequests)
+	w.mu.Unlock()
+	w.millDone.Wait()
+	return err
+}
+
+// shouldRotateは、書き込む前にローテーションが必要かを判定します。
+// writeSize: 書き込むバイト数
+func (w *RotatingWriter) shouldRotate(writeSize int64) bool {
+	// 空のファイルは、1回の書き込みがサイズを超える場合もローテーションしない
+	if w.config.MaxSize > 0 && w.size > 0 && w.size+writeSize > w.config.MaxSize {
+		return true
+	}
+	return w.config.Daily && !startOfDay(time.Now()).Equal(w.openedDay)
+}
+
+// openExistingは、既存のログファイルに追記するために開きます。ファイルがない場合は作成します。
+func (w *RotatingWriter) openExisting() error {
+	if err := os.MkdirAll(filepath.Dir(w.config.Filename), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.config.Filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	// 日付の変わり目は、前回の書き込みの日付（ファイルの更新日時）から判定する
+	w.openedDay = startOfDay(info.ModTime())
+	if info.Size() == 0 {
+		w.openedDay = startOfDay(time.Now())
+	}
+	return nil
+}
+
+// rotateは、ログファイルをバックアップに名前を変更し、新しいログファイルを開きます。mu を取得した状態で呼び出します。
+// ファイルを閉じてから名前を変更するため、他のゴルーチンが古いファイルに書き込むことはありません。
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	backup := w.backupName()
+	if err := os.Rename(w.config.Filename, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// 名前を変更できない場合も書き込みを続けられるよう、元のファイルを開き直す
+		if openErr := w.openExisting(); openErr != nil {
+			w.file = nil
+			return openErr
+		}
+		return fmt.Errorf("failed to rotate log file: %v", err)
+	}
+	file, err := os.OpenFile(w.config.Filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		w.file = nil
+		return err
+	}
+	w.file = file
+	w.size = 0
+	w.openedDay = startOfDay(time.Now())
+	w.requestMill()
+	return nil
+}
+
+// backupNameは、既存のバックアップと重複しないバックアップファイルのパスを返します。
+// 同じミリ秒に複数回ローテーションした場合は、日時を1ミリ秒ずつ進めます。
+func (w *RotatingWriter) backupName() string {
+	rotatedAt := time.Now()
+	for {
+		name := w.config.Filename + "." + rotatedAt.Format(backupTimeFormat)
+		_, plainErr := os.Lstat(name)
+		_, compressedErr := os.Lstat(name + compressSuffix)
+		if errors.Is(plainErr, os.ErrNotExist) && errors.Is(compressedErr, os.ErrNotExist) {
+			return name
+		}
+		rotatedAt = rotatedAt.Add(time.Millisecond)
+	}
+}
+
+// requestMillは、バックアップの圧縮と削除を要求します。処理待ちの要求がある場合は何もしません。
+func (w *RotatingWriter) requestMill() {
+	select {
+	case w.millRequests <- struct{}{}:
+	default:
+	}
+}
+
+// millは、要求ごとにバックアップの圧縮と削除を行います。
+// 書き込みをブロックしないよう、ローテーションとは別のゴルーチンで実行します。
+func (w *RotatingWriter) mill() {
+	defer w.millDone.Done()
+	for range w.millRequests {
+		if err := w.millOnce(); err != nil {
+			// ロガー自身に出力すると書き込みが再帰するため、標準エラー出力に出力する
+			fmt.Fprintf(os.Stderr, "logger: failed to clean up log backups: %v\n", err)
+		}
+	}
+}
+
+// backupFileは、ローテーションしたバックアップファイルです。
+type backupFile struct {
+	// パス
+	path string
+	// ローテーションした日時
+	rotatedAt time.Time
+	// 圧縮済みかどうか
+	compressed bool
+}
+
+// millOnceは、保持の設定を超えたバックアップを削除し、残ったバックアップを圧縮します。
+func (w *RotatingWriter) millOnce() error {
+	backups, err := w.listBackups()
+	if err != nil {
+		return err
+	}
+	// 新しい順に並べ、数と期間を超えたものを削除する
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotatedAt.After(backups[j].rotatedAt) })
+	var errs []error
+	cutoff := time.Now().Add(-w.config.MaxAge)
+	kept := backups[:0]
+	for i, backup := range backups {
+		expired := w.config.MaxAge > 0 && backup.rotatedAt.Before(cutoff)
+		overflow := w.config.MaxBackups > 0 && i >= w.config.MaxBackups
+		if expired || overflow {
+			if err := os.Remove(backup.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		kept = append(kept, backup)
+	}
+	if w.config.Compress {
+		for _, backup := range kept {
+			if !backup.compressed {
+				if err := compressFile(backup.path); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// listBackupsは、ログファイルのディレクトリからバックアップファイルを取得します。
+func (w *RotatingWriter) listBackups() ([]backupFile, error) {
+	dir := filepath.Dir(w.config.Filename)
+	prefix := filepath.Base(w.config.Filename) + "."
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		timestamp := strings.TrimPrefix(name, prefix)
+		compressed := strings.HasSuffix(timestamp, compressSuffix)
+		timestamp = strings.TrimSuffix(timestamp, compressSuffix)
+		rotatedAt, err := time.ParseInLocation(backupTimeFormat, timestamp, time.Local)
+		if err != nil {
+			// 日時の形式でないファイル（圧縮中の一時ファイルなど）は対象外
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), rotatedAt: rotatedAt, compressed: compressed})
+	}
+	return backups, nil
+}
+
+// compressFileは、ファイルをgzipで圧縮して元のファイルを削除します。
+// 圧縮の途中で停止しても元のファイルが残るよう、一時ファイルに書き込んでから名前を変更します。
+// path: 圧縮するファイルのパス
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	temp := path + compressSuffix + ".tmp"
+	target, err := os.OpenFile(temp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(target)
+	if _, err := io.Copy(gz, source); err != nil {
+		gz.Close()
+		target.Close()
+		os.Remove(temp)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		target.Close()
+		os.Remove(temp)
+		return err
+	}
+	if err := target.Close(); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	if err := os.Rename(temp, path+compressSuffix); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return os.Remove(path)
+}
+
+// startOfDayは、ローカル時刻の日付の0時を返します。
+// t: 時刻
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Local().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"fmt"  // フォーマットI/O用パッケージ
+	"sync" // 排他制御用パッケージ
+	"time" // 時間操作用パッケージ
+)
+
+// 集計したログの件数が多い場合に、期間が終わった集計を削除する件数
+const maxSamplingCounters = 1024
+
+// SamplingConfigは、Sampler の設定です。
+type SamplingConfig struct {
+	// 集計する期間（既定値は1秒）
+	Interval time.Duration
+	// 期間ごとに出力する最初の件数（既定値は10件）
+	First int
+	// First 件を超えたログを何件に1件出力するか（0の場合は出力せず、件数のみ集計する）
+	Thereafter int
+}
+
+// Samplerは、同じログレベルとメッセージ（テンプレート）のログを期間ごとに集計し、
+// 最初の First 件を出力した後は Thereafter 件に1件のみ出力するサンプラーです。
+// 出力しなかったログは、次に出力するとき（または期間が終わった後）に "message repeated X times" のログとしてまとめて出力します。
+// 複数のロガーで共有でき、複数のゴルーチンから同時に使用できます。
+type Sampler struct {
+	// 設定
+	config SamplingConfig
+	// 集計を排他制御するミューテックス
+	mu sync.Mutex
+	// ログレベルとメッセージごとの集計
+	counters map[samplingKey]*samplingCounter
+	// 期間が終わった集計を最後に確認した時刻
+	lastSweep time.Time
+}
+
+// samplingKeyは、集計するログのキーです。
+type samplingKey struct {
+	// ログレベル
+	level LogLevel
+	// メッセージ
+	message string
+}
+
+// samplingCounterは、ログレベルとメッセージごとの集計です。
+type samplingCounter struct {
+	// 期間の開始時刻
+	start time.Time
+	// 期間中のログの件数
+	count int
+	// 出力せず、まだまとめて出力していない件数
+	suppressed int
+	// 最後に出力しなかったログのロガー（まとめたログの出力に使用する）
+	logger *Logger
+}
+
+// samplingSummaryは、出力しなかったログをまとめて出力するログです。
+type samplingSummary struct {
+	// 出力するロガー
+	logger *Logger
+	// ログレベルとメッセージ
+	key samplingKey
+	// 出力しなかった件数
+	count int
+}
+
+// NewSamplerは、設定から Sampler を作成します。
+// cfg: 設定
+func NewSampler(cfg SamplingConfig) *Sampler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.First <= 0 {
+		cfg.First = 10
+	}
+	return &Sampler{config: cfg, counters: make(map[samplingKey]*samplingCounter)}
+}
+
+// Configは、サンプラーの設定を返します。
+func (s *Sampler) Config() SamplingConfig {
+	return s.config
+}
+
+// sampleは、ログを出力するかを判定し、まとめて出力するログを返します。
+// 出力するログの前に、同じキーでそれまで出力しなかったログをまとめて出力します。
+// 期間ごとに、期間が終わった他のキーで出力しなかったログもまとめて出力します。
+// l: ログを出力するロガー
+// now: 出力時刻
+// level: ログレベル
+// message: ログメッセージ
+func (s *Sampler) sample(l *Logger, now time.Time, level LogLevel, message string) (bool, []samplingSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var summaries []samplingSummary
+	if now.Sub(s.lastSweep) >= s.config.Interval {
+		summaries = s.sweep(now, false)
+		s.lastSweep = now
+	}
+
+	key := samplingKey{level: level, message: message}
+	counter := s.counters[key]
+	if counter == nil {
+		counter = &samplingCounter{start: now}
+		s.counters[key] = counter
+	}
+	if now.Sub(counter.start) >= s.config.Interval {
+		counter.start = now
+		counter.count = 0
+	}
+	counter.count++
+	n := counter.count - s.config.First
+	if n > 0 && (s.config.Thereafter <= 0 || n%s.config.Thereafter != 0) {
+		counter.suppressed++
+		counter.logger = l
+		return false, summaries
+	}
+	if counter.suppressed > 0 {
+		summaries = append(summaries, samplingSummary{logger: l, key: key, count: counter.suppressed})
+		counter.suppressed = 0
+		counter.logger = nil
+	}
+	return true, summaries
+}
+
+// sweepは、期間が終わった集計（all が true の場合はすべての集計）の出力しなかったログをまとめて返します。
+// 集計の件数が多い場合は、期間が終わった集計を削除します。
+// now: 現在時刻
+// all: 期間によらずすべての集計をまとめるかどうか
+func (s *Sampler) sweep(now time.Time, all bool) []samplingSummary {
+	var summaries []samplingSummary
+	for key, counter := range s.counters {
+		expired := now.Sub(counter.start) >= s.config.Interval
+		if counter.suppressed > 0 && (expired || all) {
+			summaries = append(summaries, samplingSummary{logger: counter.logger, key: key, count: counter.suppressed})
+			counter.suppressed = 0
+			counter.logger = nil
+		}
+		if expired && len(s.counters) > maxSamplingCounters {
+			delete(s.counters, key)
+		}
+	}
+	return summaries
+}
+
+// Flushは、出力しなかったログをすべてまとめて出力します。停止時に呼び出します。
+func (s *Sampler) Flush() {
+	s.mu.Lock()
+	summaries := s.sweep(time.Now(), true)
+	s.mu.Unlock()
+	writeSummaries(time.Now(), summaries)
+}
+
+// writeSummariesは、出力しなかったログをまとめたログを、それぞれのロガーで出力します。
+// サンプラーのロックの外で呼び出します（シンクがログを出力しても再帰的にロックしないため）。
+// now: 出力時刻
+// summaries: まとめて出力するログ
+func writeSummaries(now time.Time, summaries []samplingSummary) {
+	for _, summary := range summaries {
+		l := summary.logger
+		config := l.core.config.Load()
+		// ロガーのフィールド（component など）を引き継ぎ、出力しなかった件数を追加する
+		fields := make([]Field, 0, len(l.fields)+1)
+		fields = append(fields, l.fields...)
+		fields = append(fields, Field{Key: "repeated", Value: summary.count})
+		message := fmt.Sprintf("message repeated %d times: %s", summary.count, summary.key.message)
+		l.emit(config, Entry{Time: now, Level: summary.key.level, Message: message, Fields: fields})
+	}
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"io"
+	"sync"
+	"testing"
+)
+
+// recordingSinkは、受け取ったログを記録するシンクです。
+type recordingSink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (s *recordingSink) WriteEntry(entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+}
+
+func TestSamplerSummary(t *testing.T) {
+	tests := []struct {
+		name       string
+		messages   []string
+		wantOutput []string
+	}{
+		{
+			name:       "同じメッセージを間引く",
+			messages:   []string{"failed", "failed", "failed"},
+			wantOutput: []string{"failed", "message repeated 2 times: failed"},
+		},
+		{
+			name:       "メッセージごとに集計する",
+			messages:   []string{"failed", "retrying", "failed", "retrying"},
+			wantOutput: []string{"failed", "retrying", "message repeated 1 times: failed", "message repeated 1 times: retrying"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingSink{}
+			sampler := NewSampler(SamplingConfig{First: 1})
+			l := New(Config{Outputs: map[LogLevel][]io.Writer{}, Sinks: []Sink{sink}}).
+				WithSampler(sampler).
+				With(FieldComponent, "monitor", "service", "web")
+			for _, message := range tt.messages {
+				l.Warn(message)
+			}
+			sampler.Flush()
+
+			if len(sink.entries) != len(tt.wantOutput) {
+				t.Fatalf("entries = %v, want %v", sink.entries, tt.wantOutput)
+			}
+			// Flush でまとめたログの順序は不定のため、メッセージの有無のみ確認する
+			for _, want := range tt.wantOutput {
+				if !containsMessage(sink.entries, want) {
+					t.Errorf("missing entry %q in %v", want, sink.entries)
+				}
+			}
+			for _, entry := range sink.entries {
+				// まとめたログにもロガーのフィールドを付与する
+				if got, _ := fieldValue(entry.Fields, "service"); got != "web" {
+					t.Errorf("entry %q service = %v, want web", entry.Message, got)
+				}
+				if got, _ := fieldValue(entry.Fields, FieldComponent); got != "monitor" {
+					t.Errorf("entry %q component = %v, want monitor", entry.Message, got)
+				}
+				if entry.Level != WARN {
+					t.Errorf("entry %q level = %v, want WARN", entry.Message, entry.Level)
+				}
+			}
+			if got, ok := fieldValue(sink.entries[len(sink.entries)-1].Fields, "repeated"); !ok || got == 0 {
+				t.Errorf("repeated = %v, want count of suppressed entries", got)
+			}
+		})
+	}
+}
+
+// containsMessageは、ログにメッセージが含まれるかを返します。
+func containsMessage(entries []Entry, message string) bool {
+	for _, entry := range entries {
+		if entry.Message == message {
+			return true
+		}
+	}
+	return false
+}
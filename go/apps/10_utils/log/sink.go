@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"fmt"    // フォーマットI/O用パッケージ
+	"slices" // スライス操作用パッケージ
+	"time"   // 時間操作用パッケージ
+)
+
+// Entryは、シンクに渡す1件のログです。
+type Entry struct {
+	// 出力時刻
+	Time time.Time
+	// ログレベル
+	Level LogLevel
+	// ログメッセージ
+	Message string
+	// ロガーに付与されたフィールド（シンクで変更しない）
+	Fields []Field
+}
+
+// Sinkは、io.Writer ではなくログの項目単位で出力する出力先です（データベースなど）。
+// WriteEntry はログの呼び出し元で実行されるため、時間のかかる処理は非同期に行う必要があります。
+type Sink interface {
+	// ログを出力する
+	WriteEntry(entry Entry)
+}
+
+// FieldStringは、キーのフィールドの値を文字列で返します。同じキーが複数ある場合は後のフィールドを使用し、ない場合は空文字を返します。
+// key: フィールドのキー
+func (e Entry) FieldString(key string) string {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return fmt.Sprint(e.Fields[i].Value)
+		}
+	}
+	return ""
+}
+
+// Textは、メッセージにフィールドを key=value 形式で付けた文字列を返します。
+// exclude: 含めないフィールドのキー（別のカラムに記録するフィールドなど）
+func (e Entry) Text(exclude ...string) string {
+	fields := make([]Field, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		if !slices.Contains(exclude, field.Key) {
+			fields = append(fields, field)
+		}
+	}
+	return e.Message + formatFields(fields)
+}
+
+// Stringは、ログレベルの文字列（DEBUG、INFO、WARN、ERROR）を返します。
+func (level LogLevel) String() string {
+	return getLevelString(level)
+}
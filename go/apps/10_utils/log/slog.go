@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"context"  // コンテキスト用パッケージ
+	"log/slog" // 構造化ログ用パッケージ
+	"time"     // 時間操作用パッケージ
+
+	"github.com/go-logr/logr"  // logr用パッケージ
+	"go.opentelemetry.io/otel" // OpenTelemetry用パッケージ
+)
+
+// SlogHandlerは、slog のログを Logger に出力する slog.Handler です。
+// slog や logr でログを出力するライブラリのログに、Logger のレベル、出力形式、出力先、シンクを適用します。
+type SlogHandler struct {
+	// 出力先のロガー
+	logger *Logger
+	// WithGroup で指定したグループ（属性のキーに "グループ." の形式で付ける）
+	groupPrefix string
+}
+
+// NewSlogHandlerは、ロガーに出力する SlogHandler を作成します。
+// l: 出力先のロガー
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+// Enabledは、ロガーがslogのレベルに対応するレベルのログを出力するかを判定します。
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(fromSlogLevel(level))
+}
+
+// Handleは、レコードの属性をフィールドとしてロガーに出力します。
+// コンテキストのリクエストIDやトレースIDも Ctx と同様に付与します。
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	keyValues := make([]interface{}, 0, record.NumAttrs()*2)
+	record.Attrs(func(attr slog.Attr) bool {
+		keyValues = appendAttr(keyValues, h.groupPrefix, attr)
+		return true
+	})
+	l := h.logger
+	if ctx != nil {
+		l = l.Ctx(ctx)
+	}
+	at := record.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	l.With(keyValues...).logAt(at, fromSlogLevel(record.Level), record.Message)
+	return nil
+}
+
+// WithAttrsは、属性をフィールドとして付与したハンドラーを返します。
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	keyValues := make([]interface{}, 0, len(attrs)*2)
+	for _, attr := range attrs {
+		keyValues = appendAttr(keyValues, h.groupPrefix, attr)
+	}
+	return &SlogHandler{logger: h.logger.With(keyValues...), groupPrefix: h.groupPrefix}
+}
+
+// WithGroupは、以降の属性のキーにグループ名を付けるハンドラーを返します。
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, groupPrefix: h.groupPrefix + name + "."}
+}
+
+// appendAttrは、属性をキーと値として追加します。グループの属性は "グループ.キー" の形式に展開します。
+// keyValues: 追加先
+// prefix: キーに付けるグループ
+// attr: 属性
+func appendAttr(keyValues []interface{}, prefix string, attr slog.Attr) []interface{} {
+	value := attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return keyValues
+	}
+	if value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		// キーのないグループは親のグループに展開する
+		if attr.Key != "" {
+			groupPrefix += attr.Key + "."
+		}
+		for _, member := range value.Group() {
+			keyValues = appendAttr(keyValues, groupPrefix, member)
+		}
+		return keyValues
+	}
+	return append(keyValues, prefix+attr.Key, value.Any())
+}
+
+// SlogSinkは、ロガーのログを slog.Handler に出力するシンクです。
+// slog のハンドラーを使用する出力先（サードパーティのハンドラーなど）にログを渡す場合に使用します。
+type SlogSink struct {
+	// 出力先のハンドラー
+	handler slog.Handler
+}
+
+// NewSlogSinkは、slog.Handler に出力する SlogSink を作成します。
+// handler: 出力先のハンドラー（SlogHandler を指定すると出力が循環するため指定しない）
+func NewSlogSink(handler slog.Handler) *SlogSink {
+	return &SlogSink{handler: handler}
+}
+
+// WriteEntryは、ログをslogのレコードとしてハンドラーに出力します。フィールドは属性になります。
+func (s *SlogSink) WriteEntry(entry Entry) {
+	level := toSlogLevel(entry.Level)
+	if !s.handler.Enabled(context.Background(), level) {
+		return
+	}
+	record := slog.NewRecord(entry.Time, level, entry.Message, 0)
+	for _, field := range entry.Fields {
+		record.AddAttrs(slog.Any(field.Key, field.Value))
+	}
+	s.handler.Handle(context.Background(), record)
+}
+
+// SetSlogDefaultは、slog の既定のロガー、標準の log パッケージ、OpenTelemetry の内部のログとエラーをロガーに出力するよう設定します。
+// 起動時に1回呼び出します。
+// l: 出力先のロガー
+func SetSlogDefault(l *Logger) {
+	handler := NewSlogHandler(l)
+	// slog.SetDefault は標準の log パッケージの出力もハンドラーに転送する
+	slog.SetDefault(slog.New(handler))
+	otel.SetLogger(logr.FromSlogHandler(handler))
+	// OpenTelemetryのエラー（スパンの送信の失敗など）は既定では log パッケージにINFOとして出力されるため、ERRORとして出力する
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		l.With("component", "otel", "error", err).Error("opentelemetry error")
+	}))
+}
+
+// fromSlogLevelは、slogのレベルをログレベルに変換します。
+// level: slogのレベル
+func fromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	default:
+		return ERROR
+	}
+}
+
+// toSlogLevelは、ログレベルをslogのレベルに変換します。
+// level: ログレベル
+func toSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case DEBUG:
+		return slog.LevelDebug
+	case INFO:
+		return slog.LevelInfo
+	case WARN:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
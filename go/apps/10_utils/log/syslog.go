@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"bytes"         // バイト列操作用パッケージ
+	"fmt"           // フォーマットI/O用パッケージ
+	"net"           // ネットワーク用パッケージ
+	"os"            // OS機能用パッケージ
+	"path/filepath" // ファイルパス操作用パッケージ
+	"strconv"       // 文字列変換用パッケージ
+	"strings"       // 文字列操作用パッケージ
+)
+
+// syslogのファシリティの既定値（local0）
+const defaultSyslogFacility = 16
+
+// UDPで送信するsyslogのメッセージの最大長の既定値（バイト。超えた分は切り詰める）
+const defaultSyslogMaxUDPSize = 8192
+
+// syslogのヘッダーの項目の最大長（RFC 5424）
+const (
+	// ホスト名
+	maxSyslogHostnameLength = 255
+	// アプリケーション名
+	maxSyslogAppNameLength = 48
+	// プロセスID
+	maxSyslogProcIDLength = 128
+	// 構造化データのパラメーター名
+	maxSyslogParamNameLength = 32
+)
+
+// SyslogConfigは、syslog（RFC 5424）で送信するシンクの設定です。
+type SyslogConfig struct {
+	// 接続の設定（TCPではオクテットカウンティング（RFC 6587）でフレーミングし、TLSConfig でTLS（RFC 5425）で接続する）
+	NetworkConfig
+	// ファシリティ（0〜23。アプリケーションが kern（0）を使用することはないため、0の場合は local0（16））
+	Facility int
+	// アプリケーション名（空の場合は実行ファイル名）
+	AppName string
+	// ホスト名（空の場合は os.Hostname）
+	Hostname string
+	// フィールドを出力する構造化データのID（例: fields@32473。空の場合はフィールドをメッセージに key=value 形式で付ける）
+	StructuredDataID string
+	// UDPで送信するメッセージの最大長（バイト。既定値は8192。超えた分は切り詰める）
+	MaxUDPSize int
+}
+
+// NewSyslogSinkは、ログをsyslog（RFC 5424）で送信する NetworkSink を作成します。
+// ログレベルは DEBUG を debug、INFO を informational、WARN を warning、ERROR を error の重大度で送信します。
+// 停止時は Close で送信を待つログを送信します。
+// cfg: 設定
+func NewSyslogSink(cfg SyslogConfig) (*NetworkSink, error) {
+	if cfg.Facility < 0 || cfg.Facility > 23 {
+		return nil, fmt.Errorf("invalid syslog facility %d", cfg.Facility)
+	}
+	if cfg.Facility == 0 {
+		cfg.Facility = defaultSyslogFacility
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = filepath.Base(os.Args[0])
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.MaxUDPSize <= 0 {
+		cfg.MaxUDPSize = defaultSyslogMaxUDPSize
+	}
+	// ヘッダーの項目は、空白を含まない印字可能なASCII文字に制限されている
+	hostname := syslogHeaderValue(cfg.Hostname, maxSyslogHostnameLength)
+	appName := syslogHeaderValue(cfg.AppName, maxSyslogAppNameLength)
+	procID := syslogHeaderValue(strconv.Itoa(os.Getpid()), maxSyslogProcIDLength)
+
+	encode := func(entry Entry) []byte {
+		var b bytes.Buffer
+		// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+		fmt.Fprintf(&b, "<%d>1 %s %s %s %s - ", cfg.Facility*8+syslogSeverity(entry.Level), entry.Time.Format("2006-01-02T15:04:05.000000Z07:00"), hostname, appName, procID)
+		if cfg.StructuredDataID == "" || len(entry.Fields) == 0 {
+			b.WriteString("- ")
+			b.WriteString(entry.Text())
+			return b.Bytes()
+		}
+		writeSyslogStructuredData(&b, cfg.StructuredDataID, entry.Fields)
+		b.WriteByte(' ')
+		b.WriteString(entry.Message)
+		return b.Bytes()
+	}
+	write := func(conn net.Conn, message []byte) error {
+		if cfg.Network == "udp" {
+			// UDPでは1つのデータグラムで送信する（RFC 5426）
+			if len(message) > cfg.MaxUDPSize {
+				message = message[:cfg.MaxUDPSize]
+			}
+			_, err := conn.Write(message)
+			return err
+		}
+		// TCPではメッセージの長さを前に付ける（RFC 6587 のオクテットカウンティング）
+		_, err := conn.Write(append([]byte(strconv.Itoa(len(message))+" "), message...))
+		return err
+	}
+	return newNetworkSink(cfg.NetworkConfig, encode, write)
+}
+
+// syslogSeverityは、ログレベルをsyslogの重大度に変換します。
+// level: ログレベル
+func syslogSeverity(level LogLevel) int {
+	switch level {
+	case DEBUG:
+		return 7
+	case INFO:
+		return 6
+	case WARN:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// syslogHeaderValueは、値をsyslogのヘッダーの項目（空白を含まない印字可能なASCII文字）にします。
+// 使用できない文字は "_" に置き換え、最大長で切り詰めます。空の場合は NILVALUE（-）を返します。
+// value: 値
+// maxLength: 最大長
+func syslogHeaderValue(value string, maxLength int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if len(value) > maxLength {
+		value = value[:maxLength]
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// writeSyslogStructuredDataは、フィールドを構造化データの要素（[ID name="value" ...]）として書き込みます。
+// パラメーター名に使用できない文字は "_" に置き換え、値の '"'、'\'、']' はエスケープします。
+// b: 書き込み先
+// id: 構造化データのID
+// fields: フィールド
+func writeSyslogStructuredData(b *bytes.Buffer, id string, fields []Field) {
+	b.WriteByte('[')
+	b.WriteString(syslogParamName(id))
+	for _, field := range fields {
+		b.WriteByte(' ')
+		b.WriteString(syslogParamName(field.Key))
+		b.WriteString(`="`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(fmt.Sprint(field.Value)))
+		b.WriteByte('"')
+	}
+	b.WriteByte(']')
+}
+
+// syslogParamNameは、構造化データのIDまたはパラメーター名に使用できない文字を "_" に置き換え、最大長で切り詰めます。
+// IDの "@"（企業番号の区切り）はそのまま使用します。
+// name: 名前
+func syslogParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > maxSyslogParamNameLength {
+		name = name[:maxSyslogParamNameLength]
+	}
+	if name == "" {
+		return "_"
+	}
+	return name
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestSyslogSinkTCP(t *testing.T) {
+	tests := []struct {
+		name             string
+		structuredDataID string
+		entry            Entry
+		want             string
+	}{
+		{
+			name:  "フィールドなし",
+			entry: testEntry(INFO, "started"),
+			want:  "<134>1 2024-05-01T12:00:00.123456Z host app %PID% - - started",
+		},
+		{
+			name:  "フィールドをメッセージに付ける",
+			entry: testEntry(WARN, "slow", Field{Key: "ms", Value: 120}),
+			want:  "<132>1 2024-05-01T12:00:00.123456Z host app %PID% - - slow ms=120",
+		},
+		{
+			name:             "フィールドを構造化データに出力する",
+			structuredDataID: "fields@32473",
+			entry:            testEntry(ERROR, "failed", Field{Key: "path", Value: `a"b]`}),
+			want:             `<131>1 2024-05-01T12:00:00.123456Z host app %PID% - [fields@32473 path="a\"b\]"] failed`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln := listenTCP(t)
+			sink, err := NewSyslogSink(SyslogConfig{
+				NetworkConfig:    NetworkConfig{Network: "tcp", Address: ln.Addr().String()},
+				Hostname:         "host",
+				AppName:          "app",
+				StructuredDataID: tt.structuredDataID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sink.Close()
+			// 2件続けて送信し、オクテットカウンティングで区切られていることを確認する
+			sink.WriteEntry(tt.entry)
+			sink.WriteEntry(tt.entry)
+
+			r := bufio.NewReader(accept(t, ln))
+			for i := 0; i < 2; i++ {
+				got, err := readOctetCounted(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := withPID(tt.want, got); got != want {
+					t.Errorf("message %d = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	pc := listenUDP(t)
+	sink, err := NewSyslogSink(SyslogConfig{
+		NetworkConfig: NetworkConfig{Network: "udp", Address: pc.LocalAddr().String()},
+		Hostname:      "host",
+		AppName:       "app",
+		MaxUDPSize:    64,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sink.WriteEntry(testEntry(DEBUG, "short"))
+	sink.WriteEntry(testEntry(DEBUG, strings.Repeat("x", 100)))
+
+	// UDPでは長さを付けずに1つのデータグラムで送信し、MaxUDPSize を超えた分は切り詰める
+	buf := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); !strings.HasPrefix(got, "<135>1 ") || !strings.HasSuffix(got, " - - short") {
+		t.Errorf("message = %q", got)
+	}
+	n, _, err = pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 64 {
+		t.Errorf("truncated message length = %d, want 64", n)
+	}
+}
+
+// withPIDは、期待するメッセージの %PID% を受信したメッセージのプロセスIDに置き換えます。
+func withPID(want, got string) string {
+	fields := strings.SplitN(got, " ", 6)
+	if len(fields) < 6 {
+		return want
+	}
+	return strings.Replace(want, "%PID%", fields[4], 1)
+}
@@ -0,0 +1,181 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+// HTTPレスポンスボディの読み取り上限
+const maxBodySize = 1 << 20
+
+// Resultは、プローブの結果を表す構造体
+type Result struct {
+	// 応答時間
+	Latency time.Duration
+	// TLS証明書の有効期限（TLSを使用しない場合はゼロ値）
+	CertExpiry time.Time
+	// HTTPステータスコード（HTTPの場合のみ）
+	StatusCode int
+	// 失敗した場合のエラー
+	Err error
+}
+
+// HTTPOptionsは、HTTPプローブのオプションを表す構造体
+type HTTPOptions struct {
+	// HTTPメソッド（空の場合はGET）
+	Method string
+	// 期待するステータスコード（0の場合は2xxを成功とする）
+	ExpectedStatus int
+	// レスポンスボディが一致すべき正規表現（空の場合は検証しない）
+	BodyPattern string
+	// 証明書の検証を省略するかどうか
+	InsecureSkipVerify bool
+}
+
+// HTTPのステータスコードとボディを検証する関数
+// ctx: コンテキスト（タイムアウトを含む）
+// url: 対象のURL
+// options: HTTPプローブのオプション
+func HTTP(ctx context.Context, url string, options HTTPOptions) Result {
+	method := options.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return Result{Err: err}
+	}
+	// 接続を再利用しないクライアントで毎回ハンドシェイクから計測する
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: options.InsecureSkipVerify},
+		DisableKeepAlives: true,
+	}}
+
+	start := time.Now()
+	response, err := client.Do(request)
+	if err != nil {
+		return Result{Latency: time.Since(start), Err: err}
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxBodySize))
+	result := Result{Latency: time.Since(start), StatusCode: response.StatusCode}
+	if response.TLS != nil {
+		result.CertExpiry = leafExpiry(response.TLS.PeerCertificates)
+	}
+	if err != nil {
+		result.Err = fmt.Errorf("failed to read body: %v", err)
+		return result
+	}
+
+	// ステータスコードを検証
+	if options.ExpectedStatus != 0 && response.StatusCode != options.ExpectedStatus {
+		result.Err = fmt.Errorf("unexpected status %d (expected %d)", response.StatusCode, options.ExpectedStatus)
+		return result
+	}
+	if options.ExpectedStatus == 0 && (response.StatusCode < 200 || response.StatusCode >= 300) {
+		result.Err = fmt.Errorf("unexpected status %d", response.StatusCode)
+		return result
+	}
+	// ボディを検証
+	if options.BodyPattern != "" {
+		pattern, err := regexp.Compile(options.BodyPattern)
+		if err != nil {
+			result.Err = fmt.Errorf("invalid body pattern: %v", err)
+			return result
+		}
+		if !pattern.Match(body) {
+			result.Err = fmt.Errorf("body does not match %q", options.BodyPattern)
+		}
+	}
+	return result
+}
+
+// TCP接続を検証する関数
+// ctx: コンテキスト（タイムアウトを含む）
+// address: 接続先アドレス（host:port）
+func TCP(ctx context.Context, address string) Result {
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	latency := time.Since(start)
+	if err != nil {
+		return Result{Latency: latency, Err: err}
+	}
+	conn.Close()
+	return Result{Latency: latency}
+}
+
+// grpc.health.v1プロトコルでサービスの状態を検証する関数
+// ctx: コンテキスト（タイムアウトを含む）
+// address: 接続先アドレス（host:port）
+// service: 検証するサービス名（空の場合はサーバー全体）
+// useTLS: TLSを使用するかどうか
+// insecureSkipVerify: 証明書の検証を省略するかどうか
+func GRPCHealth(ctx context.Context, address string, service string, useTLS bool, insecureSkipVerify bool) Result {
+	transport := insecure.NewCredentials()
+	if useTLS {
+		transport = credentials.NewTLS(&tls.Config{InsecureSkipVerify: insecureSkipVerify})
+	}
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return Result{Err: err}
+	}
+	defer conn.Close()
+
+	// 接続先の情報から証明書を取得するためにピア情報を受け取る
+	var p peer.Peer
+	start := time.Now()
+	response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service}, grpc.Peer(&p))
+	result := Result{Latency: time.Since(start)}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+		result.CertExpiry = leafExpiry(info.State.PeerCertificates)
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if response.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		result.Err = fmt.Errorf("health status is %s", response.GetStatus())
+	}
+	return result
+}
+
+// QUICのハンドシェイクを検証する関数
+// ctx: コンテキスト（タイムアウトを含む）
+// address: 接続先アドレス（host:port）
+// nextProtos: ALPNで提示するプロトコル
+// insecureSkipVerify: 証明書の検証を省略するかどうか
+func QUICHandshake(ctx context.Context, address string, nextProtos []string, insecureSkipVerify bool) Result {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify, NextProtos: nextProtos}
+	start := time.Now()
+	conn, err := quic.DialAddr(ctx, address, tlsConfig, &quic.Config{})
+	latency := time.Since(start)
+	if err != nil {
+		return Result{Latency: latency, Err: err}
+	}
+	defer conn.CloseWithError(0, "")
+	return Result{Latency: latency, CertExpiry: leafExpiry(conn.ConnectionState().TLS.PeerCertificates)}
+}
+
+// サーバー証明書の有効期限を取得する関数
+// certificates: 接続先から受け取った証明書チェーン
+func leafExpiry(certificates []*x509.Certificate) time.Time {
+	if len(certificates) == 0 {
+		return time.Time{}
+	}
+	return certificates[0].NotAfter
+}
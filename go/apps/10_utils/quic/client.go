@@ -2,71 +2,114 @@ package quic
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/apps/10_utils/tracing"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// アプリケーションのALPNプロトコル名（QUICではALPNの指定が必須）
+const NextProto = "no-code-app"
+
+// レスポンスの最大サイズ
+const maxResponseSize = 16 << 20
+
+// リクエストのスパンを作成するトレーサーの名前
+const tracerName = "no-code-app/quic"
+
+// 接続の失敗などのログの既定のサンプリング（1分ごとに同じログを5件まで出力し、以降は20件に1件出力する）
+var defaultLogSampling = logger.SamplingConfig{Interval: time.Minute, First: 5, Thereafter: 20}
+
 type Client struct {
+	// セッションの参照と再接続、再試行の設定を排他制御するミューテックス
+	mu sync.Mutex
 	// QUICセッション
 	session quic.Connection
 	// 接続先アドレス
 	address string
+	// TLS設定（エージェントの証明書の検証方法を含む）
+	tlsConfig *tls.Config
 	// 再試行回数
 	retryAttempts int
 	// 再試行間隔
 	retryDelay time.Duration
+	// エージェントの停止中に繰り返すログを間引くサンプラー
+	sampler *logger.Sampler
 }
 
 // 新しいクライアントを作成する関数
 // address: 接続先アドレス
+// tlsConfig: TLS設定（NextProtosが空の場合はNextProtoを使用）
 // retryAttempts: 接続の再試行回数
 // retryDelay: 再試行間隔
-func NewClient(address string, retryAttempts int, retryDelay time.Duration) (*Client, error) {
+func NewClient(address string, tlsConfig *tls.Config, retryAttempts int, retryDelay time.Duration) (*Client, error) {
+	config := tlsConfig.Clone()
+	if len(config.NextProtos) == 0 {
+		config.NextProtos = []string{NextProto}
+	}
 	// クライアント構造体を初期化
 	client := &Client{
 		address:       address,
+		tlsConfig:     config,
 		retryAttempts: retryAttempts,
 		retryDelay:    retryDelay,
+		sampler:       logger.NewSampler(defaultLogSampling),
 	}
 	// 接続を試みる
-	err := client.connect()
+	err := client.connect(context.Background())
 	if err != nil {
 		return nil, err
 	}
 	return client, nil
 }
 
-// 接続を確立する関数
-func (c *Client) connect() error {
+// 接続を確立する関数（呼び出し元が c.mu をロックする）
+// ctx: コンテキスト（キャンセルすると接続の試行を中断する）
+func (c *Client) connect(ctx context.Context) error {
 	var session quic.Connection
 	var err error
 
 	// 再試行回数に基づいて接続を試みる
 	for i := 0; i < c.retryAttempts; i++ {
 		// タイムアウト付きのコンテキストを作成
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+		dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 
-		// TLS設定を作成
-		tlsConfig := &tls.Config{InsecureSkipVerify: true}
 		// QUIC設定を作成
 		quicConfig := &quic.Config{}
 		// 指定されたアドレスに接続を試みる
-		session, err = quic.DialAddr(ctx, c.address, tlsConfig, quicConfig)
+		session, err = quic.DialAddr(dialCtx, c.address, c.tlsConfig, quicConfig)
+		cancel()
 		if err == nil {
 			// 接続が成功した場合
-			log.Printf("Successfully connected to %s", c.address)
+			c.logger(ctx).Info("successfully connected")
 			c.session = session
 			return nil
 		}
 		// 接続が失敗した場合
-		log.Printf("Failed to dial address %s: %v (attempt %d/%d)", c.address, err, i+1, c.retryAttempts)
-		// 再試行前に指定された間隔だけ待機
-		time.Sleep(c.retryDelay)
+		c.logger(ctx).With("attempt", i+1, "max_attempts", c.retryAttempts, "error", err).Warn("failed to dial")
+		// 再試行前に指定された間隔だけ待機（コンテキストがキャンセルされた場合は中断）
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(c.retryDelay):
+		}
 	}
 
 	return err
@@ -74,28 +117,31 @@ func (c *Client) connect() error {
 
 // セッションを閉じる関数
 func (c *Client) Close() {
+	c.mu.Lock()
+	session := c.session
+	c.mu.Unlock()
 	// セッションをエラーなしで閉じる
-	if err := c.session.CloseWithError(0, ""); err != nil {
+	if err := session.CloseWithError(0, ""); err != nil {
 		log.Fatalf("Failed to close session: %v", err)
 	}
-	log.Println("Session closed successfully")
+	c.logger(context.Background()).Info("session closed successfully")
+	// 間引いたログの件数を出力
+	c.sampler.Flush()
 }
 
 // メッセージを送信する関数
 // ctx: コンテキスト
 // message: 送信するメッセージ
 func (c *Client) SendMessage(ctx context.Context, message []byte) ([]byte, error) {
-	// 接続されていない場合は再接続を試みる
-	if !c.IsConnected() {
-		if err := c.connect(); err != nil {
-			return nil, fmt.Errorf("not connected and failed to reconnect: %v", err)
-		}
+	session, err := c.connectedSession(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// ストリームを同期的に開く
-	stream, err := c.session.OpenStreamSync(ctx)
+	stream, err := session.OpenStreamSync(ctx)
 	if err != nil {
-		log.Printf("Failed to open stream: %v", err)
+		c.logger(ctx).With("error", err).Warn("failed to open stream")
 		return nil, err
 	}
 	defer stream.Close()
@@ -103,7 +149,7 @@ func (c *Client) SendMessage(ctx context.Context, message []byte) ([]byte, error
 	// メッセージを送信
 	_, err = stream.Write(message)
 	if err != nil {
-		log.Printf("Failed to send message: %v", err)
+		c.logger(ctx).With("error", err).Warn("failed to send message")
 		return nil, err
 	}
 
@@ -111,13 +157,161 @@ func (c *Client) SendMessage(ctx context.Context, message []byte) ([]byte, error
 	response := make([]byte, 1024)
 	n, err := stream.Read(response)
 	if err != nil {
-		log.Printf("Failed to read response: %v", err)
+		c.logger(ctx).With("error", err).Warn("failed to read response")
 		return nil, err
 	}
 
 	return response[:n], nil
 }
 
+// リクエストを送信し、サーバーがストリームを閉じるまでレスポンスを読み取る関数
+// SendMessageと異なり、1024バイトを超えるレスポンスも受け取れる
+// ctx: コンテキスト
+// request: 送信するリクエスト
+func (c *Client) SendRequest(ctx context.Context, request []byte) (response []byte, err error) {
+	// レスポンスの受信までをスパンとする
+	ctx, span := c.startSpan(ctx, "QUIC request")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	stream, err := c.openRequestStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CancelRead(0)
+
+	// レスポンスを読み取る
+	response, err = io.ReadAll(io.LimitReader(stream, maxResponseSize))
+	if err != nil {
+		c.logger(ctx).With("error", err).Warn("failed to read response")
+		return nil, err
+	}
+	return response, nil
+}
+
+// リクエストを送信し、レスポンスを読み取るためのストリームを返す関数
+// サーバーからの継続的なレスポンスを受け取る場合に使用する
+// ctx: コンテキスト（キャンセルするとストリームも中断される）
+// request: 送信するリクエスト
+func (c *Client) OpenRequestStream(ctx context.Context, request []byte) (stream quic.Stream, err error) {
+	// 継続的なレスポンスは長時間になるため、リクエストの送信までをスパンとする
+	ctx, span := c.startSpan(ctx, "QUIC open stream")
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.openRequestStream(ctx, request)
+}
+
+// リクエストを送信し、レスポンスを読み取るためのストリームを返す関数（スパンを作成しない）
+// ctx: コンテキスト（キャンセルするとストリームも中断される）
+// request: 送信するリクエスト
+func (c *Client) openRequestStream(ctx context.Context, request []byte) (quic.Stream, error) {
+	session, err := c.connectedSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// ストリームを同期的に開く
+	stream, err := session.OpenStreamSync(ctx)
+	if err != nil {
+		c.logger(ctx).With("error", err).Warn("failed to open stream")
+		return nil, err
+	}
+	// リクエストを送信し、送信側を閉じてリクエストの終端を伝える
+	if _, err := stream.Write(request); err != nil {
+		c.logger(ctx).With("error", err).Warn("failed to send message")
+		stream.CancelRead(0)
+		return nil, err
+	}
+	if err := stream.Close(); err != nil {
+		stream.CancelRead(0)
+		return nil, err
+	}
+	// コンテキストがキャンセルされたら受信を中断
+	context.AfterFunc(ctx, func() { stream.CancelRead(0) })
+	return stream, nil
+}
+
+// エージェントの証明書を検証するTLS設定を作成する関数
+// fingerprint を指定した場合は、証明書のSHA-256フィンガープリントが一致することを確認する（自己署名証明書の固定）
+// 指定しない場合は、caFile のCA証明書（空の場合はシステムの証明書）で証明書チェーンとホスト名を検証する
+// caFile: CA証明書ファイルのパス
+// serverName: 証明書のホスト名（空の場合は接続先アドレスのホスト名）
+// fingerprint: 証明書のSHA-256フィンガープリント（16進数、コロン区切りも可）
+func ClientTLSConfig(caFile, serverName, fingerprint string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: serverName, NextProtos: []string{NextProto}}
+	if fingerprint != "" {
+		want, err := hex.DecodeString(strings.ReplaceAll(fingerprint, ":", ""))
+		if err != nil || len(want) != sha256.Size {
+			return nil, fmt.Errorf("invalid certificate fingerprint %q", fingerprint)
+		}
+		// 証明書チェーンの代わりにフィンガープリントで検証する
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no certificate presented")
+			}
+			got := sha256.Sum256(rawCerts[0])
+			if subtle.ConstantTimeCompare(got[:], want) != 1 {
+				return fmt.Errorf("certificate fingerprint mismatch: got %s", CertificateFingerprint(rawCerts[0]))
+			}
+			return nil
+		}
+		return config, nil
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %v", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		config.RootCAs = roots
+	}
+	return config, nil
+}
+
+// 証明書のSHA-256フィンガープリントを16進数で返す関数
+// der: DER形式の証明書
+func CertificateFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// 接続中のセッションを返す関数
+// 接続されていない場合は再接続を試みる（複数のゴルーチンから同時に呼ばれても1回だけ再接続する）
+// ctx: コンテキスト
+func (c *Client) connectedSession(ctx context.Context) (quic.Connection, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.isConnected() {
+		if err := c.connect(ctx); err != nil {
+			return nil, fmt.Errorf("not connected and failed to reconnect: %v", err)
+		}
+	}
+	return c.session, nil
+}
+
+// リクエストのスパンを開始する関数
+// リクエストの内容は呼び出し元が作成するため、トレースコンテキストの伝搬と種類（Client）のスパンは呼び出し元で作成し、
+// このスパンはストリームの送受信の区間として記録する
+// ctx: コンテキスト
+// name: スパン名
+func (c *Client) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	options := []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(semconv.NetworkTransportKey.String("quic"))}
+	if host, port, err := net.SplitHostPort(c.address); err == nil {
+		portNumber, _ := strconv.Atoi(port)
+		options = append(options, trace.WithAttributes(semconv.ServerAddress(host), semconv.ServerPort(portNumber)))
+	}
+	return tracing.Tracer(tracerName).Start(ctx, name, options...)
+}
+
+// リクエストID、トレースID、接続先アドレスを付与したロガーを返す関数
+// 同じメッセージのログはサンプラーで間引く（log.sampling.components.quic の設定がある場合はそちらを使用する）
+// ctx: コンテキスト
+func (c *Client) logger(ctx context.Context) *logger.Logger {
+	return logger.Ctx(ctx).WithSampler(c.sampler).With("component", "quic", "address", c.address)
+}
+
 // メッセージを非同期に送信する関数
 // ctx: コンテキスト
 // message: 送信するメッセージ
@@ -140,14 +334,17 @@ func (c *Client) SendMessageAsync(ctx context.Context, message []byte, responseC
 // ctx: コンテキスト
 func (c *Client) ReceiveMessage(ctx context.Context) ([]byte, error) {
 	// 接続されていない場合はエラーを返す
-	if !c.IsConnected() {
+	c.mu.Lock()
+	connected, session := c.isConnected(), c.session
+	c.mu.Unlock()
+	if !connected {
 		return nil, fmt.Errorf("not connected")
 	}
 
 	// ストリームを受け入れる
-	stream, err := c.session.AcceptStream(ctx)
+	stream, err := session.AcceptStream(ctx)
 	if err != nil {
-		log.Printf("Failed to accept stream: %v", err)
+		c.logger(ctx).With("error", err).Warn("failed to accept stream")
 		return nil, err
 	}
 	defer stream.Close()
@@ -156,7 +353,7 @@ func (c *Client) ReceiveMessage(ctx context.Context) ([]byte, error) {
 	response := make([]byte, 1024)
 	n, err := stream.Read(response)
 	if err != nil {
-		log.Printf("Failed to read message: %v", err)
+		c.logger(ctx).With("error", err).Warn("failed to read message")
 		return nil, err
 	}
 
@@ -165,30 +362,44 @@ func (c *Client) ReceiveMessage(ctx context.Context) ([]byte, error) {
 
 // 接続されているかを確認する関数
 func (c *Client) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.isConnected()
+}
+
+// 接続されているかを確認する関数（呼び出し元が c.mu をロックする）
+func (c *Client) isConnected() bool {
 	return c.session != nil && c.session.Context().Err() == nil
 }
 
 // 接続状態を取得する関数
 func (c *Client) GetConnectionState() quic.ConnectionState {
-	return c.session.ConnectionState()
+	c.mu.Lock()
+	session := c.session
+	c.mu.Unlock()
+	return session.ConnectionState()
 }
 
 // 再試行回数を設定する関数
 // attempts: 新しい再試行回数
 func (c *Client) SetRetryAttempts(attempts int) {
+	c.mu.Lock()
 	c.retryAttempts = attempts
-	log.Printf("Retry attempts set to %d", attempts)
+	c.mu.Unlock()
+	c.logger(context.Background()).With("attempts", attempts).Info("retry attempts set")
 }
 
 // 再試行間隔を設定する関数
 // delay: 新しい再試行間隔
 func (c *Client) SetRetryDelay(delay time.Duration) {
+	c.mu.Lock()
 	c.retryDelay = delay
-	log.Printf("Retry delay set to %v", delay)
+	c.mu.Unlock()
+	c.logger(context.Background()).With("delay", delay).Info("retry delay set")
 }
 
 // 接続状態をログに出力する関数
 func (c *Client) LogConnectionState() {
 	state := c.GetConnectionState()
-	log.Printf("Connection state: %+v", state)
+	c.logger(context.Background()).With("state", fmt.Sprintf("%+v", state)).Info("connection state")
 }
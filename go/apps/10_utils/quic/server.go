@@ -0,0 +1,134 @@
+package quic
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	logger "no-code-app/apps/10_utils/log"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// リクエストの最大サイズ
+const maxRequestSize = 1 << 20
+
+// Handlerは、ストリームで受信したリクエストを処理する関数の型
+// ctx: ストリームのコンテキスト（クライアントが切断するとキャンセルされる）
+// request: 受信したリクエスト
+// w: レスポンスの書き込み先
+type Handler func(ctx context.Context, request []byte, w io.Writer) error
+
+type Server struct {
+	// 待ち受けアドレス
+	address string
+	// TLS設定
+	tlsConfig *tls.Config
+	// リクエストを処理する関数
+	handler Handler
+}
+
+// 新しいサーバーを作成する関数
+// address: 待ち受けアドレス
+// tlsConfig: TLS設定（NextProtosが空の場合はNextProtoを使用）
+// handler: リクエストを処理する関数
+func NewServer(address string, tlsConfig *tls.Config, handler Handler) *Server {
+	config := tlsConfig.Clone()
+	if len(config.NextProtos) == 0 {
+		config.NextProtos = []string{NextProto}
+	}
+	return &Server{address: address, tlsConfig: config, handler: handler}
+}
+
+// コンテキストがキャンセルされるまで接続を受け付ける関数
+// ctx: コンテキスト
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	listener, err := quic.ListenAddr(s.address, s.tlsConfig, &quic.Config{})
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	s.logger(ctx).Info("QUIC server listening")
+
+	for {
+		conn, err := listener.Accept(ctx)
+		if err != nil {
+			// キャンセルによる終了は正常終了とする
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.serveConnection(ctx, conn)
+	}
+}
+
+// 接続で開かれたストリームを処理する関数
+// ctx: コンテキスト
+// conn: QUIC接続
+func (s *Server) serveConnection(ctx context.Context, conn quic.Connection) {
+	for {
+		stream, err := conn.AcceptStream(ctx)
+		if err != nil {
+			return
+		}
+		go s.serveStream(stream, conn.RemoteAddr().String())
+	}
+}
+
+// ストリームのリクエストを読み取り、ハンドラーを呼び出す関数
+// クライアントが送信側を閉じるまでをリクエストとして読み取る
+// stream: QUICストリーム
+// remoteAddr: クライアントのアドレス
+func (s *Server) serveStream(stream quic.Stream, remoteAddr string) {
+	defer stream.Close()
+	request, err := io.ReadAll(io.LimitReader(stream, maxRequestSize))
+	if err != nil {
+		s.logger(stream.Context()).With("remote_addr", remoteAddr, "error", err).Warn("failed to read request")
+		stream.CancelRead(0)
+		return
+	}
+	if err := s.handler(stream.Context(), request, stream); err != nil && !errors.Is(err, context.Canceled) {
+		s.logger(stream.Context()).With("remote_addr", remoteAddr, "error", err).Warn("failed to handle request")
+	}
+}
+
+// 待ち受けアドレスを付与したロガーを返す関数
+// 同じメッセージのログは log.sampling.components.quic の設定がある場合に間引かれる
+// ctx: コンテキスト
+func (s *Server) logger(ctx context.Context) *logger.Logger {
+	return logger.Ctx(ctx).With("component", "quic", "address", s.address)
+}
+
+// 証明書ファイルが用意されていない場合に使用する自己署名証明書のTLS設定を作成する関数
+func GenerateSelfSignedTLSConfig() (*tls.Config, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "no-code-app"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	certificate := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return &tls.Config{Certificates: []tls.Certificate{certificate}, NextProtos: []string{NextProto}}, nil
+}
@@ -0,0 +1,81 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// リクエストIDを受け渡すHTTPヘッダー名
+const Header = "X-Request-ID"
+
+// リクエストIDを受け渡すgRPCメタデータのキー
+const MetadataKey = "x-request-id"
+
+// 受け付けるリクエストIDの最大長
+const maxLength = 128
+
+// リクエストIDをコンテキストに格納するキーの型
+type contextKey struct{}
+
+// Newは、新しいリクエストID（32桁の16進数）を生成します。
+func New() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// NewContextは、リクエストIDを格納したコンテキストを返します。
+// ctx: 親のコンテキスト
+// id: リクエストID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContextは、コンテキストに格納されたリクエストIDを返します。格納されていない場合は空文字を返します。
+// ctx: コンテキスト
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Validは、外部から受け取ったリクエストIDをそのまま使用できるかを判定します。
+// ログの改ざんを防ぐため、英数字と - _ . : のみからなる128文字以下の値のみ受け付けます。
+// id: リクエストID
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Transportは、コンテキストのリクエストIDを送信するリクエストのヘッダーに付与するRoundTripperです。
+type Transport struct {
+	// 実際に送信するRoundTripper（nilの場合はhttp.DefaultTransport）
+	Base http.RoundTripper
+}
+
+// RoundTripは、リクエストIDのヘッダーを付与してリクエストを送信します。
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	id := FromContext(req.Context())
+	if id == "" || req.Header.Get(Header) != "" {
+		return base.RoundTrip(req)
+	}
+	// RoundTripperは元のリクエストを変更してはならないため複製する
+	clone := req.Clone(req.Context())
+	clone.Header.Set(Header, id)
+	return base.RoundTrip(clone)
+}
@@ -0,0 +1,139 @@
+package sysinfo
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 最初の計測で使用率を算出するためのサンプリング間隔
+const initialSampleInterval = 500 * time.Millisecond
+
+// Processは、プロセスのリソース使用状況を表す構造体
+type Process struct {
+	// プロセスID
+	PID int
+	// プロセス名
+	Name string
+	// コマンドライン
+	Command string
+	// CPU使用率（%、1コアを100%とする）
+	CPUPercent float64
+	// 常駐メモリサイズ（バイト）
+	RSS uint64
+	// 開いているファイルディスクリプタの数（取得できない場合は-1）
+	OpenFDs int
+}
+
+// Snapshotは、ホストとプロセスのリソース使用状況を表す構造体
+type Snapshot struct {
+	// ホスト名
+	Hostname string
+	// CPU使用率（%）
+	CPUUsage float64
+	// メモリ使用率（%）
+	MemoryUsage float64
+	// ディスク使用率（%）
+	DiskUsage float64
+	// プロセスのリスト（CPU使用率の降順）
+	Processes []Process
+	// 計測時刻
+	Timestamp time.Time
+}
+
+// cpuSampleは、CPU時間の累積値を表す構造体
+type cpuSample struct {
+	// ホスト全体のビジー時間（クロックティック）
+	busy uint64
+	// ホスト全体の合計時間（クロックティック）
+	total uint64
+	// プロセスごとのCPU時間（クロックティック）
+	processes map[int]uint64
+	// 計測時刻
+	time time.Time
+}
+
+// Collectorは、前回の計測との差分からCPU使用率を算出する構造体
+type Collector struct {
+	// 排他制御用のミューテックス
+	mu sync.Mutex
+	// ディスク使用率を計測するパス
+	diskPath string
+	// 前回の計測結果
+	previous *cpuSample
+}
+
+// 新しいCollectorを作成する関数
+// diskPath: ディスク使用率を計測するパス
+func NewCollector(diskPath string) *Collector {
+	return &Collector{diskPath: diskPath}
+}
+
+// ホストとプロセスのリソース使用状況を計測する関数
+func (c *Collector) Collect() (Snapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 初回は短い間隔で2回計測して差分を求める
+	if c.previous == nil {
+		sample, err := readCPUSample()
+		if err != nil {
+			return Snapshot{}, err
+		}
+		c.previous = &sample
+		time.Sleep(initialSampleInterval)
+	}
+	current, err := readCPUSample()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	previous := c.previous
+	c.previous = &current
+
+	snapshot := Snapshot{Hostname: hostname(), Timestamp: current.time}
+	// ホスト全体のCPU使用率
+	if total := current.total - previous.total; total > 0 {
+		snapshot.CPUUsage = float64(current.busy-previous.busy) / float64(total) * 100
+	}
+	if snapshot.MemoryUsage, err = readMemoryUsage(); err != nil {
+		return Snapshot{}, err
+	}
+	if snapshot.DiskUsage, err = readDiskUsage(c.diskPath); err != nil {
+		return Snapshot{}, err
+	}
+
+	// プロセスごとのCPU使用率
+	elapsed := current.time.Sub(previous.time).Seconds()
+	processes, err := readProcesses()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for i := range processes {
+		before, ok := previous.processes[processes[i].PID]
+		after := current.processes[processes[i].PID]
+		if ok && elapsed > 0 && after >= before {
+			processes[i].CPUPercent = float64(after-before) / clockTicks / elapsed * 100
+		}
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		if processes[i].CPUPercent != processes[j].CPUPercent {
+			return processes[i].CPUPercent > processes[j].CPUPercent
+		}
+		return processes[i].RSS > processes[j].RSS
+	})
+	snapshot.Processes = processes
+	return snapshot, nil
+}
+
+// プロセスが指定された名前に一致するかを判定する関数
+// プロセス名、またはコマンドラインの実行ファイル名で比較する
+// name: プロセス名
+func (p Process) Matches(name string) bool {
+	if p.Name == name {
+		return true
+	}
+	fields := strings.Fields(p.Command)
+	return len(fields) > 0 && filepath.Base(fields[0]) == name
+}
@@ -0,0 +1,172 @@
+package sysinfo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 1秒あたりのクロックティック数（Linuxの既定値）
+const clockTicks = 100
+
+// ホスト名を取得する関数
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+// /proc/statとプロセスごとのCPU時間を読み取る関数
+func readCPUSample() (cpuSample, error) {
+	sample := cpuSample{processes: make(map[int]uint64), time: time.Now()}
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return sample, err
+	}
+	// 先頭行の「cpu user nice system idle iowait irq softirq steal ...」を集計
+	line := strings.SplitN(string(data), "\n", 2)[0]
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return sample, fmt.Errorf("unexpected /proc/stat format")
+	}
+	for i, field := range fields[1:] {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return sample, err
+		}
+		sample.total += value
+		// idleとiowait以外をビジー時間とする
+		if i != 3 && i != 4 {
+			sample.busy += value
+		}
+	}
+
+	for _, pid := range listPIDs() {
+		if ticks, err := readProcessCPUTime(pid); err == nil {
+			sample.processes[pid] = ticks
+		}
+	}
+	return sample, nil
+}
+
+// /proc/meminfoからメモリ使用率を読み取る関数
+func readMemoryUsage() (float64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 {
+			value, _ := strconv.ParseUint(fields[1], 10, 64)
+			values[strings.TrimSuffix(fields[0], ":")] = value
+		}
+	}
+	total := values["MemTotal"]
+	if total == 0 {
+		return 0, fmt.Errorf("MemTotal not found in /proc/meminfo")
+	}
+	return float64(total-values["MemAvailable"]) / float64(total) * 100, nil
+}
+
+// ファイルシステムの使用率を読み取る関数
+// path: 計測するパス
+func readDiskUsage(path string) (float64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	total := stat.Blocks * uint64(stat.Bsize)
+	free := stat.Bfree * uint64(stat.Bsize)
+	if total == 0 {
+		return 0, nil
+	}
+	return float64(total-free) / float64(total) * 100, nil
+}
+
+// 全てのプロセスの情報を読み取る関数
+// 読み取り中に終了したプロセスは除外する
+func readProcesses() ([]Process, error) {
+	pageSize := uint64(os.Getpagesize())
+	var processes []Process
+	for _, pid := range listPIDs() {
+		dir := filepath.Join("/proc", strconv.Itoa(pid))
+		comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+		if err != nil {
+			continue
+		}
+		process := Process{PID: pid, Name: strings.TrimSpace(string(comm)), OpenFDs: -1}
+		// コマンドラインは引数がNULL区切りで格納されている
+		if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+			process.Command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+		}
+		if process.Command == "" {
+			process.Command = process.Name
+		}
+		// statmの2番目の値が常駐ページ数
+		if statm, err := os.ReadFile(filepath.Join(dir, "statm")); err == nil {
+			if fields := strings.Fields(string(statm)); len(fields) >= 2 {
+				pages, _ := strconv.ParseUint(fields[1], 10, 64)
+				process.RSS = pages * pageSize
+			}
+		}
+		// 権限がない場合はファイルディスクリプタを数えられない
+		if fds, err := os.ReadDir(filepath.Join(dir, "fd")); err == nil {
+			process.OpenFDs = len(fds)
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// /proc/[pid]/statからユーザー時間とシステム時間の合計を読み取る関数
+// pid: プロセスID
+func readProcessCPUTime(pid int) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, err
+	}
+	// プロセス名に空白や括弧が含まれる場合があるため、最後の「)」以降を解析
+	content := string(data)
+	index := strings.LastIndex(content, ")")
+	if index < 0 {
+		return 0, fmt.Errorf("unexpected stat format for pid %d", pid)
+	}
+	// 「)」以降の1番目がstate、12番目がutime、13番目がstime
+	fields := strings.Fields(content[index+1:])
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("unexpected stat format for pid %d", pid)
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return utime + stime, nil
+}
+
+// /procからプロセスIDの一覧を取得する関数
+func listPIDs() []int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
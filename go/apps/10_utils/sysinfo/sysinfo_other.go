@@ -0,0 +1,41 @@
+//go:build !linux
+
+package sysinfo
+
+import (
+	"errors"
+	"os"
+)
+
+// 1秒あたりのクロックティック数
+const clockTicks = 100
+
+// 未対応のOSで返すエラー
+var errUnsupported = errors.New("sysinfo is only supported on linux")
+
+// ホスト名を取得する関数
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
+
+// CPU時間を読み取る関数（未対応）
+func readCPUSample() (cpuSample, error) {
+	return cpuSample{}, errUnsupported
+}
+
+// メモリ使用率を読み取る関数（未対応）
+func readMemoryUsage() (float64, error) {
+	return 0, errUnsupported
+}
+
+// ディスク使用率を読み取る関数（未対応）
+// path: 計測するパス
+func readDiskUsage(path string) (float64, error) {
+	return 0, errUnsupported
+}
+
+// プロセスの情報を読み取る関数（未対応）
+func readProcesses() ([]Process, error) {
+	return nil, errUnsupported
+}
@@ -0,0 +1,120 @@
+package tail
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// 1行の最大長（これを超える行は分割して通知する）
+const maxLineLength = 64 * 1024
+
+// ファイルの末尾に追記された行を読み取り、コンテキストがキャンセルされるまで通知する関数
+// ローテーション（ファイルの置き換え）と切り詰めにも追従する
+// ctx: コンテキスト
+// path: ファイルのパス
+// pollInterval: 追記を確認する間隔
+// onLine: 行を受け取る関数（改行は含まない）
+func Follow(ctx context.Context, path string, pollInterval time.Duration, onLine func(line string)) error {
+	// ファイルが作成されるまで待機し、既存の内容は読み飛ばす
+	file, info, err := waitOpen(ctx, path, pollInterval)
+	if err != nil {
+		return err
+	}
+	defer func() { file.Close() }()
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(file)
+	var partial strings.Builder
+	for {
+		chunk, err := reader.ReadString('\n')
+		offset += int64(len(chunk))
+		partial.WriteString(chunk)
+		if err == nil || partial.Len() >= maxLineLength {
+			// 行が完成したか最大長に達したら通知
+			onLine(strings.TrimRight(partial.String(), "\r\n"))
+			partial.Reset()
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		// 追記を待つ
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+
+		current, statErr := os.Stat(path)
+		switch {
+		case statErr != nil:
+			// ローテーション中でファイルが存在しない場合は次回に再確認
+			continue
+		case !os.SameFile(info, current):
+			// 別のファイルに置き換えられた場合は新しいファイルを先頭から読む
+			newFile, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			// 置き換え前のファイルに残っている行を読み切る
+			for {
+				chunk, err := reader.ReadString('\n')
+				partial.WriteString(chunk)
+				if err != nil {
+					break
+				}
+				onLine(strings.TrimRight(partial.String(), "\r\n"))
+				partial.Reset()
+			}
+			if partial.Len() > 0 {
+				onLine(strings.TrimRight(partial.String(), "\r\n"))
+				partial.Reset()
+			}
+			file.Close()
+			file, info, offset = newFile, current, 0
+			reader.Reset(file)
+		case current.Size() < offset:
+			// 切り詰められた場合は先頭から読み直す
+			if offset, err = file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			reader.Reset(file)
+			partial.Reset()
+		}
+	}
+}
+
+// ファイルが開けるようになるまで待機する関数
+// ctx: コンテキスト
+// path: ファイルのパス
+// pollInterval: 再試行の間隔
+func waitOpen(ctx context.Context, path string, pollInterval time.Duration) (*os.File, os.FileInfo, error) {
+	for {
+		file, err := os.Open(path)
+		if err == nil {
+			info, err := file.Stat()
+			if err != nil {
+				file.Close()
+				return nil, nil, err
+			}
+			return file, info, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
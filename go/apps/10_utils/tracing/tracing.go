@@ -0,0 +1,174 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// エクスポーターの種類の定数
+const (
+	// スパンを出力しない（トレースコンテキストの伝搬のみ行う）
+	ExporterNone = "none"
+	// OTLP/HTTPでコレクターに送信
+	ExporterOTLP = "otlp"
+	// 標準出力にJSONで出力（ローカルでの確認用）
+	ExporterStdout = "stdout"
+)
+
+// Configは、トレーシングの設定です。
+type Config struct {
+	// エクスポーターの種類（otlp、stdout、none。空の場合はnone）
+	Exporter string
+	// OTLPの送信先（例: http://otel-collector:4318。空の場合は環境変数 OTEL_EXPORTER_OTLP_ENDPOINT）
+	Endpoint string
+	// サンプリングする割合（0より大きく1以下。0の場合は1）
+	SampleRatio float64
+	// サービス名（例: monitor）
+	ServiceName string
+	// サービスのバージョン
+	ServiceVersion string
+}
+
+// Initは、W3C Trace Contextの伝搬を設定し、エクスポーターが指定されている場合はトレーサープロバイダーを初期化します。
+// 戻り値の関数で、未送信のスパンを送信してトレーサープロバイダーを停止します。
+// ctx: コンテキスト
+// cfg: トレーシングの設定
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	// スパンを出力しない場合も、上流から受け取ったトレースコンテキストは下流に伝搬する
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s exporter: %v", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.ServiceVersion(cfg.ServiceVersion),
+	))
+	if err != nil {
+		return nil, err
+	}
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// 上流でサンプリングされたトレースは割合によらず記録する
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracerは、名前付きのトレーサーを返します。Init の前に取得した場合も、Init 後のトレーサープロバイダーを使用します。
+// name: 計装するパッケージの名前
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+// Injectは、コンテキストのトレースコンテキストをキャリア（traceparent、tracestate）に書き出します。
+// HTTPヘッダーやgRPCのメタデータを使用できないメッセージ（QUICのリクエストなど）で伝搬するために使用します。
+// ctx: コンテキスト
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extractは、キャリアのトレースコンテキストを親としたコンテキストを返します。
+// ctx: コンテキスト
+// carrier: Inject で書き出したキャリア
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// TraceIDは、コンテキストのスパンのトレースIDを返します。スパンがない場合は空文字を返します。
+// ctx: コンテキスト
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// EndSpanは、エラーがある場合はスパンに記録してからスパンを終了します。
+// span: スパン
+// err: 処理のエラー
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Transportは、HTTPリクエストごとにクライアントのスパンを作成し、トレースコンテキストをヘッダーに付与するRoundTripperです。
+type Transport struct {
+	// リクエストを送信するRoundTripper（nilの場合はhttp.DefaultTransport）
+	Base http.RoundTripper
+}
+
+// RoundTripは、スパンを作成してリクエストを送信します。
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx, span := Tracer("no-code-app/http").Start(r.Context(), "HTTP "+r.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLFull(r.URL.Redacted()),
+			semconv.ServerAddress(r.URL.Hostname()),
+		),
+	)
+	// RoundTripperはリクエストを変更してはならないため複製してヘッダーを付与する
+	r = r.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	response, err := base.RoundTrip(r)
+	if err != nil {
+		EndSpan(span, err)
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(response.StatusCode))
+	if response.StatusCode >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+	}
+	span.End()
+	return response, nil
+}
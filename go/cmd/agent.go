@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"no-code-app/apps/10_utils/config"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/apps/10_utils/quic"
+	"no-code-app/apps/10_utils/sysinfo"
+	"no-code-app/pkg/agent"
+)
+
+// agentCommandは、監視対象ホストでエージェントを起動するコマンドです。
+func agentCommand(fs *flag.FlagSet, opts *options) func(args []string) error {
+	return func(args []string) error {
+		cfg, err := opts.loadConfig()
+		if err != nil {
+			return err
+		}
+		// シグナルを受け取るまでリクエストを受け付ける
+		ctx, stop := signalContext()
+		defer stop()
+		return runAgent(ctx, cfg)
+	}
+}
+
+// runAgentは、エージェントを初期化し、コンテキストがキャンセルされるまでリクエストを受け付けます。
+// ctx: コンテキスト
+// cfg: 設定
+func runAgent(ctx context.Context, cfg *config.Config) error {
+	settings := cfg.Agent
+	// 監視サーバー以外からのリクエストを拒否するため、認証トークンは必須とする
+	if settings.Token == "" {
+		return fmt.Errorf("agent.token is required")
+	}
+
+	// ログの出力形式、コンポーネントごとのログレベル、絞り込みを設定
+	if err := initLogger(cfg); err != nil {
+		return err
+	}
+	// ログファイルを初期化（停止時にログファイルを閉じる）
+	closeLogFile, err := initLogFile(cfg, "agent")
+	if err != nil {
+		return err
+	}
+	defer closeLogFile()
+	// ログをsyslog、Graylogに送信（停止時に送信を待つログを送信する）
+	stopLogShipping, err := initLogShipping(cfg, "agent")
+	if err != nil {
+		return err
+	}
+	defer stopLogShipping()
+
+	// トレーシングを初期化（監視サーバーのスパンを親としてスパンを作成する）
+	shutdownTracing, err := initTracing(ctx, cfg, "agent")
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+
+	// TLS設定を作成（証明書が指定されていない場合は自己署名証明書を使用）
+	var tlsConfig *tls.Config
+	if settings.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate: %v", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	} else {
+		var err error
+		tlsConfig, err = quic.GenerateSelfSignedTLSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to generate certificate: %v", err)
+		}
+	}
+	// 監視サーバーの monitoring.agent.cert_fingerprint に指定するフィンガープリントを出力
+	logger.With("cert_fingerprint", quic.CertificateFingerprint(tlsConfig.Certificates[0].Certificate[0])).Info("agent certificate")
+
+	// 監視するサービスの設定を作成
+	var services []agent.ServiceConfig
+	for _, service := range settings.Services {
+		services = append(services, agent.ServiceConfig{Name: service.Name, Processes: service.Processes, LogFiles: service.LogFiles})
+	}
+	diskPath := settings.DiskPath
+	if diskPath == "" {
+		diskPath = "/"
+	}
+	listenAddress := settings.ListenAddress
+	if listenAddress == "" {
+		listenAddress = ":443"
+	}
+
+	// エージェントを初期化してリクエストの受け付けを開始
+	a := agent.New(sysinfo.NewCollector(diskPath), services, settings.Token)
+	server := quic.NewServer(listenAddress, tlsConfig, a.Handle)
+	return server.ListenAndServe(ctx)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"no-code-app/apps/10_utils/config"
+	"no-code-app/apps/10_utils/quic"
+)
+
+// configValidateCommandは、設定ファイルを読み込んで検証するコマンドです。
+func configValidateCommand(fs *flag.FlagSet, opts *options) func(args []string) error {
+	return func(args []string) error {
+		cfg, err := opts.loadConfig()
+		if err != nil {
+			return err
+		}
+		if err := validateConfig(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("%s is valid\n", opts.configPath)
+		return nil
+	}
+}
+
+// validateConfigは、serve、monitor、agent、supervise が起動時に行う設定の検証を、
+// 同じ関数を使用して、DBや送信先への接続、ファイルの作成を行わずに実行します。
+// cfg: 設定
+func validateConfig(cfg *config.Config) error {
+	// ログの出力形式、コンポーネントごとのログレベル、絞り込み、伏せ字の規則
+	if err := initLogger(cfg); err != nil {
+		return err
+	}
+	if _, err := logFileEncoder(cfg); err != nil {
+		return err
+	}
+	if _, err := logDBMinLevel(cfg); err != nil {
+		return err
+	}
+	// syslog、Graylogのシンク（作成のみで接続は行わない）
+	sinks, err := newLogShippingSinks(cfg, "config")
+	if err != nil {
+		return err
+	}
+	closeSinks(sinks)
+	// レート制限のポリシーとストレージ
+	if _, err := rateLimitPolicies(cfg); err != nil {
+		return err
+	}
+	if err := validateRateLimitStore(cfg.RateLimit.Store); err != nil {
+		return err
+	}
+	// 合成プローブの検証対象とエージェントの証明書の検証
+	if _, err := probeTargets(cfg); err != nil {
+		return err
+	}
+	if _, err := quic.ClientTLSConfig(cfg.Monitoring.Agent.CAFile, cfg.Monitoring.Agent.ServerName, cfg.Monitoring.Agent.CertFingerprint); err != nil {
+		return fmt.Errorf("invalid monitoring.agent: %v", err)
+	}
+	// supervise のサービスの定義（再起動ポリシーなど）
+	if len(cfg.Supervisor.Services) > 0 {
+		if _, err := newSupervisor(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
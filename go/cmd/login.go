@@ -1,79 +1,172 @@
-package main
+package cmd
 
 import (
+	"context"
+	"crypto/subtle"
 	"database/sql"
+	"flag"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 
-	_ "github.com/go-sql-driver/mysql"
-)
+	repositories "no-code-app/apps/04_repositories"
+	interfaces "no-code-app/apps/05_interfaces"
+	orm "no-code-app/apps/10_utils/database"
+	errorhandler "no-code-app/apps/10_utils/error"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/pkg/middleware"
+	"no-code-app/pkg/openapi"
+	"no-code-app/pkg/ratelimit"
+	"no-code-app/pkg/router"
+	"no-code-app/pkg/server"
 
-// DB接続情報
-const (
-	dbUser     = "dev"
-	dbPassword = "dev"
-	dbName     = "sample"
+	"golang.org/x/crypto/bcrypt"
 )
 
-// DB接続を初期化する関数
-func initDB() (*sql.DB, error) {
-	dataSourceName := fmt.Sprintf("%s:%s@/%s", dbUser, dbPassword, dbName)
-	db, err := sql.Open("mysql", dataSourceName)
-	if err != nil {
-		return nil, err
+// serveLoginCommandは、ログインサービスを起動するコマンドです。
+func serveLoginCommand(fs *flag.FlagSet, opts *options) func(args []string) error {
+	// 待ち受けアドレスは環境変数 PORT をデフォルトとする
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
 	}
-	if err = db.Ping(); err != nil {
-		return nil, err
+	addr := fs.String("addr", ":"+port, "待ち受けアドレス")
+
+	return func(args []string) error {
+		cfg, err := opts.loadConfig()
+		if err != nil {
+			return err
+		}
+
+		// ログの出力形式、コンポーネントごとのログレベル、絞り込みを設定
+		if err := initLogger(cfg); err != nil {
+			return err
+		}
+		// ログファイルを初期化（停止時にログファイルを閉じる）
+		closeLogFile, err := initLogFile(cfg, "login")
+		if err != nil {
+			return err
+		}
+		defer closeLogFile()
+		// ログをsyslog、Graylogに送信（停止時に送信を待つログを送信する）
+		stopLogShipping, err := initLogShipping(cfg, "login")
+		if err != nil {
+			return err
+		}
+		defer stopLogShipping()
+
+		// トレーシングを初期化（停止時に未送信のスパンを送信する）
+		shutdownTracing, err := initTracing(context.Background(), cfg, "login")
+		if err != nil {
+			return err
+		}
+		defer shutdownTracing()
+
+		// DB接続を初期化
+		db, err := orm.NewORM(cfg.DataSourceName())
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %v", err)
+		}
+		defer db.Close()
+
+		// ログをログテーブルに記録（停止時に記録を待つログを記録する）
+		stopLogSink, sinkRecordsErrors, err := initLogSink(cfg, "login")
+		if err != nil {
+			return err
+		}
+		defer stopLogSink()
+		// パニックはシンクが記録しない場合のみ直接記録する
+		var panicLogs interfaces.LogRepository
+		if !sinkRecordsErrors {
+			panicLogs = repositories.NewMySQLLogRepository(db.DB)
+		}
+
+		// シグナルを受け取るまでリクエストを受け付ける
+		ctx, stop := signalContext()
+		defer stop()
+
+		// レート制限を初期化（ログインは認証前のためユーザー単位の制限は適用しない）
+		limiter, closeLimiter, err := newRateLimiter(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		defer closeLimiter()
+
+		r := router.New(router.NewServeMuxEngine())
+		r.Use(middleware.RequestID(), middleware.Tracing(), middleware.AccessLog(), middleware.Recovery("login", panicLogs), limiter.middleware(ratelimit.KeyIP, ratelimit.KeyAPIKey), middleware.Validate())
+		r.NotFound(notFoundHandler)
+		r.MethodNotAllowed(methodNotAllowedHandler)
+		r.POST("/login", loginHandler(db), router.Name("login"), router.Doc(&openapi.Operation{
+			Summary: "メールアドレスとパスワードでログインします",
+			Tags:    []string{"auth"},
+			RequestBody: openapi.FormBody(openapi.Object(map[string]*openapi.Schema{
+				"username": openapi.String().Describe("メールアドレス").Length(1, 255),
+				"password": openapi.String().Describe("パスワード").Length(1, 255),
+			}, "username", "password")),
+			Responses: map[string]*openapi.Response{
+				"200": openapi.TextResponse("ログインに成功"),
+				"401": openapi.JSONResponse("メールアドレスまたはパスワードが一致しない", openapi.SchemaOf(errorhandler.ErrorResponse{})),
+			},
+		}))
+		registerAPIDocs(r, openapi.Info{Title: "no-code-app login", Version: Version})
+
+		return server.New(serverConfig(cfg, *addr), r).Run(ctx)
 	}
-	return db, nil
 }
 
+// ユーザー名またはパスワードが一致しない場合のエラー
+var errInvalidCredentials = errorhandler.New(http.StatusUnauthorized, errorhandler.CodeUnauthorized, "Invalid username or password")
+
 // ログインハンドラー
-func loginHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
-		return
-	}
+// db: DB接続（クエリはリクエストのスパンの子として記録する）
+func loginHandler(db *orm.ORM) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 
-	username := r.FormValue("username")
-	password := r.FormValue("password")
+		username := r.FormValue("username")
+		password := r.FormValue("password")
 
-	db, err := initDB()
-	if err != nil {
-		http.Error(w, "Database connection error", http.StatusInternalServerError)
-		return
-	}
-	defer db.Close()
+		var dbPassword string
+		err := db.QueryRowContext(r.Context(), "SELECT password FROM cm_m_users WHERE email = ?", username).Scan(&dbPassword)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				errorhandler.WriteError(w, r, errInvalidCredentials)
+			} else {
+				errorhandler.WriteError(w, r, err)
+			}
+			return
+		}
 
-	var dbPassword string
-	err = db.QueryRow("SELECT password FROM cm_m_users WHERE email = ?", username).Scan(&dbPassword)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			http.Error(w, "Invalid username or password", http.StatusUnauthorized)
-		} else {
-			http.Error(w, "Database query error", http.StatusInternalServerError)
+		if !verifyPassword(r.Context(), db, username, dbPassword, password) {
+			errorhandler.WriteError(w, r, errInvalidCredentials)
+			return
 		}
-		return
-	}
 
-	if password != dbPassword {
-		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
-		return
+		fmt.Fprintf(w, "Login successful")
 	}
-
-	fmt.Fprintf(w, "Login successful")
 }
 
-func main() {
-	http.HandleFunc("/login", loginHandler)
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+// verifyPasswordは、保存されているパスワードと入力されたパスワードを比較します。
+// パスワードは user create で bcrypt のハッシュとして保存します。
+// 以前に平文で保存されたパスワードは、一致した場合に bcrypt のハッシュに置き換えます（全てのユーザーの移行後に削除する一時的な処理）。
+// ctx: コンテキスト
+// db: DB接続
+// email: メールアドレス
+// stored: 保存されているパスワード
+// password: 入力されたパスワード
+func verifyPassword(ctx context.Context, db *orm.ORM, email, stored, password string) bool {
+	if _, err := bcrypt.Cost([]byte(stored)); err == nil {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+	if stored == "" || subtle.ConstantTimeCompare([]byte(stored), []byte(password)) != 1 {
+		return false
 	}
-	log.Printf("Server starting on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	// ログインは成功させ、ハッシュへの置き換えに失敗した場合は次回のログインで再試行する
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err == nil {
+		_, err = db.ExecContext(ctx, "UPDATE cm_m_users SET password = ? WHERE email = ? AND password = ?", string(hash), email, stored)
+	}
+	if err != nil {
+		logger.Ctx(ctx).With("error", err).Warn("failed to rehash plaintext password")
 	}
+	return true
 }
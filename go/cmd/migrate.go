@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	logger "no-code-app/apps/10_utils/log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	orm "no-code-app/apps/10_utils/database"
+)
+
+// マイグレーションファイルのデフォルトのディレクトリ
+const defaultMigrationDir = "../mysql/02_migrations"
+
+// マイグレーションファイル名の形式（例: 0001_create_cm_t_sample.up.sql）
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// 適用済みのマイグレーションを記録するテーブルの作成
+const createMigrationTableQuery = `CREATE TABLE IF NOT EXISTS cm_t_schema_migration (
+    version BIGINT PRIMARY KEY COMMENT 'バージョン',
+    name VARCHAR(255) NOT NULL COMMENT 'マイグレーション名',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '作成日時',
+    created_by VARCHAR(50) NOT NULL COMMENT '作成ユーザー',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新日時',
+    updated_by VARCHAR(50) NOT NULL COMMENT '更新ユーザー'
+)`
+
+// migrationは、1つのマイグレーションです。
+type migration struct {
+	// バージョン
+	version int64
+	// マイグレーション名
+	name string
+	// 適用するSQLファイルのパス
+	upFile string
+	// 取り消すSQLファイルのパス
+	downFile string
+}
+
+// migrateUpCommandは、未適用のマイグレーションを適用するコマンドです。
+func migrateUpCommand(fs *flag.FlagSet, opts *options) func(args []string) error {
+	dir := fs.String("dir", defaultMigrationDir, "マイグレーションファイルのディレクトリ")
+	steps := fs.Int("steps", 0, "適用する件数（0の場合は全て）")
+
+	return func(args []string) error {
+		return runMigrations(opts, *dir, func(db *sql.DB, migrations []migration, applied map[int64]bool) error {
+			count := 0
+			for _, m := range migrations {
+				if applied[m.version] {
+					continue
+				}
+				if *steps > 0 && count >= *steps {
+					break
+				}
+				if m.upFile == "" {
+					return fmt.Errorf("migration %d_%s has no up file", m.version, m.name)
+				}
+				if err := execFile(db, m.upFile); err != nil {
+					return fmt.Errorf("failed to apply %d_%s: %v", m.version, m.name, err)
+				}
+				if _, err := db.Exec("INSERT INTO cm_t_schema_migration (version, name, created_by, updated_by) VALUES (?, ?, 'migrate', 'migrate')", m.version, m.name); err != nil {
+					return err
+				}
+				logger.Info(fmt.Sprintf("Applied migration %d_%s", m.version, m.name))
+				count++
+			}
+			if count == 0 {
+				logger.Info("No migrations to apply")
+			}
+			return nil
+		})
+	}
+}
+
+// migrateDownCommandは、適用済みのマイグレーションを新しい順に取り消すコマンドです。
+func migrateDownCommand(fs *flag.FlagSet, opts *options) func(args []string) error {
+	dir := fs.String("dir", defaultMigrationDir, "マイグレーションファイルのディレクトリ")
+	steps := fs.Int("steps", 1, "取り消す件数")
+
+	return func(args []string) error {
+		if *steps <= 0 {
+			return errUsage
+		}
+		return runMigrations(opts, *dir, func(db *sql.DB, migrations []migration, applied map[int64]bool) error {
+			count := 0
+			for i := len(migrations) - 1; i >= 0 && count < *steps; i-- {
+				m := migrations[i]
+				if !applied[m.version] {
+					continue
+				}
+				if m.downFile == "" {
+					return fmt.Errorf("migration %d_%s has no down file", m.version, m.name)
+				}
+				if err := execFile(db, m.downFile); err != nil {
+					return fmt.Errorf("failed to revert %d_%s: %v", m.version, m.name, err)
+				}
+				if _, err := db.Exec("DELETE FROM cm_t_schema_migration WHERE version = ?", m.version); err != nil {
+					return err
+				}
+				logger.Info(fmt.Sprintf("Reverted migration %d_%s", m.version, m.name))
+				count++
+			}
+			if count == 0 {
+				logger.Info("No migrations to revert")
+			}
+			return nil
+		})
+	}
+}
+
+// runMigrationsは、DBに接続してマイグレーションの一覧と適用済みのバージョンを読み込み、処理を実行します。
+// opts: 共通フラグの値
+// dir: マイグレーションファイルのディレクトリ
+// apply: マイグレーションを適用または取り消す関数
+func runMigrations(opts *options, dir string, apply func(db *sql.DB, migrations []migration, applied map[int64]bool) error) error {
+	cfg, err := opts.loadConfig()
+	if err != nil {
+		return err
+	}
+	migrations, err := loadMigrations(dir)
+	if err != nil {
+		return err
+	}
+
+	// 1つのファイルに複数のSQL文を記述できるようにする
+	db, err := orm.NewORM(cfg.DataSourceName() + "&multiStatements=true")
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.DB.Exec(createMigrationTableQuery); err != nil {
+		return err
+	}
+	applied, err := appliedVersions(db.DB)
+	if err != nil {
+		return err
+	}
+	return apply(db.DB, migrations, applied)
+}
+
+// loadMigrationsは、ディレクトリからマイグレーションファイルを読み込み、バージョン順に並べて返します。
+// dir: マイグレーションファイルのディレクトリ
+func loadMigrations(dir string) ([]migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		var version int64
+		fmt.Sscan(match[1], &version)
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: match[2]}
+			byVersion[version] = m
+		} else if m.name != match[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, m.name, match[2])
+		}
+		if match[3] == "up" {
+			m.upFile = filepath.Join(dir, entry.Name())
+		} else {
+			m.downFile = filepath.Join(dir, entry.Name())
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// appliedVersionsは、適用済みのバージョンを取得します。
+// db: DB接続
+func appliedVersions(db *sql.DB) (map[int64]bool, error) {
+	rows, err := db.Query("SELECT version FROM cm_t_schema_migration")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// execFileは、SQLファイルを実行します。
+// db: DB接続
+// path: SQLファイルのパス
+func execFile(db *sql.DB, path string) error {
+	query, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(query) == 0 {
+		return errors.New("empty migration file")
+	}
+	_, err = db.Exec(string(query))
+	return err
+}
@@ -1,32 +1,295 @@
-package main
+package cmd
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
 	controllers "no-code-app/apps/01_controllers"
 	usecases "no-code-app/apps/02_use_cases"
+	entities "no-code-app/apps/03_entities"
 	repositories "no-code-app/apps/04_repositories"
+	interfaces "no-code-app/apps/05_interfaces"
+	"no-code-app/apps/10_utils/anomaly"
+	"no-code-app/apps/10_utils/config"
+	"no-code-app/apps/10_utils/google"
 	"no-code-app/apps/10_utils/quic"
+	"no-code-app/pkg/middleware"
+	"no-code-app/pkg/openapi"
+	"no-code-app/pkg/ratelimit"
+	"no-code-app/pkg/router"
+	"no-code-app/pkg/server"
+	"regexp"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/api/calendar/v3"
 )
 
-func monitoring() {
-	router := gin.Default()
+// サービスごとに保持するステータス履歴の最大件数
+const statusHistoryLimit = 10000
+
+// serveMonitorCommandは、監視サービスを起動するコマンドです。
+func serveMonitorCommand(fs *flag.FlagSet, opts *options) func(args []string) error {
+	addr := fs.String("addr", ":8080", "待ち受けアドレス")
+
+	return func(args []string) error {
+		cfg, err := opts.loadConfig()
+		if err != nil {
+			return err
+		}
+		// シグナルを受け取るとバックグラウンドの処理とサーバーを停止する
+		ctx, stop := signalContext()
+		defer stop()
+		return serveMonitor(ctx, cfg, *addr)
+	}
+}
+
+// serveMonitorは、監視サービスを初期化し、コンテキストがキャンセルされるまでサーバーを起動します。
+// ctx: コンテキスト（キャンセルでバックグラウンドの処理も停止する）
+// cfg: 設定
+// addr: 待ち受けアドレス
+func serveMonitor(ctx context.Context, cfg *config.Config, addr string) error {
+	// ログの出力形式、コンポーネントごとのログレベル、絞り込みを設定
+	if err := initLogger(cfg); err != nil {
+		return err
+	}
+	// ログファイルを初期化（停止時にログファイルを閉じる）
+	closeLogFile, err := initLogFile(cfg, "monitor")
+	if err != nil {
+		return err
+	}
+	defer closeLogFile()
+	// ログをsyslog、Graylogに送信（停止時に送信を待つログを送信する）
+	stopLogShipping, err := initLogShipping(cfg, "monitor")
+	if err != nil {
+		return err
+	}
+	defer stopLogShipping()
+
+	// トレーシングを初期化（停止時に未送信のスパンを送信する）
+	shutdownTracing, err := initTracing(ctx, cfg, "monitor")
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+	// 合成プローブの検証対象を作成（設定の誤りは起動時にエラーとする）
+	targets, err := probeTargets(cfg)
+	if err != nil {
+		return err
+	}
+	// ルーターを初期化（エンジンにはginを使用し、アクセスログとパニックの回復はミドルウェアで行う）
+	r := router.New(router.NewGinEngine(gin.New()))
+	// レート制限を初期化（ユーザー単位の制限は認証の後に適用する）
+	limiter, closeLimiter, err := newRateLimiter(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeLimiter()
+	// パニックを記録し、ログを検索するログテーブルに接続（DBが停止していても監視は開始する）
+	logDB, err := sql.Open("mysql", cfg.DataSourceName())
+	if err != nil {
+		return err
+	}
+	defer logDB.Close()
+	// ログをログテーブルに記録（停止時に記録を待つログを記録する）
+	stopLogSink, sinkRecordsErrors, err := initLogSink(cfg, "monitor")
+	if err != nil {
+		return err
+	}
+	defer stopLogSink()
+	// パニックはシンクが記録しない場合のみ直接記録する
+	var panicLogs interfaces.LogRepository
+	if !sinkRecordsErrors {
+		panicLogs = repositories.NewMySQLLogRepository(logDB)
+	}
+	r.Use(middleware.RequestID(), middleware.Tracing(), middleware.AccessLog(), middleware.Recovery("monitor", panicLogs), limiter.middleware(ratelimit.KeyIP, ratelimit.KeyAPIKey), middleware.Validate())
+	r.NotFound(notFoundHandler)
+	r.MethodNotAllowed(methodNotAllowedHandler)
+	auth := router.Chain(middleware.APIKeyAuth(cfg.APIKeyMap()), limiter.middleware(ratelimit.KeyUser))
+	api := r.Group("/api/v1")
 
 	// QUICクライアントを作成
-	quicClient, err := quic.NewClient("localhost:443", 3, 2*time.Second)
+	agentSettings := cfg.Monitoring.Agent
+	if agentSettings.Address == "" {
+		agentSettings.Address = "localhost:443"
+	}
+	// エージェントの証明書を検証する（自己署名証明書の場合はフィンガープリントで固定する）
+	agentTLSConfig, err := quic.ClientTLSConfig(agentSettings.CAFile, agentSettings.ServerName, agentSettings.CertFingerprint)
 	if err != nil {
-		log.Fatalf("Failed to create QUIC client: %v", err)
+		return fmt.Errorf("invalid monitoring.agent: %v", err)
 	}
+	quicClient, err := quic.NewClient(agentSettings.Address, agentTLSConfig, 3, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to create QUIC client: %v", err)
+	}
+	// サーバーの停止後にエージェントとの接続をクローズ
+	defer quicClient.Close()
+
+	// メンテナンス期間のユースケースを初期化
+	maintenanceUseCase, err := newMaintenanceUseCase(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	// アラートのユースケースを初期化
+	alertUseCase := usecases.NewAlertUseCase(maintenanceUseCase, repositories.NewLogAlertNotifier())
 
 	// リポジトリを初期化
-	monitoringRepo := repositories.NewMonitoringRepository(quicClient)
+	monitoringRepo := repositories.NewMonitoringRepository(quicClient, agentSettings.TopProcesses, agentSettings.Token)
+	historyRepo := repositories.NewInMemoryStatusHistoryRepository(statusHistoryLimit)
 	// ユースケースを初期化
-	monitoringUseCase := usecases.NewMonitoringUseCase(monitoringRepo)
+	anomalyUseCase := usecases.NewAnomalyUseCase(historyRepo, alertUseCase, anomalySettings(cfg))
+	monitoringUseCase := usecases.NewMonitoringUseCase(monitoringRepo, historyRepo, maintenanceUseCase, alertUseCase, anomalyUseCase)
+	probeUseCase := usecases.NewProbeUseCase(repositories.NewProbeChecker(), monitoringUseCase, alertUseCase, targets, cfg.Monitoring.Probes.CertExpiryWarning)
+	// バックグラウンドで合成プローブを開始
+	go probeUseCase.Run(ctx)
+	// バックグラウンドでエージェントからのステータス取得を開始
+	if len(agentSettings.Services) > 0 {
+		pollInterval := agentSettings.PollInterval
+		if pollInterval <= 0 {
+			pollInterval = 30 * time.Second
+		}
+		go monitoringUseCase.RunPolling(ctx, agentSettings.Services, pollInterval)
+	}
+	// ログ追従のユースケースを初期化
+	historySize := cfg.Monitoring.LogTail.HistorySize
+	if historySize <= 0 {
+		historySize = 1000
+	}
+	logTailUseCase := usecases.NewLogTailUseCase(repositories.NewLogTailRepository(quicClient, agentSettings.Token), cfg.Monitoring.LogTail.Services, historySize, cfg.Monitoring.LogTail.AllowedUsers)
+	// バックグラウンドでログの追従を開始
+	go logTailUseCase.Run(ctx)
+
 	// コントローラーを初期化
-	controllers.NewMonitoringController(router, monitoringUseCase)
+	statusHub := controllers.NewWebSocketHub()
+	logHub := controllers.NewWebSocketHub()
+	controllers.NewMonitoringController(api, monitoringUseCase, statusHub)
+	controllers.NewLogTailController(api, logTailUseCase, auth, logHub)
+	controllers.NewLogLevelController(api, usecases.NewLogLevelUseCase(cfg.Log.AdminUsers), auth)
+	controllers.NewLogSearchController(api, usecases.NewLogSearchUseCase(repositories.NewMySQLLogRepository(logDB), cfg.Log.AdminUsers), auth, logHub)
+	registerAPIDocs(r, openapi.Info{Title: "no-code-app monitor", Version: Version})
+
+	// サーバーを起動（シャットダウン時にWebSocketのクライアントへクローズフレームを送信）
+	srv := server.New(serverConfig(cfg, addr), r)
+	srv.OnShutdown(statusHub.Close)
+	srv.OnShutdown(logHub.Close)
+	return srv.Run(ctx)
+}
+
+// newMaintenanceUseCaseは、Googleカレンダーと同期するメンテナンス期間のユースケースを初期化します。
+// カレンダーIDが設定されていない場合はnilを返します。
+// ctx: コンテキスト（キャンセルで同期を停止する）
+// cfg: 設定
+func newMaintenanceUseCase(ctx context.Context, cfg *config.Config) (usecases.MaintenanceUseCase, error) {
+	settings := cfg.Monitoring.Maintenance
+	if settings.CalendarID == "" {
+		return nil, nil
+	}
 
-	// サーバーを起動
-	router.Run(":8080")
+	// カレンダーの読み取り権限で認証済みクライアントを取得
+	httpClient, err := google.GetService(settings.CredentialsFile, settings.TokenFile, calendar.CalendarReadonlyScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authorize Google Calendar: %v", err)
+	}
+	calendarClient, err := google.NewCalendarClient(httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Google Calendar client: %v", err)
+	}
+
+	// 未設定の項目にはデフォルト値を使用
+	syncInterval := settings.SyncInterval
+	if syncInterval <= 0 {
+		syncInterval = 5 * time.Minute
+	}
+	lookBehind := settings.LookBehind
+	if lookBehind <= 0 {
+		lookBehind = 30 * 24 * time.Hour
+	}
+	lookAhead := settings.LookAhead
+	if lookAhead <= 0 {
+		lookAhead = 7 * 24 * time.Hour
+	}
+
+	maintenanceRepo := repositories.NewCalendarMaintenanceRepository(calendarClient, settings.CalendarID)
+	maintenanceUseCase := usecases.NewMaintenanceUseCase(maintenanceRepo, lookBehind, lookAhead)
+	// バックグラウンドでカレンダーとの同期を開始
+	go maintenanceUseCase.RunSync(ctx, syncInterval)
+	return maintenanceUseCase, nil
+}
+
+// anomalySettingsは、設定から異常検知の設定を作成します。未設定の項目にはデフォルト値を使用します。
+// cfg: 設定
+func anomalySettings(cfg *config.Config) usecases.AnomalySettings {
+	settings := cfg.Monitoring.Anomaly
+	detector := anomaly.DefaultConfig()
+	if settings.Alpha > 0 {
+		detector.Alpha = settings.Alpha
+	}
+	if settings.Threshold > 0 {
+		detector.Threshold = settings.Threshold
+	}
+	if settings.MinSamples > 0 {
+		detector.MinSamples = settings.MinSamples
+	}
+	if settings.MinStdDev > 0 {
+		detector.MinStdDev = settings.MinStdDev
+	}
+	result := usecases.AnomalySettings{
+		Detector:        detector,
+		BaselineWindow:  14 * 24 * time.Hour,
+		ForecastWindow:  3 * 24 * time.Hour,
+		ForecastHorizon: 7 * 24 * time.Hour,
+		DiskThreshold:   100,
+		AlertCooldown:   time.Hour,
+	}
+	if settings.BaselineWindow > 0 {
+		result.BaselineWindow = settings.BaselineWindow
+	}
+	if settings.ForecastWindow > 0 {
+		result.ForecastWindow = settings.ForecastWindow
+	}
+	if settings.ForecastHorizon > 0 {
+		result.ForecastHorizon = settings.ForecastHorizon
+	}
+	if settings.DiskThreshold > 0 {
+		result.DiskThreshold = settings.DiskThreshold
+	}
+	if settings.AlertCooldown > 0 {
+		result.AlertCooldown = settings.AlertCooldown
+	}
+	return result
+}
+
+// probeTargetsは、設定から合成プローブの検証対象を作成します。
+// 不明な種類や正規表現として不正な body_pattern は、検証のたびに失敗するため起動時にエラーとします。
+// cfg: 設定
+func probeTargets(cfg *config.Config) ([]entities.ProbeTarget, error) {
+	var targets []entities.ProbeTarget
+	for i, t := range cfg.Monitoring.Probes.Targets {
+		switch entities.ProbeType(t.Type) {
+		case entities.ProbeTypeHTTP, entities.ProbeTypeTCP, entities.ProbeTypeGRPC, entities.ProbeTypeQUIC:
+		default:
+			return nil, fmt.Errorf("invalid monitoring.probes.targets[%d]: unknown type %q", i, t.Type)
+		}
+		if t.BodyPattern != "" {
+			if _, err := regexp.Compile(t.BodyPattern); err != nil {
+				return nil, fmt.Errorf("invalid monitoring.probes.targets[%d].body_pattern: %v", i, err)
+			}
+		}
+		targets = append(targets, entities.ProbeTarget{
+			Name:               t.Name,
+			Type:               entities.ProbeType(t.Type),
+			Address:            t.Address,
+			Interval:           t.Interval,
+			Timeout:            t.Timeout,
+			Method:             t.Method,
+			ExpectedStatus:     t.ExpectedStatus,
+			BodyPattern:        t.BodyPattern,
+			GRPCService:        t.GRPCService,
+			TLS:                t.TLS,
+			InsecureSkipVerify: t.InsecureSkipVerify,
+			NextProtos:         t.NextProtos,
+		})
+	}
+	return targets, nil
 }
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	logger "no-code-app/apps/10_utils/log"
+	"os"
+	"strings"
+
+	"no-code-app/apps/10_utils/config"
+	grpcutil "no-code-app/apps/10_utils/gRPC"
+)
+
+// 使用方法の誤りを表すエラー
+var errUsage = errors.New("usage error")
+
+// optionsは、全てのサブコマンドで共通のフラグの値です。
+type options struct {
+	// 設定ファイルのパス
+	configPath string
+	// ログレベル
+	logLevel string
+}
+
+// loadConfigは、共通フラグで指定された設定ファイルを読み込みます。
+func (o *options) loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig(o.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config %s: %v", o.configPath, err)
+	}
+	return cfg, nil
+}
+
+// commandは、サブコマンドの定義です。
+type command struct {
+	// コマンド名
+	name string
+	// 説明
+	description string
+	// 子のサブコマンド
+	subcommands []*command
+	// コマンド固有のフラグを登録し、実行する関数を返す関数（子を持つコマンドではnil）
+	setup func(fs *flag.FlagSet, opts *options) func(args []string) error
+}
+
+// rootCommandは、全てのサブコマンドを持つルートコマンドを返します。
+func rootCommand() *command {
+	return &command{
+		name: "no-code-app",
+		subcommands: []*command{
+			{name: "serve", description: "サーバーを起動します", subcommands: []*command{
+				{name: "login", description: "ログインサービスを起動します", setup: serveLoginCommand},
+				{name: "monitor", description: "監視サービスを起動します", setup: serveMonitorCommand},
+			}},
+			{name: "agent", description: "監視対象ホストでエージェントを起動します", setup: agentCommand},
+			{name: "supervise", description: "設定ファイルのサービスを子プロセスとして起動・監視します", setup: superviseCommand},
+			{name: "migrate", description: "データベースのマイグレーションを実行します", subcommands: []*command{
+				{name: "up", description: "未適用のマイグレーションを適用します", setup: migrateUpCommand},
+				{name: "down", description: "適用済みのマイグレーションを取り消します", setup: migrateDownCommand},
+			}},
+			{name: "user", description: "ユーザーを管理します", subcommands: []*command{
+				{name: "create", description: "ユーザーを作成します", setup: userCreateCommand},
+			}},
+			{name: "config", description: "設定ファイルを管理します", subcommands: []*command{
+				{name: "validate", description: "設定ファイルを検証します", setup: configValidateCommand},
+			}},
+			{name: "version", description: "バージョンを表示します", setup: versionCommand},
+		},
+	}
+}
+
+// Executeは、コマンドライン引数に従ってサブコマンドを実行し、終了コードを返します。
+// args: プログラム名を除くコマンドライン引数
+func Execute(args []string) int {
+	// 設定ファイルのパスは環境変数 CONFIG_PATH をデフォルトとする
+	opts := &options{configPath: "config.yaml", logLevel: "info"}
+	if path, ok := os.LookupEnv("CONFIG_PATH"); ok {
+		opts.configPath = path
+	}
+
+	// サブコマンド名の前に指定された共通フラグを解析
+	root := rootCommand()
+	fs := newFlagSet(root.name, opts)
+	fs.Usage = func() { printUsage(os.Stderr, root.name, root) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// サブコマンドをたどる
+	current, path, rest := root, root.name, fs.Args()
+	for current.setup == nil {
+		if len(rest) == 0 || findSubcommand(current, rest[0]) == nil {
+			if len(rest) > 0 {
+				fmt.Fprintf(os.Stderr, "unknown command %q\n", rest[0])
+			}
+			printUsage(os.Stderr, path, current)
+			return 2
+		}
+		current, path, rest = findSubcommand(current, rest[0]), path+" "+rest[0], rest[1:]
+	}
+
+	// サブコマンド固有のフラグと共通フラグを解析
+	fs = newFlagSet(path, opts)
+	run := current.setup(fs, opts)
+	if err := fs.Parse(rest); err != nil {
+		return 2
+	}
+	level, err := logger.ParseLogLevel(opts.logLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	logger.SetLogLevel(level)
+	// ライブラリが slog、log、logr、grpclog で出力するログも、ロガーのレベル、出力形式、出力先に従って出力する
+	logger.SetSlogDefault(logger.Default())
+	grpcutil.SetLogger(logger.Default(), 0)
+
+	if err := run(fs.Args()); err != nil {
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	return 0
+}
+
+// newFlagSetは、共通フラグを登録したフラグセットを作成します。
+// name: コマンドのパス
+// opts: 共通フラグの値
+func newFlagSet(name string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.configPath, "config", opts.configPath, "設定ファイルのパス（環境変数 CONFIG_PATH）")
+	fs.StringVar(&opts.logLevel, "log-level", opts.logLevel, "ログレベル（debug, info, warn, error）")
+	return fs
+}
+
+// findSubcommandは、名前に一致する子のサブコマンドを返します。
+// parent: 親のコマンド
+// name: サブコマンド名
+func findSubcommand(parent *command, name string) *command {
+	for _, sub := range parent.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// printUsageは、コマンドの使用方法を出力します。
+// w: 出力先
+// path: コマンドのパス
+// c: コマンド
+func printUsage(w io.Writer, path string, c *command) {
+	fmt.Fprintf(w, "Usage: %s [--config path] [--log-level level] <command>\n\nCommands:\n", path)
+	for _, sub := range c.subcommands {
+		name := sub.name
+		if len(sub.subcommands) > 0 {
+			var names []string
+			for _, s := range sub.subcommands {
+				names = append(names, s.name)
+			}
+			name += " " + strings.Join(names, "|")
+		}
+		fmt.Fprintf(w, "  %-24s %s\n", name, sub.description)
+	}
+}
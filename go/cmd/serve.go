@@ -0,0 +1,472 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	repositories "no-code-app/apps/04_repositories"
+	"no-code-app/apps/10_utils/config"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/apps/10_utils/tracing"
+	"no-code-app/pkg/middleware"
+	"no-code-app/pkg/openapi"
+	"no-code-app/pkg/ratelimit"
+	"no-code-app/pkg/router"
+	"no-code-app/pkg/server"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	orm "no-code-app/apps/10_utils/database"
+	errorhandler "no-code-app/apps/10_utils/error"
+)
+
+// signalContextは、SIGTERM/SIGINTを受け取るとキャンセルされるコンテキストを返します。
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+}
+
+// serverConfigは、設定からHTTPサーバーの設定を作成します。
+// cfg: 設定
+// addr: 待ち受けアドレス
+func serverConfig(cfg *config.Config, addr string) server.Config {
+	settings := cfg.Server
+	return server.Config{
+		Addr:              addr,
+		ReadHeaderTimeout: settings.ReadHeaderTimeout,
+		ReadTimeout:       settings.ReadTimeout,
+		WriteTimeout:      settings.WriteTimeout,
+		IdleTimeout:       settings.IdleTimeout,
+		ShutdownTimeout:   settings.ShutdownTimeout,
+		CertFile:          settings.CertFile,
+		KeyFile:           settings.KeyFile,
+	}
+}
+
+// initTracingは、設定からトレーシングを初期化します。
+// 戻り値の関数で、未送信のスパンを送信してから停止します。
+// ctx: コンテキスト
+// cfg: 設定
+// serviceName: スパンに記録するサービス名
+func initTracing(ctx context.Context, cfg *config.Config, serviceName string) (func(), error) {
+	settings := cfg.Tracing
+	shutdown, err := tracing.Init(ctx, tracing.Config{
+		Exporter:       settings.Exporter,
+		Endpoint:       settings.Endpoint,
+		SampleRatio:    settings.SampleRatio,
+		ServiceName:    serviceName,
+		ServiceVersion: Version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %v", err)
+	}
+	return func() {
+		// シグナルでキャンセルされたコンテキストは使用できないため、新しい期限で送信する
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			logger.Warn(fmt.Sprintf("failed to flush spans: %v", err))
+		}
+	}, nil
+}
+
+// initLogFileは、設定でディレクトリが指定されている場合に、ローテーションするログファイル（サービス名.log）を既定のロガーのシンクに追加します。
+// 戻り値の関数で、ログファイルを閉じます。
+// cfg: 設定
+// serviceName: サービス名
+func initLogFile(cfg *config.Config, serviceName string) (func(), error) {
+	settings := cfg.Log.File
+	if settings.Dir == "" {
+		return func() {}, nil
+	}
+	encoder, err := logFileEncoder(cfg)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := logger.NewRotatingWriter(logger.RotateConfig{
+		Filename:   filepath.Join(settings.Dir, serviceName+".log"),
+		MaxSize:    int64(settings.MaxSizeMB) << 20,
+		Daily:      settings.Daily,
+		MaxAge:     settings.MaxAge,
+		MaxBackups: settings.MaxBackups,
+		Compress:   settings.Compress,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %v", err)
+	}
+	// 標準出力とは別の出力形式で書き込めるよう、シンクとして追加する
+	logger.AddSink(logger.NewWriterSink(writer, encoder, logger.DEBUG))
+	return func() { writer.Close() }, nil
+}
+
+// logFileEncoderは、ログファイルの出力形式（未設定の場合は log.format）のエンコーダーを作成します。
+// cfg: 設定
+func logFileEncoder(cfg *config.Config) (logger.Encoder, error) {
+	format := cfg.Log.File.Format
+	if format == "" {
+		format = cfg.Log.Format
+	}
+	if format == "" {
+		return logger.NewEncoder(logger.TEXT), nil
+	}
+	outputFormat, err := logger.ParseLogOutputFormat(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log.file.format: %v", err)
+	}
+	return logger.NewEncoder(outputFormat), nil
+}
+
+// initLoggerは、設定の出力形式、コンポーネントごとのログレベル、ログを絞り込む規則、機密情報を伏せ字にする規則、ログのサンプリングを既定のロガーに設定します。
+// cfg: 設定
+func initLogger(cfg *config.Config) error {
+	if cfg.Log.Format != "" {
+		format, err := logger.ParseLogOutputFormat(cfg.Log.Format)
+		if err != nil {
+			return fmt.Errorf("invalid log.format: %v", err)
+		}
+		logger.SetLogOutputFormat(format)
+	}
+	levels := make(map[string]logger.LogLevel, len(cfg.Log.Components))
+	for component, name := range cfg.Log.Components {
+		level, err := logger.ParseLogLevel(name)
+		if err != nil {
+			return fmt.Errorf("invalid log.components.%s: %v", component, err)
+		}
+		levels[component] = level
+	}
+	rules := make([]logger.FilterRule, 0, len(cfg.Log.Filters))
+	for _, rule := range cfg.Log.Filters {
+		rules = append(rules, logger.FilterRule{Contains: rule.Contains, Pattern: rule.Pattern, Fields: rule.Fields, Exclude: rule.Exclude})
+	}
+	filter, err := logger.NewFilter(rules...)
+	if err != nil {
+		return err
+	}
+	var redactor *logger.Redactor
+	if settings := cfg.Log.Redact; !settings.Disabled {
+		redactRules := make([]logger.RedactRule, 0, len(settings.Patterns))
+		for _, rule := range settings.Patterns {
+			redactRules = append(redactRules, logger.RedactRule{Pattern: rule.Pattern, Replacement: rule.Replacement})
+		}
+		if redactor, err = logger.NewRedactor(logger.RedactConfig{Fields: settings.Fields, Rules: redactRules, DisableDefaults: settings.DisableDefaults}); err != nil {
+			return err
+		}
+	}
+	var sampler *logger.Sampler
+	if settings := cfg.Log.Sampling; settings.First > 0 {
+		sampler = logger.NewSampler(logger.SamplingConfig{Interval: settings.Interval, First: settings.First, Thereafter: settings.Thereafter})
+	}
+	samplers := make(map[string]*logger.Sampler, len(cfg.Log.Sampling.Components))
+	for component, settings := range cfg.Log.Sampling.Components {
+		samplers[component] = nil
+		if settings.First > 0 {
+			samplers[component] = logger.NewSampler(logger.SamplingConfig{Interval: settings.Interval, First: settings.First, Thereafter: settings.Thereafter})
+		}
+	}
+	logger.SetComponentLevels(levels)
+	logger.SetLogFilter(filter)
+	logger.SetLogRedactor(redactor)
+	logger.SetLogSampler(sampler)
+	logger.SetComponentSamplers(samplers)
+	return nil
+}
+
+// initLogSinkは、設定で有効な場合に、ログを cm_t_log テーブルにまとめて記録するシンクを既定のロガーに追加します。
+// 戻り値の関数で、記録を待つログを記録してから停止します。recordsErrors は、シンクがERRORのログを記録するかを表します。
+// cfg: 設定
+// programID: cm_t_log に記録するプログラムID
+func initLogSink(cfg *config.Config, programID string) (stop func(), recordsErrors bool, err error) {
+	settings := cfg.Log.DB
+	if !settings.Enabled {
+		return func() {}, false, nil
+	}
+	minLevel, err := logDBMinLevel(cfg)
+	if err != nil {
+		return nil, false, err
+	}
+	// DBが停止していてもサービスは起動し、記録できないログは退避ファイルに書き込む
+	db, err := sql.Open("mysql", cfg.DataSourceName())
+	if err != nil {
+		return nil, false, err
+	}
+	writer := repositories.NewLogBatchWriter(repositories.NewMySQLLogRepository(db), programID, localIP())
+	sink := logger.NewBatchSink(writer, logger.BatchSinkConfig{
+		MinLevel:      minLevel,
+		FlushInterval: settings.FlushInterval,
+		BatchSize:     settings.BatchSize,
+		BufferSize:    settings.BufferSize,
+		SpillPath:     settings.SpillPath,
+	})
+	logger.AddSink(sink)
+	return func() {
+		sink.Close()
+		db.Close()
+	}, minLevel <= logger.ERROR, nil
+}
+
+// logDBMinLevelは、cm_t_log に記録する最小のログレベル（未設定の場合はWARN）を返します。
+// cfg: 設定
+func logDBMinLevel(cfg *config.Config) (logger.LogLevel, error) {
+	if cfg.Log.DB.MinLevel == "" {
+		return logger.WARN, nil
+	}
+	level, err := logger.ParseLogLevel(cfg.Log.DB.MinLevel)
+	if err != nil {
+		return level, fmt.Errorf("invalid log.db.min_level: %v", err)
+	}
+	return level, nil
+}
+
+// initLogShippingは、設定で送信先が指定されている場合に、ログをsyslog、Graylog（GELF）に送信するシンクを既定のロガーに追加します。
+// 戻り値の関数で、送信を待つログを送信してから停止します。
+// cfg: 設定
+// serviceName: サービス名（syslogのアプリケーション名の既定値）
+func initLogShipping(cfg *config.Config, serviceName string) (func(), error) {
+	sinks, err := newLogShippingSinks(cfg, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	for _, sink := range sinks {
+		logger.AddSink(sink)
+	}
+	return func() { closeSinks(sinks) }, nil
+}
+
+// newLogShippingSinksは、設定で送信先が指定されているsyslog、Graylog（GELF）のシンクを作成します。
+// 送信先への接続はログの送信時に行います。
+// cfg: 設定
+// serviceName: サービス名（syslogのアプリケーション名の既定値）
+func newLogShippingSinks(cfg *config.Config, serviceName string) ([]*logger.NetworkSink, error) {
+	var sinks []*logger.NetworkSink
+	if settings := cfg.Log.Syslog; settings.Address != "" {
+		network, err := logNetworkConfig("log.syslog", settings.Network, settings.Address, settings.TLS, settings.CAFile, settings.InsecureSkipVerify, settings.MinLevel, settings.BufferSize)
+		if err != nil {
+			return nil, err
+		}
+		appName := settings.AppName
+		if appName == "" {
+			appName = serviceName
+		}
+		sink, err := logger.NewSyslogSink(logger.SyslogConfig{NetworkConfig: network, Facility: settings.Facility, AppName: appName})
+		if err != nil {
+			return nil, fmt.Errorf("invalid log.syslog: %v", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	if settings := cfg.Log.GELF; settings.Address != "" {
+		network, err := logNetworkConfig("log.gelf", settings.Network, settings.Address, settings.TLS, settings.CAFile, settings.InsecureSkipVerify, settings.MinLevel, settings.BufferSize)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+		sink, err := logger.NewGELFSink(logger.GELFConfig{NetworkConfig: network})
+		if err != nil {
+			closeSinks(sinks)
+			return nil, fmt.Errorf("invalid log.gelf: %v", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// closeSinksは、送信を待つログを送信してからシンクを停止します。
+// sinks: シンク
+func closeSinks(sinks []*logger.NetworkSink) {
+	for _, sink := range sinks {
+		sink.Close()
+	}
+}
+
+// logNetworkConfigは、ログの送信先の設定から接続の設定を作成します。
+// name: エラーメッセージに使用する設定の名前
+// network: プロトコル（空の場合はudp）
+// address: 送信先のアドレス
+// useTLS: TLSで接続するかどうか
+// caFile: サーバー証明書を検証するCA証明書のファイル
+// insecureSkipVerify: サーバー証明書を検証しないかどうか
+// minLevel: 送信する最小のログレベル（空の場合はinfo）
+// bufferSize: 送信を待つログの最大件数
+func logNetworkConfig(name, network, address string, useTLS bool, caFile string, insecureSkipVerify bool, minLevel string, bufferSize int) (logger.NetworkConfig, error) {
+	result := logger.NetworkConfig{Network: network, Address: address, MinLevel: logger.INFO, BufferSize: bufferSize}
+	if result.Network == "" {
+		result.Network = "udp"
+	}
+	if minLevel != "" {
+		level, err := logger.ParseLogLevel(minLevel)
+		if err != nil {
+			return result, fmt.Errorf("invalid %s.min_level: %v", name, err)
+		}
+		result.MinLevel = level
+	}
+	if !useTLS {
+		return result, nil
+	}
+	result.TLSConfig = &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s.ca_file: %v", name, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return result, fmt.Errorf("invalid %s.ca_file: no certificates found", name)
+		}
+		result.TLSConfig.RootCAs = pool
+	}
+	return result, nil
+}
+
+// localIPは、ループバック以外の最初のIPアドレスを返します。取得できない場合は空文字を返します。
+func localIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+			return ipNet.IP.String()
+		}
+	}
+	return ""
+}
+
+// notFoundHandlerは、どのルートにもマッチしないリクエストに共通のエラー形式で404を返します。
+func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+	errorhandler.WriteError(w, r, errorhandler.NotFound("route not found"))
+}
+
+// methodNotAllowedHandlerは、パスにマッチするルートのメソッドが異なるリクエストに共通のエラー形式で405を返します。
+// 許可されたメソッドはルーターが Allow ヘッダーに設定します。
+func methodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	errorhandler.WriteError(w, r, errorhandler.New(http.StatusMethodNotAllowed, errorhandler.CodeMethodNotAllowed, "method not allowed"))
+}
+
+// registerAPIDocsは、OpenAPIのドキュメント（/openapi.json）とSwagger UIのページ（/docs）を登録します。
+// ドキュメントには全てのルートのエラーレスポンスとして共通のエラー形式を追加します。
+// r: ルーター
+// info: APIの情報
+func registerAPIDocs(r *router.Router, info openapi.Info) {
+	build := func() *openapi.Document {
+		doc := r.OpenAPI(info)
+		doc.SecurityScheme(openapi.BearerAuth, &openapi.SecurityScheme{Type: "http", Scheme: "bearer", Description: "auth.api_keys のAPIキー"})
+		errorResponse := openapi.JSONResponse("エラー", openapi.SchemaOf(errorhandler.ErrorResponse{}))
+		for _, item := range doc.Paths {
+			for _, op := range item {
+				if _, ok := op.Responses["default"]; !ok {
+					op.Responses["default"] = errorResponse
+				}
+			}
+		}
+		return doc
+	}
+	r.GET("/openapi.json", openapi.Handler(build), router.Name("openapi"), router.Doc(&openapi.Operation{
+		Summary:   "OpenAPIのドキュメントを取得します",
+		Tags:      []string{"docs"},
+		Responses: map[string]*openapi.Response{"200": openapi.JSONResponse("OpenAPIのドキュメント", &openapi.Schema{Type: "object"})},
+	}))
+	r.GET("/docs", openapi.SwaggerUI(info.Title, "/openapi.json"), router.Name("docs"), router.Doc(&openapi.Operation{
+		Summary:   "Swagger UIのページを表示します",
+		Tags:      []string{"docs"},
+		Responses: map[string]*openapi.Response{"200": {Description: "HTMLのページ"}},
+	}))
+}
+
+// rateLimiterは、設定から作成したレート制限のストレージとポリシーです。
+type rateLimiter struct {
+	// バケットのストレージ
+	store ratelimit.Store
+	// 制限のポリシー
+	policies []ratelimit.Policy
+}
+
+// newRateLimiterは、設定からレート制限を作成します。
+// 戻り値の関数でストレージのDB接続をクローズします。
+// ctx: コンテキスト（キャンセルで不要なバケットの削除を停止する）
+// cfg: 設定
+func newRateLimiter(ctx context.Context, cfg *config.Config) (*rateLimiter, func(), error) {
+	settings := cfg.RateLimit
+	policies, err := rateLimitPolicies(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateRateLimitStore(settings.Store); err != nil {
+		return nil, nil, err
+	}
+	limiter := &rateLimiter{policies: policies}
+
+	switch settings.Store {
+	case "", "memory":
+		limiter.store = ratelimit.NewMemoryStore()
+		return limiter, func() {}, nil
+	case "mysql":
+		db, err := orm.NewORM(cfg.DataSourceName())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to rate limit database: %v", err)
+		}
+		store := ratelimit.NewMySQLStore(db.DB)
+		// 1日以上使用されていないバケットを削除
+		go store.RunCleanup(ctx, time.Hour, 24*time.Hour)
+		limiter.store = store
+		return limiter, func() { db.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("unknown rate limit store %q", settings.Store)
+}
+
+// rateLimitPoliciesは、設定からレート制限のポリシーを作成します。未設定の項目にはデフォルト値を使用します。
+// cfg: 設定
+func rateLimitPolicies(cfg *config.Config) ([]ratelimit.Policy, error) {
+	var policies []ratelimit.Policy
+	for _, p := range cfg.RateLimit.Policies {
+		switch p.Key {
+		case ratelimit.KeyIP, ratelimit.KeyUser, ratelimit.KeyAPIKey:
+		default:
+			return nil, fmt.Errorf("rate limit policy %s: unknown key %q", p.Name, p.Key)
+		}
+		if p.Rate <= 0 {
+			return nil, fmt.Errorf("rate limit policy %s: rate must be positive", p.Name)
+		}
+		limit := ratelimit.Limit{Rate: p.Rate, Period: p.Period, Burst: p.Burst}
+		if limit.Period <= 0 {
+			limit.Period = time.Minute
+		}
+		if limit.Burst <= 0 {
+			limit.Burst = p.Rate
+		}
+		policies = append(policies, ratelimit.Policy{Name: p.Name, Key: p.Key, Limit: limit, Routes: p.Routes})
+	}
+	return policies, nil
+}
+
+// validateRateLimitStoreは、レート制限のストレージの種類を検証します。
+// store: ストレージの種類（空の場合は memory）
+func validateRateLimitStore(store string) error {
+	switch store {
+	case "", "memory", "mysql":
+		return nil
+	}
+	return fmt.Errorf("unknown rate limit store %q", store)
+}
+
+// middlewareは、指定した種類のキーのポリシーのみを適用するミドルウェアを返します。
+// キーが user のポリシーは認証ミドルウェアの後、それ以外は認証の前に適用するために分けて使用します。
+// keys: キーの種類
+func (l *rateLimiter) middleware(keys ...string) router.Middleware {
+	var policies []ratelimit.Policy
+	for _, policy := range l.policies {
+		for _, key := range keys {
+			if policy.Key == key {
+				policies = append(policies, policy)
+			}
+		}
+	}
+	if len(policies) == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return middleware.RateLimit(l.store, policies)
+}
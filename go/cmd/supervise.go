@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"no-code-app/apps/10_utils/config"
+	"no-code-app/pkg/supervisor"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// シャットダウン時に子プロセスの終了を待つデフォルトの期限
+const defaultShutdownTimeout = 30 * time.Second
+
+// superviseCommandは、設定ファイルのサービスを子プロセスとして起動・監視するコマンドです。
+func superviseCommand(fs *flag.FlagSet, opts *options) func(args []string) error {
+	return func(args []string) error {
+		cfg, err := opts.loadConfig()
+		if err != nil {
+			return err
+		}
+		// ログの出力形式、コンポーネントごとのログレベル、絞り込みを設定
+		if err := initLogger(cfg); err != nil {
+			return err
+		}
+
+		if len(cfg.Supervisor.Services) == 0 {
+			return errors.New("no services configured in supervisor.services")
+		}
+		sup, err := newSupervisor(cfg)
+		if err != nil {
+			return err
+		}
+
+		// SIGTERM/SIGINTを受け取ったら子プロセスに転送してシャットダウン
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+		defer signal.Stop(signals)
+		go func() {
+			sup.Shutdown(<-signals)
+		}()
+
+		// サービスが終了するのを待つ
+		return sup.Run()
+	}
+}
+
+// newSupervisorは、設定ファイルのサービスの定義からSupervisorを作成します。
+// cfg: 設定
+func newSupervisor(cfg *config.Config) (*supervisor.Supervisor, error) {
+	var specs []supervisor.ServiceSpec
+	for _, service := range cfg.Supervisor.Services {
+		// コマンドが省略された場合は自身のバイナリを起動する
+		command := service.Command
+		if command == "" {
+			var err error
+			if command, err = os.Executable(); err != nil {
+				return nil, err
+			}
+		}
+		specs = append(specs, supervisor.ServiceSpec{
+			Name:       service.Name,
+			Command:    command,
+			Args:       service.Args,
+			Env:        service.Env,
+			Dir:        service.Dir,
+			Restart:    supervisor.RestartPolicy(service.Restart),
+			MinBackoff: service.MinBackoff,
+			MaxBackoff: service.MaxBackoff,
+		})
+	}
+	shutdownTimeout := cfg.Supervisor.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+	sup, err := supervisor.New(specs, shutdownTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid supervisor.services: %v", err)
+	}
+	return sup, nil
+}
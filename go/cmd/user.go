@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	orm "no-code-app/apps/10_utils/database"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// userCreateCommandは、ユーザーを作成するコマンドです。
+// パスワードはコマンドライン引数に残らないよう標準入力から読み込み、bcryptでハッシュ化して保存します。
+func userCreateCommand(fs *flag.FlagSet, opts *options) func(args []string) error {
+	email := fs.String("email", "", "メールアドレス（必須）")
+	firstName := fs.String("first-name", "", "名（必須）")
+	lastName := fs.String("last-name", "", "姓（必須）")
+	createdBy := fs.String("created-by", "system", "作成ユーザー")
+
+	return func(args []string) error {
+		if *email == "" || *firstName == "" || *lastName == "" {
+			return errUsage
+		}
+		password, err := readPassword(os.Stdin, os.Stderr)
+		if err != nil {
+			return err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %v", err)
+		}
+		cfg, err := opts.loadConfig()
+		if err != nil {
+			return err
+		}
+		db, err := orm.NewORM(cfg.DataSourceName())
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %v", err)
+		}
+		defer db.Close()
+
+		// 同じメールアドレスのユーザーが存在しないか確認
+		var count int
+		if err := db.DB.QueryRow("SELECT COUNT(*) FROM cm_m_users WHERE email = ?", *email).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("user %s already exists", *email)
+		}
+
+		result, err := db.Create("INSERT INTO cm_m_users (first_name, last_name, email, password, created_by, updated_by) VALUES (?, ?, ?, ?, ?, ?)",
+			*firstName, *lastName, *email, string(hash), *createdBy, *createdBy)
+		if err != nil {
+			return err
+		}
+		userID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created user %d (%s)\n", userID, *email)
+		return nil
+	}
+}
+
+// readPasswordは、標準入力の1行目をパスワードとして読み込みます。
+// 標準入力が端末の場合は入力を促すメッセージを出力します（例: echo "$PASSWORD" | no-code-app user create ...）。
+// r: 標準入力
+// prompt: 入力を促すメッセージの出力先
+func readPassword(r *os.File, prompt io.Writer) (string, error) {
+	if info, err := r.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(prompt, "Password: ")
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %v", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password is required on standard input")
+	}
+	return password, nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Versionは、ビルド時に -ldflags "-X no-code-app/cmd.Version=v1.2.3" で設定するバージョンです。
+var Version = "dev"
+
+// versionCommandは、バージョンを表示するコマンドです。
+func versionCommand(fs *flag.FlagSet, opts *options) func(args []string) error {
+	return func(args []string) error {
+		fmt.Printf("no-code-app %s (%s %s/%s)\n", Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+		// ビルド情報にコミットが含まれていれば表示
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" || setting.Key == "vcs.time" {
+					fmt.Printf("%s: %s\n", setting.Key, setting.Value)
+				}
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	entities "no-code-app/apps/03_entities"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/apps/10_utils/sysinfo"
+	"no-code-app/apps/10_utils/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceConfigは、エージェントが監視するサービスの設定です。
+type ServiceConfig struct {
+	// サービス名
+	Name string
+	// 起動しているべきプロセス名（1つでも見つからなければサービス停止とみなす）
+	Processes []string
+	// 追従するログファイルのパス
+	LogFiles []string
+}
+
+// Agentは、監視対象ホストで監視サーバーからのリクエストに応答します。
+type Agent struct {
+	// リソース使用状況の計測
+	collector *sysinfo.Collector
+	// サービス名ごとの設定
+	services map[string]ServiceConfig
+	// 監視サーバーと共有する認証トークン
+	token string
+}
+
+// Newは、新しいAgentを初期化します。
+// collector: リソース使用状況の計測
+// services: 監視するサービスの設定
+// token: 監視サーバーと共有する認証トークン（一致しないリクエストは拒否する）
+func New(collector *sysinfo.Collector, services []ServiceConfig, token string) *Agent {
+	agent := &Agent{collector: collector, services: make(map[string]ServiceConfig), token: token}
+	for _, service := range services {
+		agent.services[service.Name] = service
+	}
+	return agent
+}
+
+// Handleは、QUICストリームで受信したリクエストを処理します。
+// ctx: ストリームのコンテキスト
+// request: JSON形式のリクエスト
+// w: レスポンスの書き込み先
+func (a *Agent) Handle(ctx context.Context, request []byte, w io.Writer) error {
+	var req entities.AgentRequest
+	if err := json.Unmarshal(request, &req); err != nil {
+		return writeResponse(w, entities.AgentResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+	}
+
+	// 監視サーバーのログと突き合わせられるようリクエストIDを記録する
+	reqLogger := logger.With("type", req.Type, "service", req.ServiceName, logger.FieldRequestID, req.RequestID)
+
+	// プロセスのコマンドラインやログを返すため、トークンが一致しないリクエストはステータスの取得、ログの追従とも拒否する
+	if a.token == "" || subtle.ConstantTimeCompare([]byte(req.Token), []byte(a.token)) != 1 {
+		reqLogger.Warn("unauthorized agent request")
+		return writeResponse(w, entities.AgentResponse{Error: "unauthorized"})
+	}
+	reqLogger.Debug("agent request")
+
+	// 監視サーバーのスパンを親としてスパンを開始
+	ctx, span := tracing.Tracer("no-code-app/pkg/agent").Start(tracing.Extract(ctx, req.TraceContext), "agent "+req.Type,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("app.service_name", req.ServiceName), attribute.String("app.request_id", req.RequestID)),
+	)
+	defer span.End()
+
+	switch req.Type {
+	case entities.AgentRequestStatus:
+		status, err := a.Status(req.ServiceName, req.TopN)
+		if err != nil {
+			reqLogger.With("error", err).Warn("failed to get service status")
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return writeResponse(w, entities.AgentResponse{Error: err.Error()})
+		}
+		return writeResponse(w, entities.AgentResponse{Status: &status})
+	case entities.AgentRequestTail:
+		err := a.Tail(ctx, req.ServiceName, w)
+		if err != nil && ctx.Err() == nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	default:
+		return writeResponse(w, entities.AgentResponse{Error: fmt.Sprintf("unknown request type %q", req.Type)})
+	}
+}
+
+// Statusは、ホストとプロセスのリソース使用状況からサービスステータスを作成します。
+// 設定されたプロセスが見つからない場合はサービス停止とします。
+// serviceName: サービス名
+// topN: 返却するプロセスの上限数
+func (a *Agent) Status(serviceName string, topN int) (entities.ServiceStatus, error) {
+	snapshot, err := a.collector.Collect()
+	if err != nil {
+		return entities.ServiceStatus{}, fmt.Errorf("failed to collect system info: %v", err)
+	}
+
+	status := entities.ServiceStatus{
+		ServiceName: serviceName,
+		PCName:      snapshot.Hostname,
+		MemoryUsage: snapshot.MemoryUsage,
+		DiskUsage:   snapshot.DiskUsage,
+		CPUUsage:    snapshot.CPUUsage,
+		IsUp:        true,
+		Timestamp:   snapshot.Timestamp,
+	}
+	// 設定されたプロセスが起動しているかを確認
+	for _, name := range a.services[serviceName].Processes {
+		if !containsProcess(snapshot.Processes, name) {
+			status.MissingProcesses = append(status.MissingProcesses, name)
+			status.IsUp = false
+		}
+	}
+	// CPU使用率の高い順に上限数までのプロセスを返却
+	for i, process := range snapshot.Processes {
+		if i >= topN {
+			break
+		}
+		status.Processes = append(status.Processes, entities.ProcessInfo{
+			PID:        process.PID,
+			Command:    process.Command,
+			CPUPercent: process.CPUPercent,
+			RSS:        process.RSS,
+			OpenFDs:    process.OpenFDs,
+		})
+	}
+	return status, nil
+}
+
+// containsProcessは、指定された名前のプロセスが存在するかを判定します。
+// processes: プロセスのリスト
+// name: プロセス名
+func containsProcess(processes []sysinfo.Process, name string) bool {
+	for _, process := range processes {
+		if process.Matches(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeResponseは、レスポンスをJSON形式で書き込みます。
+// w: 書き込み先
+// response: レスポンス
+func writeResponse(w io.Writer, response entities.AgentResponse) error {
+	return json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	entities "no-code-app/apps/03_entities"
+	"no-code-app/apps/10_utils/tail"
+	"os"
+	"sync"
+	"time"
+)
+
+// ログファイルへの追記を確認する間隔
+const tailPollInterval = 500 * time.Millisecond
+
+// Tailは、サービスのログファイルに追記された行をコンテキストがキャンセルされるまで書き込みます。
+// 各行は改行区切りのJSON形式のLogLineとして書き込まれます。
+// 認証トークンの確認は Handle で行うため、リクエストを認証してから呼び出してください。
+// ctx: ストリームのコンテキスト
+// serviceName: サービス名
+// w: 書き込み先
+func (a *Agent) Tail(ctx context.Context, serviceName string, w io.Writer) error {
+	files := a.services[serviceName].LogFiles
+	if len(files) == 0 {
+		return writeResponse(w, entities.AgentResponse{Error: fmt.Sprintf("no log files configured for %s", serviceName)})
+	}
+	hostname, _ := os.Hostname()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 複数のファイルからの行を1つのストリームに直列に書き込む
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	errs := make(chan error, len(files))
+	for _, file := range files {
+		go func(file string) {
+			errs <- tail.Follow(ctx, file, tailPollInterval, func(line string) {
+				mu.Lock()
+				defer mu.Unlock()
+				err := encoder.Encode(entities.LogLine{
+					ServiceName: serviceName,
+					PCName:      hostname,
+					File:        file,
+					Line:        line,
+					Timestamp:   time.Now(),
+				})
+				// 書き込みに失敗した場合はクライアントが切断したとみなして終了
+				if err != nil {
+					cancel()
+				}
+			})
+		}(file)
+	}
+
+	// いずれかの追従が終了したら全体を終了
+	err := <-errs
+	cancel()
+	for i := 1; i < len(files); i++ {
+		<-errs
+	}
+	return err
+}
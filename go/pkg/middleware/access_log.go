@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/apps/10_utils/requestid"
+	"no-code-app/apps/10_utils/tracing"
+	"no-code-app/pkg/router"
+	"strconv"
+	"time"
+)
+
+// アクセスログの記録をコンテキストに格納するキーの型
+type accessRecordContextKey struct{}
+
+// accessRecordは、後続のミドルウェアがアクセスログに追記する情報です。
+type accessRecord struct {
+	// 認証済みユーザーID
+	userID string
+}
+
+// setAccessUserIDは、アクセスログに認証済みユーザーIDを記録します。
+// ctx: リクエストのコンテキスト
+// userID: ユーザーID
+func setAccessUserID(ctx context.Context, userID string) {
+	if record, ok := ctx.Value(accessRecordContextKey{}).(*accessRecord); ok {
+		record.userID = userID
+	}
+}
+
+// AccessLogは、リクエストごとに1行のアクセスログをロガーに出力するミドルウェアを返します。
+// メソッド、ルートのテンプレート、ステータス、バイト数、処理時間、ユーザーID、クライアントIP、リクエストID、トレースIDを
+// ロガーのフィールドとして出力します（テキスト形式では key=value 形式）。5xxはエラー、4xxは警告、それ以外は情報レベルで出力します。
+func AccessLog() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			record := &accessRecord{}
+			recorder := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), accessRecordContextKey{}, record)))
+
+			// ルートのテンプレートを使用し、パスパラメータごとにログが分散しないようにする
+			route := r.URL.Path
+			if current := router.CurrentRoute(r); current != nil {
+				route = current.Path
+			}
+			status := recorder.statusCode()
+			log := logger.With(
+				"method", r.Method,
+				"route", route,
+				"status", status,
+				"bytes", recorder.bytes,
+				"latency_ms", strconv.FormatFloat(float64(time.Since(start).Microseconds())/1000, 'f', 3, 64),
+				"user_id", record.userID,
+				"client_ip", clientIP(r),
+				"request_id", requestid.FromContext(r.Context()),
+				"trace_id", tracing.TraceID(r.Context()),
+			)
+			switch {
+			case status >= 500:
+				log.Error("access")
+			case status >= 400:
+				log.Warn("access")
+			default:
+				log.Info("access")
+			}
+		})
+	}
+}
+
+// clientIPは、リクエストの接続元IPアドレスを返します。
+// X-Forwarded-For は偽装できるため使用しません。
+// r: HTTPリクエスト
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// responseRecorderは、レスポンスのステータスとバイト数を記録するResponseWriterです。
+type responseRecorder struct {
+	http.ResponseWriter
+	// ステータスコード（0の場合は未送信）
+	status int
+	// 書き込んだバイト数
+	bytes int64
+}
+
+// WriteHeaderは、ステータスコードを記録して送信します。
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Writeは、書き込んだバイト数を記録します。
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flushは、バッファされたレスポンスを送信します。
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijackは、WebSocketなどのために接続を引き継ぎます。ステータスは101として記録します。
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T does not support hijacking", r.ResponseWriter)
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrapは、http.ResponseControllerのために元のResponseWriterを返します。
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// statusCodeは、送信したステータスコードを返します。何も送信していない場合は200です。
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	errorhandler "no-code-app/apps/10_utils/error"
+	logger "no-code-app/apps/10_utils/log"
+	"strings"
+)
+
+// 認証済みユーザーIDを格納するコンテキストのキーの型
+type userIDContextKey struct{}
+
+// UserIDFromContextは、認証ミドルウェアが格納したユーザーIDを返します。未認証の場合は空文字を返します。
+// ctx: リクエストのコンテキスト
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDContextKey{}).(string)
+	return userID
+}
+
+// APIKeyAuthは、APIキーでリクエストを認証するミドルウェアを返します。
+// APIキーは Authorization: Bearer ヘッダーまたは X-API-Key ヘッダーで受け付けます。
+// URLはアクセスログやプロキシのログに残るため、クエリパラメータでは受け付けません。
+// keys: APIキーからユーザーIDへのマップ
+func APIKeyAuth(keys map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := keys[apiKeyFromRequest(r)]
+			if !ok {
+				errorhandler.WriteError(w, r, errorhandler.Unauthorized())
+				return
+			}
+			// アクセスログと後続のハンドラーのためにユーザーIDを格納（logger.Ctx のログにも付与する）
+			setAccessUserID(r.Context(), userID)
+			ctx := context.WithValue(r.Context(), userIDContextKey{}, userID)
+			ctx = logger.NewContext(ctx, logger.FieldUserID, userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// apiKeyFromRequestは、リクエストからAPIキーを取り出します。
+// r: HTTPリクエスト
+func apiKeyFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	return r.Header.Get("X-API-Key")
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	errorhandler "no-code-app/apps/10_utils/error"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/pkg/ratelimit"
+	"no-code-app/pkg/router"
+	"strconv"
+	"time"
+)
+
+// RateLimitは、トークンバケットでリクエスト数を制限するミドルウェアを返します。
+// ルートに適用される全てのポリシーのバケットにトークンがある場合のみ各バケットからトークンを取得し、
+// 1つでも不足した場合はどのバケットからも取得せずに429を返します（制限したリクエストで他のポリシーのトークンを消費しない）。
+// レスポンスには最も残りの少ないポリシーの X-RateLimit-Limit、X-RateLimit-Remaining、X-RateLimit-Reset を付与し、
+// 制限した場合は Retry-After も付与します。ストレージのエラー時はリクエストを許可します。
+// キーが user のポリシーは、認証ミドルウェアの後に適用した場合のみ有効です。
+// store: バケットのストレージ
+// policies: 制限のポリシー
+func RateLimit(store ratelimit.Store, policies []ratelimit.Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name, path := "", r.URL.Path
+			if route := router.CurrentRoute(r); route != nil {
+				name, path = route.Name, route.Path
+			}
+
+			var buckets []ratelimit.Bucket
+			for _, policy := range policies {
+				if !policy.Matches(name, path) {
+					continue
+				}
+				value := rateLimitKey(r, policy.Key)
+				if value == "" {
+					// キーを特定できない場合（未認証のユーザーなど）は対象外
+					continue
+				}
+				buckets = append(buckets, ratelimit.Bucket{Key: policy.Name + ":" + policy.Key + ":" + value, Limit: policy.Limit})
+			}
+			if len(buckets) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			results, err := store.Take(r.Context(), buckets, time.Now())
+			if err != nil {
+				logger.Ctx(r.Context()).With("error", err).Warn("rate limit store error")
+				next.ServeHTTP(w, r)
+				return
+			}
+			// 制限したポリシー、または最も残りの少ないポリシーをヘッダーで返す
+			reported := &results[0]
+			for i := 1; i < len(results); i++ {
+				if moreRestrictive(results[i], *reported) {
+					reported = &results[i]
+				}
+			}
+
+			header := w.Header()
+			header.Set("X-RateLimit-Limit", strconv.Itoa(reported.Limit))
+			header.Set("X-RateLimit-Remaining", strconv.Itoa(reported.Remaining))
+			header.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(reported.Reset)))
+			if !reported.Allowed {
+				header.Set("Retry-After", strconv.Itoa(ceilSeconds(reported.RetryAfter)))
+				errorhandler.WriteError(w, r, errorhandler.New(http.StatusTooManyRequests, errorhandler.CodeRateLimited, "too many requests"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKeyは、キーの種類に応じてリクエストから制限のキーを取り出します。
+// APIキーはストレージに平文で保存しないようハッシュ化します。
+// r: HTTPリクエスト
+// keyType: キーの種類
+func rateLimitKey(r *http.Request, keyType string) string {
+	switch keyType {
+	case ratelimit.KeyIP:
+		return clientIP(r)
+	case ratelimit.KeyUser:
+		return UserIDFromContext(r.Context())
+	case ratelimit.KeyAPIKey:
+		key := apiKeyFromRequest(r)
+		if key == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:16])
+	default:
+		return ""
+	}
+}
+
+// moreRestrictiveは、aがbより厳しい結果かを判定します。制限された結果を優先し、次に残りの少ない結果を優先します。
+func moreRestrictive(a, b ratelimit.Result) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+	return a.Remaining < b.Remaining
+}
+
+// ceilSecondsは、時間を秒単位に切り上げます。
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	entities "no-code-app/apps/03_entities"
+	interfaces "no-code-app/apps/05_interfaces"
+	errorhandler "no-code-app/apps/10_utils/error"
+	logger "no-code-app/apps/10_utils/log"
+	"no-code-app/apps/10_utils/requestid"
+	"runtime/debug"
+	"time"
+)
+
+// パニックをログテーブルに記録する際のタイムアウト
+const recoveryLogTimeout = 5 * time.Second
+
+// Recoveryは、ハンドラーのパニックを回復して500のエラーレスポンスを返すミドルウェアを返します。
+// スタックトレースはリクエストIDと共にロガーに出力し、logsが指定されている場合は cm_t_log にも記録します。
+// programID: cm_t_log に記録するプログラムID
+// logs: ログのリポジトリ（nilの場合はロガーにのみ出力する。ロガーのシンクがERRORのログを cm_t_log に記録する場合は重複するためnilを指定する）
+func Recovery(programID string, logs interfaces.LogRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := &responseRecorder{ResponseWriter: w}
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				// クライアントの切断による中断はnet/httpに任せる
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				id := requestid.FromContext(r.Context())
+				message := fmt.Sprintf("panic: %v (method=%s path=%s request_id=%s)\n%s", recovered, r.Method, r.URL.Path, id, debug.Stack())
+				// ロガーのシンクが cm_t_log に記録する場合に備え、カラムに記録する値をフィールドとして付ける
+				logger.With("program_id", programID, "client_ip", clientIP(r), "server_ip", serverIP(r), "user_id", UserIDFromContext(r.Context())).Error(message)
+				if logs != nil {
+					ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), recoveryLogTimeout)
+					defer cancel()
+					entry := entities.LogEntry{
+						ProgramID: programID,
+						LogLevel:  "ERROR",
+						Message:   message,
+						ClientIP:  clientIP(r),
+						ServerIP:  serverIP(r),
+						CreatedBy: UserIDFromContext(r.Context()),
+					}
+					if err := logs.SaveLog(ctx, entry); err != nil {
+						logger.With(logger.FieldRequestID, id, "error", err).Warn("failed to save panic log")
+					}
+				}
+
+				// レスポンスを送信済み（WebSocketを含む）の場合は書き込めない
+				if recorder.status == 0 {
+					errorhandler.WriteError(w, r, errorhandler.Internal(nil))
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// serverIPは、リクエストを受け付けたサーバーのIPアドレスを返します。
+// r: HTTPリクエスト
+func serverIP(r *http.Request) string {
+	addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
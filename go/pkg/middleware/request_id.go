@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+	"no-code-app/apps/10_utils/requestid"
+)
+
+// RequestIDは、リクエストIDをリクエストのコンテキストに格納するミドルウェアを返します。
+// X-Request-ID ヘッダーが有効な値であればそれを引き継ぎ、なければ新しく生成します。
+// リクエストIDはレスポンスの X-Request-ID ヘッダーでも返します。
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestid.Header)
+			if !requestid.Valid(id) {
+				id = requestid.New()
+			}
+			w.Header().Set(requestid.Header, id)
+			next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"no-code-app/apps/10_utils/requestid"
+	"no-code-app/apps/10_utils/tracing"
+	"no-code-app/pkg/router"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HTTPサーバーのスパンを作成するトレーサーの名前
+const tracerName = "no-code-app/pkg/middleware"
+
+// リクエストIDを記録するスパンの属性のキー
+const requestIDAttribute = attribute.Key("app.request_id")
+
+// Tracingは、リクエストごとにサーバーのスパンを作成するミドルウェアを返します。
+// traceparent ヘッダーのトレースコンテキストを親とし、スパン名にはルートのテンプレートを使用します。
+// アクセスログにトレースIDを出力するため、RequestID の後、AccessLog の前に適用してください。
+func Tracing() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := ""
+			if current := router.CurrentRoute(r); current != nil {
+				route = current.Path
+			}
+			ctx, span := startServerSpan(r, route)
+			recorder := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+			endServerSpan(span, recorder.statusCode())
+		})
+	}
+}
+
+// GinTracingは、ルーターを介さずにginを使用する場合の Tracing です。スパン名には gin のルートのパスを使用します。
+func GinTracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, span := startServerSpan(c.Request, c.FullPath())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		endServerSpan(span, c.Writer.Status())
+	}
+}
+
+// startServerSpanは、リクエストのトレースコンテキストを親としてサーバーのスパンを開始します。
+// r: HTTPリクエスト
+// route: ルートのテンプレート（マッチしない場合は空文字）
+func startServerSpan(r *http.Request, route string) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	name := r.Method
+	attributes := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.URLPath(r.URL.Path),
+		semconv.ClientAddress(clientIP(r)),
+		semconv.UserAgentOriginal(r.UserAgent()),
+		requestIDAttribute.String(requestid.FromContext(r.Context())),
+	}
+	if route != "" {
+		name += " " + route
+		attributes = append(attributes, semconv.HTTPRoute(route))
+	}
+	return tracing.Tracer(tracerName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attributes...))
+}
+
+// endServerSpanは、レスポンスのステータスを記録してスパンを終了します。サーバーのエラー（5xx）はスパンをエラーとします。
+// span: スパン
+// status: ステータスコード
+func endServerSpan(span trace.Span, status int) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	span.End()
+}
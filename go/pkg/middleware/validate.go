@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	errorhandler "no-code-app/apps/10_utils/error"
+	"no-code-app/pkg/openapi"
+	"no-code-app/pkg/router"
+)
+
+// Validateは、ルートに設定されたOpenAPIの操作でパラメータとリクエストボディを検証するミドルウェアを返します。
+// 検証エラーは validation_error として、フィールドごとのエラーを details に返します。
+// 操作が設定されていないルートは検証しません。
+func Validate() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := router.CurrentRoute(r)
+			if route == nil || route.Operation == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			fieldErrors, err := route.Operation.ValidateRequest(r)
+			if errors.Is(err, openapi.ErrBodyTooLarge) {
+				errorhandler.WriteError(w, r, errorhandler.New(http.StatusRequestEntityTooLarge, errorhandler.CodePayloadTooLarge, err.Error()))
+				return
+			}
+			if err != nil {
+				errorhandler.WriteError(w, r, errorhandler.BadRequest("failed to read request body"))
+				return
+			}
+			if len(fieldErrors) > 0 {
+				errorhandler.WriteError(w, r, errorhandler.Validation(fieldErrors))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sync"
+)
+
+// Handlerは、ドキュメントをJSONで返すハンドラーを返します。
+// ドキュメントは全てのルートが登録された後の最初のリクエストで1度だけ作成します。
+// build: ドキュメントを作成する関数
+func Handler(build func() *Document) http.HandlerFunc {
+	document := sync.OnceValues(func() ([]byte, error) {
+		return json.MarshalIndent(build(), "", "  ")
+	})
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := document()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(body)
+	}
+}
+
+// Swagger UIのバージョン（CDNから読み込む）
+const swaggerUIVersion = "5.17.14"
+
+// Swagger UIのページのテンプレート
+var swaggerUITemplate = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`))
+
+// SwaggerUIは、ドキュメントを表示するSwagger UIのページを返すハンドラーを返します。
+// Swagger UIのスクリプトはCDN（unpkg.com）から読み込みます。
+// title: ページのタイトル
+// specURL: ドキュメントのURL（例: /openapi.json）
+func SwaggerUI(title, specURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		swaggerUITemplate.Execute(w, struct {
+			Title   string
+			Version string
+			SpecURL string
+		}{title, swaggerUIVersion, specURL})
+	}
+}
@@ -0,0 +1,255 @@
+package openapi
+
+import (
+	"maps"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// 生成するドキュメントのOpenAPIのバージョン
+const Version = "3.0.3"
+
+// APIキーによるBearer認証のセキュリティスキーム名
+const BearerAuth = "bearerAuth"
+
+// Documentは、OpenAPIのドキュメントです。
+type Document struct {
+	// OpenAPIのバージョン
+	OpenAPI string `json:"openapi"`
+	// APIの情報
+	Info Info `json:"info"`
+	// パスごとの操作
+	Paths map[string]PathItem `json:"paths"`
+	// 共通のコンポーネント
+	Components *Components `json:"components,omitempty"`
+}
+
+// Infoは、APIの情報です。
+type Info struct {
+	// タイトル
+	Title string `json:"title"`
+	// 説明
+	Description string `json:"description,omitempty"`
+	// APIのバージョン
+	Version string `json:"version"`
+}
+
+// PathItemは、小文字のHTTPメソッドから操作へのマップです。
+type PathItem map[string]*Operation
+
+// Operationは、1つのルートの操作（パラメータ、リクエストボディ、レスポンス）です。
+type Operation struct {
+	// 概要
+	Summary string `json:"summary,omitempty"`
+	// 説明
+	Description string `json:"description,omitempty"`
+	// 操作ID（省略時はルート名）
+	OperationID string `json:"operationId,omitempty"`
+	// タグ
+	Tags []string `json:"tags,omitempty"`
+	// パス、クエリ、ヘッダーのパラメータ
+	Parameters []Parameter `json:"parameters,omitempty"`
+	// リクエストボディ
+	RequestBody *RequestBody `json:"requestBody,omitempty"`
+	// ステータスコード（または default）ごとのレスポンス
+	Responses map[string]*Response `json:"responses"`
+	// 必要な認証
+	Security []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Parameterは、パス、クエリ、ヘッダーのパラメータです。
+type Parameter struct {
+	// パラメータ名
+	Name string `json:"name"`
+	// パラメータの場所（path、query、header）
+	In string `json:"in"`
+	// 説明
+	Description string `json:"description,omitempty"`
+	// 必須かどうか
+	Required bool `json:"required,omitempty"`
+	// 値のスキーマ
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// RequestBodyは、リクエストボディです。
+type RequestBody struct {
+	// 説明
+	Description string `json:"description,omitempty"`
+	// 必須かどうか
+	Required bool `json:"required,omitempty"`
+	// Content-Typeごとのスキーマ
+	Content map[string]MediaType `json:"content"`
+}
+
+// MediaTypeは、Content-Typeごとのスキーマです。
+type MediaType struct {
+	// スキーマ
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Responseは、レスポンスです。
+type Response struct {
+	// 説明
+	Description string `json:"description"`
+	// Content-Typeごとのスキーマ
+	Content map[string]MediaType `json:"content,omitempty"`
+}
+
+// SecurityRequirementは、セキュリティスキーム名からスコープへのマップです。
+type SecurityRequirement map[string][]string
+
+// Componentsは、ドキュメント全体で共通のコンポーネントです。
+type Components struct {
+	// セキュリティスキーム
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecuritySchemeは、認証の方式です。
+type SecurityScheme struct {
+	// 種類（http、apiKey）
+	Type string `json:"type"`
+	// 説明
+	Description string `json:"description,omitempty"`
+	// HTTP認証のスキーム（bearer）
+	Scheme string `json:"scheme,omitempty"`
+	// apiKeyのパラメータ名
+	Name string `json:"name,omitempty"`
+	// apiKeyの場所（header、query）
+	In string `json:"in,omitempty"`
+}
+
+// パラメータの場所の定数
+const (
+	// パスパラメータ
+	InPath = "path"
+	// クエリパラメータ
+	InQuery = "query"
+	// ヘッダー
+	InHeader = "header"
+	// リクエストボディ（検証エラーのフィールドの場所としてのみ使用）
+	InBody = "body"
+)
+
+// Content-Typeの定数
+const (
+	// JSON
+	ContentJSON = "application/json"
+	// フォーム
+	ContentForm = "application/x-www-form-urlencoded"
+	// テキスト
+	ContentText = "text/plain"
+)
+
+// パスのテンプレートのパラメータ（{name}）
+var pathParamPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// NewDocumentは、新しいDocumentを作成します。
+// info: APIの情報
+func NewDocument(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+	}
+}
+
+// AddOperationは、メソッドとパスに操作を追加します。
+// パスのテンプレートのパラメータが宣言されていない場合は文字列のパスパラメータとして追加し、
+// レスポンスが宣言されていない場合は200を追加します。
+// method: HTTPメソッド
+// path: パスのテンプレート（パラメータは {name} の形式）
+// op: 操作
+func (d *Document) AddOperation(method, path string, op *Operation) {
+	copied := *op
+	copied.Parameters = append([]Parameter{}, op.Parameters...)
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		if !copied.hasParameter(InPath, match[1]) {
+			copied.Parameters = append(copied.Parameters, PathParam(match[1], ""))
+		}
+	}
+	copied.Responses = maps.Clone(op.Responses)
+	if len(copied.Responses) == 0 {
+		copied.Responses = map[string]*Response{"200": {Description: http.StatusText(http.StatusOK)}}
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+	item[strings.ToLower(method)] = &copied
+}
+
+// SecuritySchemeは、セキュリティスキームを追加します。
+// name: スキーム名
+// scheme: 認証の方式
+func (d *Document) SecurityScheme(name string, scheme *SecurityScheme) {
+	if d.Components == nil {
+		d.Components = &Components{}
+	}
+	if d.Components.SecuritySchemes == nil {
+		d.Components.SecuritySchemes = make(map[string]*SecurityScheme)
+	}
+	d.Components.SecuritySchemes[name] = scheme
+}
+
+// hasParameterは、場所と名前が一致するパラメータが宣言されているかを判定します。
+func (op *Operation) hasParameter(in, name string) bool {
+	for _, p := range op.Parameters {
+		if p.In == in && p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PathParamは、文字列のパスパラメータを作成します。
+// name: パラメータ名
+// description: 説明
+func PathParam(name, description string) Parameter {
+	return Parameter{Name: name, In: InPath, Description: description, Required: true, Schema: String()}
+}
+
+// QueryParamは、任意のクエリパラメータを作成します。
+// name: パラメータ名
+// description: 説明
+// schema: 値のスキーマ
+func QueryParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: InQuery, Description: description, Schema: schema}
+}
+
+// RequiredQueryParamは、必須のクエリパラメータを作成します。
+// name: パラメータ名
+// description: 説明
+// schema: 値のスキーマ
+func RequiredQueryParam(name, description string, schema *Schema) Parameter {
+	p := QueryParam(name, description, schema)
+	p.Required = true
+	return p
+}
+
+// JSONBodyは、必須のJSONのリクエストボディを作成します。
+// schema: ボディのスキーマ
+func JSONBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]MediaType{ContentJSON: {Schema: schema}}}
+}
+
+// FormBodyは、必須のフォームのリクエストボディを作成します。
+// schema: フォームの項目を properties に持つオブジェクトのスキーマ
+func FormBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]MediaType{ContentForm: {Schema: schema}}}
+}
+
+// JSONResponseは、JSONのレスポンスを作成します。
+// description: 説明
+// schema: レスポンスのスキーマ
+func JSONResponse(description string, schema *Schema) *Response {
+	return &Response{Description: description, Content: map[string]MediaType{ContentJSON: {Schema: schema}}}
+}
+
+// TextResponseは、テキストのレスポンスを作成します。
+// description: 説明
+func TextResponse(description string) *Response {
+	return &Response{Description: description, Content: map[string]MediaType{ContentText: {Schema: String()}}}
+}
@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schemaは、値のスキーマ（OpenAPI 3.0のSchema Objectのうち検証に使用する項目）です。
+type Schema struct {
+	// 型（object、array、string、integer、number、boolean。空の場合は任意の値）
+	Type string `json:"type,omitempty"`
+	// 書式（date-time、date、email、int64など）
+	Format string `json:"format,omitempty"`
+	// 説明
+	Description string `json:"description,omitempty"`
+	// nullを許可するかどうか
+	Nullable bool `json:"nullable,omitempty"`
+	// 許可する値
+	Enum []interface{} `json:"enum,omitempty"`
+	// オブジェクトのプロパティ
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// 必須のプロパティ
+	Required []string `json:"required,omitempty"`
+	// properties 以外のプロパティの値のスキーマ（マップ）
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+	// 配列の要素のスキーマ
+	Items *Schema `json:"items,omitempty"`
+	// 配列の最小要素数
+	MinItems *int `json:"minItems,omitempty"`
+	// 配列の最大要素数
+	MaxItems *int `json:"maxItems,omitempty"`
+	// 文字列の最小文字数
+	MinLength *int `json:"minLength,omitempty"`
+	// 文字列の最大文字数
+	MaxLength *int `json:"maxLength,omitempty"`
+	// 数値の最小値
+	Minimum *float64 `json:"minimum,omitempty"`
+	// 数値の最大値
+	Maximum *float64 `json:"maximum,omitempty"`
+	// 文字列の正規表現
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// Stringは、文字列のスキーマを作成します。
+func String() *Schema {
+	return &Schema{Type: "string"}
+}
+
+// DateTimeは、RFC3339形式の日時の文字列のスキーマを作成します。
+func DateTime() *Schema {
+	return &Schema{Type: "string", Format: "date-time"}
+}
+
+// Emailは、メールアドレスの文字列のスキーマを作成します。
+func Email() *Schema {
+	return &Schema{Type: "string", Format: "email"}
+}
+
+// Integerは、整数のスキーマを作成します。
+func Integer() *Schema {
+	return &Schema{Type: "integer", Format: "int64"}
+}
+
+// Numberは、数値のスキーマを作成します。
+func Number() *Schema {
+	return &Schema{Type: "number", Format: "double"}
+}
+
+// Booleanは、真偽値のスキーマを作成します。
+func Boolean() *Schema {
+	return &Schema{Type: "boolean"}
+}
+
+// Arrayは、配列のスキーマを作成します。
+// items: 要素のスキーマ
+func Array(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+// Objectは、オブジェクトのスキーマを作成します。
+// properties: プロパティ名からスキーマへのマップ
+// required: 必須のプロパティ名
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// Describeは、説明を設定します。
+// description: 説明
+func (s *Schema) Describe(description string) *Schema {
+	s.Description = description
+	return s
+}
+
+// OneOfは、許可する値を設定します。
+// values: 許可する値
+func (s *Schema) OneOf(values ...interface{}) *Schema {
+	s.Enum = values
+	return s
+}
+
+// Lengthは、文字列の最小文字数と最大文字数を設定します。
+// min: 最小文字数
+// max: 最大文字数（0の場合は制限しない）
+func (s *Schema) Length(min, max int) *Schema {
+	s.MinLength = &min
+	if max > 0 {
+		s.MaxLength = &max
+	}
+	return s
+}
+
+// Rangeは、数値の最小値と最大値を設定します。
+// min: 最小値
+// max: 最大値
+func (s *Schema) Range(min, max float64) *Schema {
+	s.Minimum = &min
+	s.Maximum = &max
+	return s
+}
+
+// Matchは、文字列の正規表現を設定します。
+// pattern: 正規表現
+func (s *Schema) Match(pattern string) *Schema {
+	s.Pattern = pattern
+	return s
+}
+
+// timeType と durationType は、JSONでの表現が型の種類と異なる型です。
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// SchemaOfは、Goの値の型からencoding/jsonでの表現に対応するスキーマを作成します。
+// フィールド名は json タグ（ない場合はフィールド名）を使用します。
+// v: 型を表す値（例: entities.ServiceStatus{}）
+func SchemaOf(v interface{}) *Schema {
+	return schemaOf(reflect.TypeOf(v), make(map[reflect.Type]bool))
+}
+
+// schemaOfは、型からスキーマを作成します。
+// t: 型
+// visiting: 再帰的な型を展開しないための作成中の型
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch t {
+	case timeType:
+		return DateTime()
+	case durationType:
+		return Integer().Describe("nanoseconds")
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := *schemaOf(t.Elem(), visiting)
+		schema.Nullable = true
+		return &schema
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Integer()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		min := 0.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &min}
+	case reflect.Float32, reflect.Float64:
+		return Number()
+	case reflect.String:
+		return String()
+	case reflect.Slice, reflect.Array:
+		// []byteはBase64の文字列になる
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return Array(schemaOf(t.Elem(), visiting))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{Type: "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		addFields(schema, t, visiting)
+		return schema
+	}
+	// interface{}などは任意の値とする
+	return &Schema{}
+}
+
+// addFieldsは、構造体のフィールドをスキーマのプロパティに追加します。埋め込みフィールドは展開します。
+// schema: 追加先のオブジェクトのスキーマ
+// t: 構造体の型
+// visiting: 作成中の型
+func addFields(schema *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				addFields(schema, fieldType, visiting)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = schemaOf(fieldType, visiting)
+	}
+}
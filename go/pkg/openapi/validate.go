@@ -0,0 +1,346 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// 検証するリクエストボディの最大サイズ
+const MaxBodySize = 1 << 20
+
+// ErrBodyTooLargeは、リクエストボディが MaxBodySize を超えた場合のエラーです。
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// FieldErrorは、フィールドごとの検証エラーです。
+type FieldError struct {
+	// フィールド（ボディのネストしたフィールドは items[0].name の形式）
+	Field string `json:"field"`
+	// フィールドの場所（path、query、header、body）
+	In string `json:"in"`
+	// メッセージ
+	Message string `json:"message"`
+}
+
+// 正規表現のキャッシュ
+var patterns sync.Map
+
+// ValidateRequestは、リクエストのパラメータとボディを操作のスキーマで検証し、検証エラーを返します。
+// ボディは検証後にハンドラーから再度読み取れるように戻します。
+// r: HTTPリクエスト
+func (op *Operation) ValidateRequest(r *http.Request) ([]FieldError, error) {
+	var errs []FieldError
+	for _, p := range op.Parameters {
+		var values []string
+		switch p.In {
+		case InPath:
+			if value := r.PathValue(p.Name); value != "" {
+				values = []string{value}
+			}
+		case InQuery:
+			values = r.URL.Query()[p.Name]
+		case InHeader:
+			values = r.Header.Values(p.Name)
+		default:
+			continue
+		}
+		errs = append(errs, validateValues(values, p.Required, p.Schema, p.Name, p.In)...)
+	}
+	if op.RequestBody == nil {
+		return errs, nil
+	}
+	bodyErrs, err := op.RequestBody.validate(r)
+	if err != nil {
+		return nil, err
+	}
+	return append(errs, bodyErrs...), nil
+}
+
+// validateは、リクエストボディをContent-Typeに対応するスキーマで検証します。
+// r: HTTPリクエスト
+func (b *RequestBody) validate(r *http.Request) ([]FieldError, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(r.Body, MaxBodySize+1))
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(body) > MaxBodySize {
+			return nil, ErrBodyTooLarge
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if len(body) == 0 {
+		if b.Required {
+			return []FieldError{{In: InBody, Message: "request body is required"}}, nil
+		}
+		return nil, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	content, ok := b.Content[mediaType]
+	if !ok {
+		var expected []string
+		for contentType := range b.Content {
+			expected = append(expected, contentType)
+		}
+		sort.Strings(expected)
+		return []FieldError{{Field: "Content-Type", In: InHeader, Message: "must be one of " + strings.Join(expected, ", ")}}, nil
+	}
+	if content.Schema == nil {
+		return nil, nil
+	}
+
+	switch mediaType {
+	case ContentJSON:
+		var value interface{}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return []FieldError{{In: InBody, Message: fmt.Sprintf("invalid JSON: %v", err)}}, nil
+		}
+		var errs []FieldError
+		content.Schema.validate(value, "", &errs)
+		return errs, nil
+	case ContentForm:
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return []FieldError{{In: InBody, Message: fmt.Sprintf("invalid form: %v", err)}}, nil
+		}
+		// フォームの項目はクエリパラメータと同様に文字列から変換して検証する
+		var errs []FieldError
+		for _, name := range slices.Sorted(maps.Keys(content.Schema.Properties)) {
+			required := slices.Contains(content.Schema.Required, name)
+			errs = append(errs, validateValues(form[name], required, content.Schema.Properties[name], name, InBody)...)
+		}
+		return errs, nil
+	}
+	return nil, nil
+}
+
+// validateValuesは、パラメータやフォームの文字列の値を、スキーマの型に変換して検証します。
+// values: 値（配列の場合は複数）
+// required: 必須かどうか
+// schema: 値のスキーマ
+// field: フィールド名
+// in: フィールドの場所
+func validateValues(values []string, required bool, schema *Schema, field, in string) []FieldError {
+	// 空の値は指定されていないものとする
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		if required {
+			return []FieldError{{Field: field, In: in, Message: "is required"}}
+		}
+		return nil
+	}
+	if schema == nil {
+		return nil
+	}
+
+	var errs []FieldError
+	if schema.Type == "array" {
+		items := make([]interface{}, len(values))
+		for i, value := range values {
+			items[i] = parseScalar(value, schema.Items)
+		}
+		schema.validate(items, field, &errs)
+	} else {
+		schema.validate(parseScalar(values[0], schema), field, &errs)
+	}
+	for i := range errs {
+		errs[i].In = in
+	}
+	return errs
+}
+
+// parseScalarは、文字列をスキーマの型に変換します。変換できない場合は文字列のまま返し、型の検証でエラーにします。
+// value: 文字列の値
+// schema: 値のスキーマ
+func parseScalar(value string, schema *Schema) interface{} {
+	if schema == nil {
+		return value
+	}
+	switch schema.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// validateは、JSONをデコードした値をスキーマで検証し、検証エラーを追加します。
+// value: 検証する値（数値は json.Number）
+// field: フィールド名
+// errs: 検証エラーの追加先
+func (s *Schema) validate(value interface{}, field string, errs *[]FieldError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: field, In: InBody, Message: fmt.Sprintf(format, args...)})
+	}
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			fail("must not be null")
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				*errs = append(*errs, FieldError{Field: joinField(field, name), In: InBody, Message: "is required"})
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(object)) {
+			if property, ok := s.Properties[name]; ok {
+				property.validate(object[name], joinField(field, name), errs)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(object[name], joinField(field, name), errs)
+			}
+		}
+		return
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", field, i), errs)
+			}
+		}
+		return
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if s.MinLength != nil && utf8.RuneCountInString(str) < *s.MinLength {
+			if *s.MinLength == 1 {
+				fail("must not be empty")
+			} else {
+				fail("must be at least %d characters", *s.MinLength)
+			}
+		}
+		if s.MaxLength != nil && utf8.RuneCountInString(str) > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" && !matchPattern(s.Pattern, str) {
+			fail("must match %s", s.Pattern)
+		}
+		if message := validateFormat(s.Format, str); message != "" {
+			fail("%s", message)
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		f, err := number.Float64()
+		if s.Type == "integer" && ok {
+			_, err = number.Int64()
+		}
+		if !ok || err != nil {
+			if s.Type == "integer" {
+				fail("must be an integer")
+			} else {
+				fail("must be a number")
+			}
+			return
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				return
+			}
+		}
+		fail("must be one of %v", s.Enum)
+	}
+}
+
+// validateFormatは、文字列が書式に一致しない場合にメッセージを返します。
+// format: 書式
+// value: 文字列
+func validateFormat(format, value string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC3339 date-time"
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case "email":
+		if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+			return "must be an email address"
+		}
+	}
+	return ""
+}
+
+// matchPatternは、文字列が正規表現に一致するかを判定します。不正な正規表現は一致しないものとします。
+// pattern: 正規表現
+// value: 文字列
+func matchPattern(pattern, value string) bool {
+	cached, ok := patterns.Load(pattern)
+	if !ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false
+		}
+		cached, _ = patterns.LoadOrStore(pattern, re)
+	}
+	return cached.(*regexp.Regexp).MatchString(value)
+}
+
+// joinFieldは、親のフィールド名と子のフィールド名を連結します。
+func joinField(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
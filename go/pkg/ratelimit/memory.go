@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 満タンに戻ったバケットを削除する間隔
+const memorySweepInterval = time.Minute
+
+// bucketは、メモリ上のトークンバケットです。
+type bucket struct {
+	// トークン数
+	tokens float64
+	// 最後に補充した日時
+	refilledAt time.Time
+	// バケットが満タンに戻る日時
+	fullAt time.Time
+}
+
+// MemoryStoreは、プロセスのメモリにバケットを保持するストレージです。単一インスタンスでの使用を想定しています。
+type MemoryStore struct {
+	// 排他制御用のミューテックス
+	mu sync.Mutex
+	// キーごとのバケット
+	buckets map[string]*bucket
+	// 最後に削除を行った日時
+	sweptAt time.Time
+}
+
+// NewMemoryStoreは、新しいMemoryStoreを作成します。
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Takeは、全てのバケットにトークンがある場合のみ、各バケットからトークンを1つずつ取得します。
+func (s *MemoryStore) Take(ctx context.Context, buckets []Bucket, now time.Time) ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 満タンに戻ったバケットは新しいバケットと同じなので削除してメモリを解放する
+	if now.Sub(s.sweptAt) > memorySweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.fullAt) {
+				delete(s.buckets, k)
+			}
+		}
+		s.sweptAt = now
+	}
+
+	// 全てのバケットを補充してからまとめて取得する
+	stored := make([]*bucket, len(buckets))
+	tokens := make([]float64, len(buckets))
+	for i, requested := range buckets {
+		b, ok := s.buckets[requested.Key]
+		if !ok {
+			b = &bucket{tokens: float64(requested.Limit.Burst), refilledAt: now}
+			s.buckets[requested.Key] = b
+		}
+		stored[i] = b
+		tokens[i] = refill(b.tokens, now.Sub(b.refilledAt), requested.Limit)
+	}
+	results := takeAll(tokens, buckets)
+	for i, b := range stored {
+		b.tokens = tokens[i]
+		b.refilledAt = now
+		b.fullAt = now.Add(results[i].Reset)
+	}
+	return results, nil
+}
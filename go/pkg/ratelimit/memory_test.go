@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTakeAll(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	strict := Bucket{Key: "strict", Limit: Limit{Rate: 1, Period: time.Hour, Burst: 1}}
+	loose := Bucket{Key: "loose", Limit: Limit{Rate: 10, Period: time.Hour, Burst: 10}}
+
+	// 1回目は両方のバケットから取得する
+	results, err := store.Take(context.Background(), []Bucket{strict, loose}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Allowed || !results[1].Allowed || results[1].Remaining != 9 {
+		t.Fatalf("first results = %+v, want allowed with 9 remaining in loose", results)
+	}
+
+	// strict が不足している間は、制限したリクエストで loose のトークンを消費しない
+	for i := 0; i < 3; i++ {
+		results, err = store.Take(context.Background(), []Bucket{strict, loose}, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results[0].Allowed || results[1].Allowed {
+			t.Fatalf("results = %+v, want rejected", results)
+		}
+		if results[0].RetryAfter <= 0 || results[1].RetryAfter != 0 {
+			t.Errorf("retry after = %v, %v, want only strict to wait", results[0].RetryAfter, results[1].RetryAfter)
+		}
+	}
+	results, err = store.Take(context.Background(), []Bucket{loose}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Allowed || results[0].Remaining != 8 {
+		t.Errorf("loose result = %+v, want allowed with 8 remaining", results[0])
+	}
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	logger "no-code-app/apps/10_utils/log"
+	"sort"
+	"time"
+)
+
+// MySQLStoreは、cm_t_rate_limit テーブルにバケットを保持するストレージです。
+// 複数のインスタンスで制限を共有できます。
+type MySQLStore struct {
+	// DB接続
+	db *sql.DB
+}
+
+// NewMySQLStoreは、新しいMySQLStoreを作成します。
+// db: DB接続（テーブルは mysql/02_migrations のマイグレーションで作成する）
+func NewMySQLStore(db *sql.DB) *MySQLStore {
+	return &MySQLStore{db: db}
+}
+
+// Takeは、全てのバケットの行ロックを取得し、全てのバケットにトークンがある場合のみ各バケットからトークンを1つずつ取得します。
+func (s *MySQLStore) Take(ctx context.Context, buckets []Bucket, now time.Time) ([]Result, error) {
+	// バケットがなければ満タンで作成（同時に作成された場合は既存の行を使用）
+	for _, bucket := range buckets {
+		if _, err := s.db.ExecContext(ctx,
+			"INSERT IGNORE INTO cm_t_rate_limit (bucket_key, tokens, refilled_at, created_by, updated_by) VALUES (?, ?, ?, 'system', 'system')",
+			bucket.Key, float64(bucket.Limit.Burst), now.UnixMicro()); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// デッドロックを避けるため、キーの順に行ロックを取得する
+	order := make([]int, len(buckets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return buckets[order[a]].Key < buckets[order[b]].Key })
+	tokens := make([]float64, len(buckets))
+	for _, i := range order {
+		var refilledAt int64
+		if err := tx.QueryRowContext(ctx, "SELECT tokens, refilled_at FROM cm_t_rate_limit WHERE bucket_key = ? FOR UPDATE", buckets[i].Key).Scan(&tokens[i], &refilledAt); err != nil {
+			return nil, err
+		}
+		tokens[i] = refill(tokens[i], now.Sub(time.UnixMicro(refilledAt)), buckets[i].Limit)
+	}
+	results := takeAll(tokens, buckets)
+	for _, i := range order {
+		if _, err := tx.ExecContext(ctx, "UPDATE cm_t_rate_limit SET tokens = ?, refilled_at = ? WHERE bucket_key = ?", tokens[i], now.UnixMicro(), buckets[i].Key); err != nil {
+			return nil, err
+		}
+	}
+	return results, tx.Commit()
+}
+
+// RunCleanupは、コンテキストがキャンセルされるまで、一定時間使用されていないバケットを定期的に削除します。
+// ctx: コンテキスト
+// interval: 削除の間隔
+// idle: 削除するまでの未使用時間（最も長い補充時間より長くする）
+func (s *MySQLStore) RunCleanup(ctx context.Context, interval time.Duration, idle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		threshold := time.Now().Add(-idle).UnixMicro()
+		if _, err := s.db.ExecContext(ctx, "DELETE FROM cm_t_rate_limit WHERE refilled_at < ?", threshold); err != nil && ctx.Err() == nil {
+			logger.With("error", err).Warn("failed to clean up rate limit buckets")
+		}
+	}
+}
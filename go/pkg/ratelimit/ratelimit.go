@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// 制限のキーの種類
+const (
+	// 接続元IPアドレスごと
+	KeyIP = "ip"
+	// 認証済みユーザーごと（認証ミドルウェアの後に適用する）
+	KeyUser = "user"
+	// APIキーごと
+	KeyAPIKey = "api_key"
+)
+
+// Limitは、トークンバケットの制限です。Periodあたり Rate 回のリクエストを、最大 Burst 回まで連続して許可します。
+type Limit struct {
+	// 期間あたりに補充されるトークン数
+	Rate int
+	// 補充の期間
+	Period time.Duration
+	// バケットの容量（連続して許可するリクエスト数）
+	Burst int
+}
+
+// perSecondは、1秒あたりに補充されるトークン数を返します。
+func (l Limit) perSecond() float64 {
+	return float64(l.Rate) / l.Period.Seconds()
+}
+
+// Bucketは、トークンを取得するバケットのキーと制限です。
+type Bucket struct {
+	// バケットのキー
+	Key string
+	// 制限
+	Limit Limit
+}
+
+// Resultは、トークンを取得した結果です。
+type Result struct {
+	// リクエストを許可するかどうか（同時に取得した全てのバケットで同じ値）
+	Allowed bool
+	// バケットの容量
+	Limit int
+	// 残りのトークン数
+	Remaining int
+	// 次にトークンを取得できるまでの時間（許可された場合は0）
+	RetryAfter time.Duration
+	// バケットが満タンに戻るまでの時間
+	Reset time.Duration
+}
+
+// Storeは、バケットの状態を保持するストレージのインターフェースです。
+// 複数のインスタンスで制限を共有する場合は、共有ストレージ（MySQLなど）の実装を使用します。
+type Store interface {
+	// Takeは、全てのバケットにトークンがある場合のみ、各バケットからトークンを1つずつ取得します。
+	// 1つでも不足している場合はどのバケットからも取得しないため、制限したリクエストが他のバケットのトークンを消費しません。
+	// 結果は buckets と同じ順序で返します。
+	Take(ctx context.Context, buckets []Bucket, now time.Time) ([]Result, error)
+}
+
+// Policyは、ルートに適用する制限です。
+type Policy struct {
+	// ポリシー名（バケットのキーに含める）
+	Name string
+	// 制限のキーの種類（ip、user、api_key）
+	Key string
+	// 制限
+	Limit Limit
+	// 適用するルート名またはパスのテンプレート（空の場合は全てのルート）
+	Routes []string
+}
+
+// Matchesは、ポリシーがルートに適用されるかを判定します。
+// name: ルート名
+// path: パスのテンプレート
+func (p Policy) Matches(name, path string) bool {
+	if len(p.Routes) == 0 {
+		return true
+	}
+	for _, route := range p.Routes {
+		if route == name || route == path {
+			return true
+		}
+	}
+	return false
+}
+
+// refillは、前回の状態から経過時間分のトークンを補充したトークン数を返します。
+// tokens: 前回のトークン数
+// elapsed: 前回からの経過時間
+// limit: 制限
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed > 0 {
+		tokens = math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.perSecond())
+	}
+	return tokens
+}
+
+// takeAllは、補充後のトークン数から、全てのバケットにトークンがある場合のみ1つずつ取得し、その結果を返します。
+// tokens: 補充後のトークン数（取得後のトークン数に更新する）
+// buckets: バケット
+func takeAll(tokens []float64, buckets []Bucket) []Result {
+	allowed := true
+	for _, t := range tokens {
+		if t < 1 {
+			allowed = false
+		}
+	}
+	results := make([]Result, len(buckets))
+	for i, bucket := range buckets {
+		rate := bucket.Limit.perSecond()
+		if allowed {
+			tokens[i]--
+		} else if tokens[i] < 1 {
+			results[i].RetryAfter = secondsToDuration((1 - tokens[i]) / rate)
+		}
+		results[i].Allowed = allowed
+		results[i].Limit = bucket.Limit.Burst
+		results[i].Remaining = int(math.Floor(tokens[i]))
+		results[i].Reset = secondsToDuration((float64(bucket.Limit.Burst) - tokens[i]) / rate)
+	}
+	return results
+}
+
+// secondsToDurationは、秒数をDurationに変換します。
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
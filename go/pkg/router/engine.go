@@ -0,0 +1,159 @@
+package router
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/mux"
+)
+
+// パスのパラメータ（{name}）
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// ginEngineは、gin.Engineでルーティングを実行するエンジンです。
+type ginEngine struct {
+	*gin.Engine
+}
+
+// NewGinEngineは、gin.Engineを使用するエンジンを作成します。
+// engine: ginのエンジン（gin.New()またはgin.Default()）
+func NewGinEngine(engine *gin.Engine) Engine {
+	return &ginEngine{Engine: engine}
+}
+
+// Handleは、パスを :name の形式に変換してginに登録します。
+func (e *ginEngine) Handle(method, path string, handler http.Handler) {
+	e.Engine.Handle(method, pathParamPattern.ReplaceAllString(path, ":$1"), func(c *gin.Context) {
+		// ginのパラメータをr.PathValueで取得できるように設定
+		for _, param := range c.Params {
+			c.Request.SetPathValue(param.Key, param.Value)
+		}
+		handler.ServeHTTP(c.Writer, c.Request)
+	})
+}
+
+// NotFoundは、ginのNoRouteにハンドラーを登録します。
+func (e *ginEngine) NotFound(handler http.Handler) {
+	e.Engine.NoRoute(gin.WrapH(handler))
+}
+
+// MethodNotAllowedは、ginのNoMethodにハンドラーを登録します。Allow ヘッダーはginが設定します。
+func (e *ginEngine) MethodNotAllowed(handler http.Handler) {
+	e.Engine.HandleMethodNotAllowed = true
+	e.Engine.NoMethod(gin.WrapH(handler))
+}
+
+// muxEngineは、gorilla/muxでルーティングを実行するエンジンです。
+type muxEngine struct {
+	*mux.Router
+}
+
+// NewMuxEngineは、gorilla/muxを使用するエンジンを作成します。
+func NewMuxEngine() Engine {
+	return &muxEngine{Router: mux.NewRouter()}
+}
+
+// Handleは、パスをそのままgorilla/muxに登録します。
+func (e *muxEngine) Handle(method, path string, handler http.Handler) {
+	e.Router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		// gorilla/muxのパラメータをr.PathValueで取得できるように設定
+		for key, value := range mux.Vars(r) {
+			r.SetPathValue(key, value)
+		}
+		handler.ServeHTTP(w, r)
+	}).Methods(method)
+}
+
+// NotFoundは、gorilla/muxのNotFoundHandlerにハンドラーを登録します。
+func (e *muxEngine) NotFound(handler http.Handler) {
+	e.Router.NotFoundHandler = handler
+}
+
+// MethodNotAllowedは、gorilla/muxのMethodNotAllowedHandlerにハンドラーを登録します。
+// gorilla/muxは Allow ヘッダーを設定しないため、パスにマッチするルートのメソッドを設定してから呼び出します。
+func (e *muxEngine) MethodNotAllowed(handler http.Handler) {
+	e.Router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var allowed []string
+		e.Router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			var match mux.RouteMatch
+			if !route.Match(r, &match) && match.MatchErr == mux.ErrMethodMismatch {
+				methods, _ := route.GetMethods()
+				for _, method := range methods {
+					if !slices.Contains(allowed, method) {
+						allowed = append(allowed, method)
+					}
+				}
+			}
+			return nil
+		})
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// serveMuxEngineは、標準ライブラリのhttp.ServeMuxでルーティングを実行するエンジンです。
+type serveMuxEngine struct {
+	*http.ServeMux
+	// 登録されたルートのメソッド（登録順）
+	methods []string
+	// どのルートにもマッチしないリクエストのハンドラー（nilの場合は http.NotFound）
+	notFound http.Handler
+	// メソッドがマッチしないリクエストのハンドラー（nilの場合は http.Error で405を返す）
+	methodNotAllowed http.Handler
+}
+
+// NewServeMuxEngineは、http.ServeMuxを使用するエンジンを作成します。
+// http.ServeMuxはメソッドを指定しない "/" をメソッドのマッチしないルートより優先するため、
+// "/" にはルートのメソッドを判定して404または405を返すハンドラーを登録します。
+func NewServeMuxEngine() Engine {
+	e := &serveMuxEngine{ServeMux: http.NewServeMux()}
+	e.ServeMux.HandleFunc("/", e.serveUnmatched)
+	return e
+}
+
+// Handleは、"METHOD /path" のパターンでhttp.ServeMuxに登録します。
+func (e *serveMuxEngine) Handle(method, path string, handler http.Handler) {
+	e.ServeMux.Handle(method+" "+path, handler)
+	if !slices.Contains(e.methods, method) {
+		e.methods = append(e.methods, method)
+	}
+}
+
+// NotFoundは、どのルートにもマッチしないリクエストのハンドラーを登録します。
+func (e *serveMuxEngine) NotFound(handler http.Handler) {
+	e.notFound = handler
+}
+
+// MethodNotAllowedは、メソッドがマッチしないリクエストのハンドラーを登録します。
+func (e *serveMuxEngine) MethodNotAllowed(handler http.Handler) {
+	e.methodNotAllowed = handler
+}
+
+// serveUnmatchedは、どのルートにもマッチしないリクエストを処理します。
+// 他のメソッドでパスにマッチするルートがある場合は、Allow ヘッダーを設定して405を返します。
+func (e *serveMuxEngine) serveUnmatched(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	for _, method := range e.methods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := e.ServeMux.Handler(probe); pattern != "/" {
+			allowed = append(allowed, method)
+		}
+	}
+	switch {
+	case len(allowed) > 0:
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		if e.methodNotAllowed == nil {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		e.methodNotAllowed.ServeHTTP(w, r)
+	case e.notFound == nil:
+		http.NotFound(w, r)
+	default:
+		e.notFound.ServeHTTP(w, r)
+	}
+}
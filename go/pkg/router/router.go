@@ -1,18 +1,277 @@
 package router
 
 import (
+	"context"
 	"net/http"
-
-	"github.com/gorilla/mux"
+	"no-code-app/pkg/openapi"
+	"strings"
 )
 
-// NewRouter は新しいルーターを作成します
-func NewRouter() *mux.Router {
-	r := mux.NewRouter()
+// Middlewareは、ハンドラーを包んで前後の処理を追加するミドルウェアの型です。
+type Middleware func(http.Handler) http.Handler
+
+// Chainは、複数のミドルウェアを1つにまとめます。先に指定したものが外側になります。
+// middlewares: ミドルウェア
+func Chain(middlewares ...Middleware) Middleware {
+	return func(handler http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			handler = middlewares[i](handler)
+		}
+		return handler
+	}
+}
+
+// Engineは、ルーティングを実行するエンジン（gin、gorilla/muxなど）のインターフェースです。
+type Engine interface {
+	http.Handler
+	// Handleは、メソッドとパスにハンドラーを登録します。
+	// パスのパラメータは {name} の形式で指定し、ハンドラーからは r.PathValue(name) で取得できるようにします。
+	Handle(method, path string, handler http.Handler)
+	// NotFoundは、どのルートにもマッチしないリクエストのハンドラーを登録します。
+	NotFound(handler http.Handler)
+	// MethodNotAllowedは、パスにマッチするルートがあり、メソッドがマッチしないリクエストのハンドラーを登録します。
+	// ハンドラーを呼び出す前に、パスで許可されたメソッドを Allow ヘッダーに設定します。
+	MethodNotAllowed(handler http.Handler)
+}
+
+// Routeは、登録されたルートの情報です。
+type Route struct {
+	// HTTPメソッド
+	Method string
+	// グループのプレフィックスを含むパスのテンプレート（例: /api/v1/monitor/{serviceName}）
+	Path string
+	// ルート名
+	Name string
+	// メタデータ（例: menu_id）
+	Meta map[string]string
+	// OpenAPIの操作（パラメータ、リクエストボディ、レスポンスのスキーマ）
+	Operation *openapi.Operation
+}
+
+// RouteOptionは、ルートの登録時に名前やメタデータを設定する関数の型です。
+type RouteOption func(*Route)
+
+// Nameは、ルート名を設定します。
+// name: ルート名
+func Name(name string) RouteOption {
+	return func(r *Route) { r.Name = name }
+}
+
+// Metaは、ルートにメタデータを設定します。
+// key: キー
+// value: 値
+func Meta(key, value string) RouteOption {
+	return func(r *Route) {
+		if r.Meta == nil {
+			r.Meta = make(map[string]string)
+		}
+		r.Meta[key] = value
+	}
+}
+
+// ルートのメタデータでメニューIDを表すキー
+const MenuIDKey = "menu_id"
+
+// MenuIDは、ルートの表示に必要なメニューID（cm_m_menu.menu_id）を設定します。
+// menuID: メニューID
+func MenuID(menuID string) RouteOption {
+	return Meta(MenuIDKey, menuID)
+}
+
+// Docは、ルートにOpenAPIの操作を設定します。ドキュメントの生成とリクエストの検証に使用します。
+// op: 操作
+func Doc(op *openapi.Operation) RouteOption {
+	return func(r *Route) { r.Operation = op }
+}
+
+// ルートをコンテキストに格納するキーの型
+type routeContextKey struct{}
+
+// CurrentRouteは、リクエストにマッチしたルートを返します。ルーター外のリクエストではnilを返します。
+// r: HTTPリクエスト
+func CurrentRoute(r *http.Request) *Route {
+	route, _ := r.Context().Value(routeContextKey{}).(*Route)
+	return route
+}
+
+// Routerは、エンジンに依存しないルーターです。
+type Router struct {
+	// ルーティングを実行するエンジン
+	engine Engine
+	// 登録されたルート
+	routes []*Route
+	// プレフィックスのないルートグループ
+	root *Group
+}
+
+// Newは、新しいRouterを作成します。
+// engine: ルーティングを実行するエンジン
+func New(engine Engine) *Router {
+	r := &Router{engine: engine}
+	r.root = &Group{router: r}
 	return r
 }
 
-// AddRoute はルートを追加します
-func AddRoute(r *mux.Router, path string, handler http.HandlerFunc, methods ...string) {
-	r.HandleFunc(path, handler).Methods(methods...)
+// Groupは、プレフィックスとミドルウェアを持つグループを作成します。
+// prefix: パスのプレフィックス（例: /api/v1）
+// middlewares: グループのルートに適用するミドルウェア
+func (r *Router) Group(prefix string, middlewares ...Middleware) *Group {
+	return r.root.Group(prefix, middlewares...)
+}
+
+// Useは、全てのルートに適用するミドルウェアを追加します。追加後に登録したルートとグループにのみ適用されます。
+// middlewares: ミドルウェア（先に追加したものが外側になる）
+func (r *Router) Use(middlewares ...Middleware) {
+	r.root.Use(middlewares...)
+}
+
+// Handleは、メソッドとパスにハンドラーを登録します。
+func (r *Router) Handle(method, path string, handler http.Handler, options ...RouteOption) *Route {
+	return r.root.Handle(method, path, handler, options...)
+}
+
+// GETは、GETメソッドのハンドラーを登録します。
+func (r *Router) GET(path string, handler http.HandlerFunc, options ...RouteOption) *Route {
+	return r.root.GET(path, handler, options...)
+}
+
+// POSTは、POSTメソッドのハンドラーを登録します。
+func (r *Router) POST(path string, handler http.HandlerFunc, options ...RouteOption) *Route {
+	return r.root.POST(path, handler, options...)
+}
+
+// ServeHTTPは、エンジンでリクエストを処理します。
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.engine.ServeHTTP(w, req)
+}
+
+// NotFoundは、どのルートにもマッチしないリクエストのハンドラーを登録します。
+// 登録時点で Use により追加されているミドルウェアを適用します。
+// handler: ハンドラー
+func (r *Router) NotFound(handler http.HandlerFunc) {
+	r.engine.NotFound(r.applyMiddlewares(handler))
+}
+
+// MethodNotAllowedは、パスにマッチするルートがあり、メソッドがマッチしないリクエストのハンドラーを登録します。
+// ハンドラーの呼び出し時には、許可されたメソッドが Allow ヘッダーに設定されています。
+// 登録時点で Use により追加されているミドルウェアを適用します。
+// handler: ハンドラー
+func (r *Router) MethodNotAllowed(handler http.HandlerFunc) {
+	r.engine.MethodNotAllowed(r.applyMiddlewares(handler))
+}
+
+// applyMiddlewaresは、Use により追加されているミドルウェアをハンドラーに適用します。
+// handler: ハンドラー
+func (r *Router) applyMiddlewares(handler http.Handler) http.Handler {
+	for i := len(r.root.middlewares) - 1; i >= 0; i-- {
+		handler = r.root.middlewares[i](handler)
+	}
+	return handler
+}
+
+// Routesは、登録されたルートを登録順に返します。
+func (r *Router) Routes() []*Route {
+	return r.routes
+}
+
+// OpenAPIは、登録されたルートからOpenAPIのドキュメントを作成します。
+// 操作が設定されていないルートはパスパラメータのみのドキュメントとし、操作IDにはルート名を使用します。
+// info: APIの情報
+func (r *Router) OpenAPI(info openapi.Info) *openapi.Document {
+	doc := openapi.NewDocument(info)
+	for _, route := range r.routes {
+		op := openapi.Operation{}
+		if route.Operation != nil {
+			op = *route.Operation
+		}
+		if op.OperationID == "" {
+			op.OperationID = route.Name
+		}
+		doc.AddOperation(route.Method, route.Path, &op)
+	}
+	return doc
+}
+
+// Groupは、共通のプレフィックスとミドルウェアを持つルートのグループです。
+type Group struct {
+	// 所属するルーター
+	router *Router
+	// パスのプレフィックス
+	prefix string
+	// 親グループから引き継いだものを含むミドルウェア
+	middlewares []Middleware
+}
+
+// Groupは、プレフィックスとミドルウェアを追加した子グループを作成します。
+// prefix: パスのプレフィックス（例: /api/v1）
+// middlewares: 子グループのルートに適用するミドルウェア
+func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{
+		router:      g.router,
+		prefix:      joinPath(g.prefix, prefix),
+		middlewares: append(append([]Middleware{}, g.middlewares...), middlewares...),
+	}
+}
+
+// Useは、グループにミドルウェアを追加します。追加後に登録したルートと子グループにのみ適用されます。
+// middlewares: ミドルウェア（先に追加したものが外側になる）
+func (g *Group) Use(middlewares ...Middleware) {
+	g.middlewares = append(g.middlewares, middlewares...)
+}
+
+// Handleは、メソッドとパスにハンドラーを登録します。
+// method: HTTPメソッド
+// path: グループのプレフィックスからの相対パス（パラメータは {name} の形式）
+// handler: ハンドラー
+// options: ルート名やメタデータの設定
+func (g *Group) Handle(method, path string, handler http.Handler, options ...RouteOption) *Route {
+	route := &Route{Method: method, Path: joinPath(g.prefix, path)}
+	for _, option := range options {
+		option(route)
+	}
+
+	// グループのミドルウェアを適用（先に追加したものが外側）
+	for i := len(g.middlewares) - 1; i >= 0; i-- {
+		handler = g.middlewares[i](handler)
+	}
+	// ミドルウェアとハンドラーがルートを参照できるようにコンテキストに格納
+	inner := handler
+	handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeContextKey{}, route)))
+	})
+
+	g.router.engine.Handle(method, route.Path, handler)
+	g.router.routes = append(g.router.routes, route)
+	return route
+}
+
+// GETは、GETメソッドのハンドラーを登録します。
+func (g *Group) GET(path string, handler http.HandlerFunc, options ...RouteOption) *Route {
+	return g.Handle(http.MethodGet, path, handler, options...)
+}
+
+// POSTは、POSTメソッドのハンドラーを登録します。
+func (g *Group) POST(path string, handler http.HandlerFunc, options ...RouteOption) *Route {
+	return g.Handle(http.MethodPost, path, handler, options...)
+}
+
+// PUTは、PUTメソッドのハンドラーを登録します。
+func (g *Group) PUT(path string, handler http.HandlerFunc, options ...RouteOption) *Route {
+	return g.Handle(http.MethodPut, path, handler, options...)
+}
+
+// DELETEは、DELETEメソッドのハンドラーを登録します。
+func (g *Group) DELETE(path string, handler http.HandlerFunc, options ...RouteOption) *Route {
+	return g.Handle(http.MethodDelete, path, handler, options...)
+}
+
+// joinPathは、プレフィックスとパスを1つのスラッシュで連結します。
+// prefix: プレフィックス
+// path: パス
+func joinPath(prefix, path string) string {
+	joined := strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+	if len(joined) > 1 && !strings.HasSuffix(path, "/") {
+		joined = strings.TrimSuffix(joined, "/")
+	}
+	return joined
 }
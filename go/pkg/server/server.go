@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	logger "no-code-app/apps/10_utils/log"
+	"time"
+)
+
+// デフォルトのタイムアウト
+const (
+	// リクエストヘッダーの読み取りのタイムアウト
+	defaultReadHeaderTimeout = 5 * time.Second
+	// リクエスト全体の読み取りのタイムアウト
+	defaultReadTimeout = 15 * time.Second
+	// レスポンスの書き込みのタイムアウト
+	defaultWriteTimeout = 30 * time.Second
+	// キープアライブ接続のアイドルタイムアウト
+	defaultIdleTimeout = 2 * time.Minute
+	// シャットダウン時に処理中のリクエストの完了を待つ期限
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+// Configは、HTTPサーバーの設定です。ゼロ値の項目にはデフォルト値を使用します。
+type Config struct {
+	// 待ち受けアドレス
+	Addr string
+	// リクエストヘッダーの読み取りのタイムアウト
+	ReadHeaderTimeout time.Duration
+	// リクエスト全体の読み取りのタイムアウト
+	ReadTimeout time.Duration
+	// レスポンスの書き込みのタイムアウト（WebSocketはアップグレード時に解除される）
+	WriteTimeout time.Duration
+	// キープアライブ接続のアイドルタイムアウト
+	IdleTimeout time.Duration
+	// シャットダウン時に処理中のリクエストの完了を待つ期限
+	ShutdownTimeout time.Duration
+	// TLS証明書のパス（空の場合はHTTPで待ち受ける）
+	CertFile string
+	// TLS秘密鍵のパス
+	KeyFile string
+}
+
+// Serverは、コンテキストのキャンセルで接続を排出してから停止するHTTPサーバーです。
+type Server struct {
+	// HTTPサーバー
+	httpServer *http.Server
+	// シャットダウン時に処理中のリクエストの完了を待つ期限
+	shutdownTimeout time.Duration
+	// TLS証明書のパス
+	certFile string
+	// TLS秘密鍵のパス
+	keyFile string
+}
+
+// Newは、新しいServerを作成します。
+// cfg: サーバーの設定
+// handler: リクエストを処理するハンドラー（gin.Engineなど）
+func New(cfg Config, handler http.Handler) *Server {
+	s := &Server{
+		httpServer: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadHeaderTimeout: orDefault(cfg.ReadHeaderTimeout, defaultReadHeaderTimeout),
+			ReadTimeout:       orDefault(cfg.ReadTimeout, defaultReadTimeout),
+			WriteTimeout:      orDefault(cfg.WriteTimeout, defaultWriteTimeout),
+			IdleTimeout:       orDefault(cfg.IdleTimeout, defaultIdleTimeout),
+		},
+		shutdownTimeout: orDefault(cfg.ShutdownTimeout, defaultShutdownTimeout),
+		certFile:        cfg.CertFile,
+		keyFile:         cfg.KeyFile,
+	}
+	return s
+}
+
+// OnShutdownは、シャットダウン開始時に呼び出す関数を登録します。
+// ハイジャックされた接続（WebSocketなど）はサーバーが追跡しないため、ここでクローズします。
+// f: シャットダウン開始時に別のゴルーチンで呼び出される関数
+func (s *Server) OnShutdown(f func()) {
+	s.httpServer.RegisterOnShutdown(f)
+}
+
+// Runは、コンテキストがキャンセルされるまでリクエストを受け付けます。
+// キャンセル後は新しい接続の受け付けを停止し、処理中のリクエストの完了を待ってから戻ります。
+// ctx: コンテキスト
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if s.certFile != "" {
+			logger.Info(fmt.Sprintf("HTTPS server listening on %s", s.httpServer.Addr))
+			errCh <- s.httpServer.ListenAndServeTLS(s.certFile, s.keyFile)
+		} else {
+			logger.Info(fmt.Sprintf("HTTP server listening on %s", s.httpServer.Addr))
+			errCh <- s.httpServer.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		// 待ち受けに失敗した場合
+		return err
+	case <-ctx.Done():
+	}
+
+	// 期限付きで処理中のリクエストの完了を待つ
+	logger.Info(fmt.Sprintf("Shutting down HTTP server on %s", s.httpServer.Addr))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		// 期限を過ぎた場合は残りの接続を強制的にクローズ
+		logger.Warn(fmt.Sprintf("HTTP server did not drain within %s: %v", s.shutdownTimeout, err))
+		s.httpServer.Close()
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// orDefaultは、値が0以下の場合にデフォルト値を返します。
+// value: 設定値
+// defaultValue: デフォルト値
+func orDefault(value, defaultValue time.Duration) time.Duration {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
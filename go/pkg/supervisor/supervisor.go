@@ -0,0 +1,319 @@
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	logger "no-code-app/apps/10_utils/log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// 再起動ポリシーを表す型
+type RestartPolicy string
+
+// 再起動ポリシーの定数
+const (
+	// 終了した場合は常に再起動
+	RestartAlways RestartPolicy = "always"
+	// 異常終了した場合のみ再起動
+	RestartOnFailure RestartPolicy = "on-failure"
+	// 再起動しない
+	RestartNever RestartPolicy = "never"
+)
+
+// デフォルトの再起動待機時間
+const (
+	// 最初の再起動までの待機時間
+	defaultMinBackoff = time.Second
+	// 再起動までの最大待機時間
+	defaultMaxBackoff = time.Minute
+)
+
+// 子プロセスの終了後、子プロセスから起動されたプロセスが出力を開いたままの場合に、出力の転送を待つ期限
+const outputWaitDelay = 5 * time.Second
+
+// 転送する出力の1行の最大長（超えた場合は分割して転送）
+const maxLineLength = 1024 * 1024
+
+// ServiceSpecは、監視下で起動する子プロセスの定義です。
+type ServiceSpec struct {
+	// サービス名（出力のプレフィックスとして使用）
+	Name string
+	// 実行するバイナリのパス
+	Command string
+	// コマンドライン引数
+	Args []string
+	// 追加する環境変数（KEY=VALUE形式）
+	Env []string
+	// 作業ディレクトリ
+	Dir string
+	// 再起動ポリシー（空の場合はon-failure）
+	Restart RestartPolicy
+	// 最初の再起動までの待機時間
+	MinBackoff time.Duration
+	// 再起動までの最大待機時間（これより長く稼働した場合は待機時間を戻す）
+	MaxBackoff time.Duration
+}
+
+// Supervisorは、子プロセスを起動・再起動し、シャットダウン時に終了を待ち合わせます。
+type Supervisor struct {
+	// 子プロセスの定義
+	specs []ServiceSpec
+	// シャットダウン時に子プロセスの終了を待つ期限
+	shutdownTimeout time.Duration
+	// 排他制御用のミューテックス
+	mu sync.Mutex
+	// 実行中の子プロセス
+	running map[string]*exec.Cmd
+	// シャットダウンの開始を通知するコンテキスト
+	ctx context.Context
+	// シャットダウンを開始する関数
+	cancel context.CancelFunc
+}
+
+// Newは、新しいSupervisorを初期化します。
+// サービス名は実行中の子プロセスの識別に使用するため、空または重複している場合はエラーを返します。
+// 不明な再起動ポリシーもエラーを返します。
+// specs: 子プロセスの定義
+// shutdownTimeout: シャットダウン時に子プロセスの終了を待つ期限
+func New(specs []ServiceSpec, shutdownTimeout time.Duration) (*Supervisor, error) {
+	names := make(map[string]bool, len(specs))
+	for i, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("service #%d has no name", i+1)
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("duplicate service name %q", spec.Name)
+		}
+		names[spec.Name] = true
+		switch spec.Restart {
+		case "", RestartAlways, RestartOnFailure, RestartNever:
+		default:
+			return nil, fmt.Errorf("service %q has unknown restart policy %q", spec.Name, spec.Restart)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Supervisor{
+		specs:           specs,
+		shutdownTimeout: shutdownTimeout,
+		running:         make(map[string]*exec.Cmd),
+		ctx:             ctx,
+		cancel:          cancel,
+	}, nil
+}
+
+// Runは、全ての子プロセスを起動し、シャットダウン後に全ての子プロセスが終了するまで待ちます。
+// 期限内に終了しなかった子プロセスは強制終了し、エラーを返します。
+func (s *Supervisor) Run() error {
+	var wg sync.WaitGroup
+	for _, spec := range s.specs {
+		wg.Add(1)
+		go func(spec ServiceSpec) {
+			defer wg.Done()
+			s.supervise(spec)
+		}(spec)
+	}
+
+	// シャットダウンが開始されるか全ての子プロセスが再起動なしで終了するまで待機
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-s.ctx.Done():
+	}
+
+	// 期限まで子プロセスの終了を待ち、超過した場合は強制終了
+	select {
+	case <-done:
+		logger.Info("all services stopped")
+		return nil
+	case <-time.After(s.shutdownTimeout):
+	}
+	s.mu.Lock()
+	var names []string
+	for name, cmd := range s.running {
+		names = append(names, name)
+		cmd.Process.Kill()
+	}
+	s.mu.Unlock()
+	<-done
+	return fmt.Errorf("services did not stop within %s and were killed: %v", s.shutdownTimeout, names)
+}
+
+// Shutdownは、全ての子プロセスにシグナルを送り、再起動を停止します。
+// sig: 子プロセスに送るシグナル（SIGTERMまたはSIGINT）
+func (s *Supervisor) Shutdown(sig os.Signal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return
+	}
+	logger.Info(fmt.Sprintf("received %s, stopping services", sig))
+	s.cancel()
+	for name, cmd := range s.running {
+		signalProcess(name, cmd, sig)
+	}
+}
+
+// superviseは、再起動ポリシーに従って子プロセスを起動し続けます。
+// spec: 子プロセスの定義
+func (s *Supervisor) supervise(spec ServiceSpec) {
+	minBackoff, maxBackoff := spec.MinBackoff, spec.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	// 最大待機時間が最初の待機時間より短い場合は最初の待機時間に揃える
+	maxBackoff = max(maxBackoff, minBackoff)
+	policy := spec.Restart
+	if policy == "" {
+		policy = RestartOnFailure
+	}
+
+	backoff := minBackoff
+	for s.ctx.Err() == nil {
+		started := time.Now()
+		err := s.runOnce(spec)
+		if s.ctx.Err() != nil {
+			return
+		}
+		// 再起動ポリシーを確認
+		if policy == RestartNever || (policy == RestartOnFailure && err == nil) {
+			logger.Info(fmt.Sprintf("[%s] exited (%v), not restarting", spec.Name, exitDescription(err)))
+			return
+		}
+		// 十分に稼働していた場合は待機時間を戻す
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+		logger.Warn(fmt.Sprintf("[%s] exited (%v), restarting in %s", spec.Name, exitDescription(err), backoff))
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		// 待機時間を指数的に延ばす
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runOnceは、子プロセスを1回起動して終了を待ちます。
+// spec: 子プロセスの定義
+func (s *Supervisor) runOnce(spec ServiceSpec) error {
+	cmd := exec.Command(spec.Command, spec.Args...)
+	cmd.Dir = spec.Dir
+	cmd.Env = append(os.Environ(), spec.Env...)
+	// 出力をプレフィックス付きでロガーに転送
+	// 子プロセスから起動されたプロセスが出力を開いたままでも終了を検知できるよう、転送を待つ期限を設ける
+	stdout := &lineWriter{name: spec.Name, log: logger.Info}
+	stderr := &lineWriter{name: spec.Name, log: logger.Warn}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = outputWaitDelay
+
+	// シャットダウン中に起動しないよう、登録と起動を排他的に行う
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return s.ctx.Err()
+	}
+	if err := cmd.Start(); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.running[spec.Name] = cmd
+	s.mu.Unlock()
+	logger.Info(fmt.Sprintf("[%s] started (pid %d)", spec.Name, cmd.Process.Pid))
+
+	err := cmd.Wait()
+	stdout.Flush()
+	stderr.Flush()
+	if errors.Is(err, exec.ErrWaitDelay) {
+		// 子プロセス自体は正常に終了している
+		logger.Warn(fmt.Sprintf("[%s] output was still open %s after exit, stopped forwarding", spec.Name, outputWaitDelay))
+		err = nil
+	}
+
+	s.mu.Lock()
+	delete(s.running, spec.Name)
+	s.mu.Unlock()
+	return err
+}
+
+// lineWriterは、子プロセスの出力を1行ずつロガーに転送する io.Writer です。
+// exec.Cmd が1つのゴルーチンから書き込むため、排他制御は行いません。
+type lineWriter struct {
+	// サービス名
+	name string
+	// 出力先のログ関数
+	log func(string)
+	// 改行が書き込まれていない行
+	buf []byte
+}
+
+// Writeは、改行までの出力をロガーに転送し、残りを次の書き込みまで保持します。
+// p: 子プロセスの出力
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	// 改行のない長い出力は分割して転送
+	for len(w.buf) >= maxLineLength {
+		w.writeLine(w.buf[:maxLineLength])
+		w.buf = w.buf[maxLineLength:]
+	}
+	w.buf = append([]byte(nil), w.buf...)
+	return len(p), nil
+}
+
+// Flushは、改行で終わらない最後の出力を転送します。
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(w.buf)
+		w.buf = nil
+	}
+}
+
+// writeLineは、1行をプレフィックス付きでロガーに転送します。
+// line: 改行を除いた1行
+func (w *lineWriter) writeLine(line []byte) {
+	w.log(fmt.Sprintf("[%s] %s", w.name, bytes.TrimSuffix(line, []byte("\r"))))
+}
+
+// signalProcessは、子プロセスにシグナルを送ります。送れない環境では強制終了します。
+// name: サービス名
+// cmd: 子プロセス
+// sig: シグナル
+func signalProcess(name string, cmd *exec.Cmd, sig os.Signal) {
+	if err := cmd.Process.Signal(sig); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		logger.Warn(fmt.Sprintf("[%s] failed to send %s: %v, killing", name, sig, err))
+		cmd.Process.Kill()
+	}
+}
+
+// exitDescriptionは、終了状態を説明する文字列を返します。
+// err: cmd.Waitのエラー
+func exitDescription(err error) string {
+	if err == nil {
+		return "exit status 0"
+	}
+	return err.Error()
+}
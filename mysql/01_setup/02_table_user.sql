@@ -21,7 +21,7 @@ CREATE TABLE cm_m_users (
 -- テーブルにインデックスを追加
 CREATE INDEX idx_cm_m_users_email ON cm_m_users (email);
 
--- データを挿入
+-- データを挿入（パスワードは 'password' の bcrypt のハッシュ）
 INSERT INTO cm_m_users (first_name, last_name, email, password, created_by, updated_by)
-VALUES ('太郎', '山田', 'test_1@test.com', 'password', 'system', 'system'),
-       ('花子', '山田', 'test_2@test.com', 'password', 'system', 'system');
\ No newline at end of file
+VALUES ('太郎', '山田', 'test_1@test.com', '$2a$10$vIuOh/w8VGBiSqVYk1FAC.mCVNF/8K7sNDONWC62WdIFbTSYZCX3W', 'system', 'system'),
+       ('花子', '山田', 'test_2@test.com', '$2a$10$vIuOh/w8VGBiSqVYk1FAC.mCVNF/8K7sNDONWC62WdIFbTSYZCX3W', 'system', 'system');
\ No newline at end of file
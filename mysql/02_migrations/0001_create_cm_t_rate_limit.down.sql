@@ -0,0 +1,2 @@
+-- レート制限のトークンバケットテーブルを削除
+DROP TABLE IF EXISTS cm_t_rate_limit;
@@ -0,0 +1,13 @@
+-- レート制限のトークンバケットテーブル
+CREATE TABLE cm_t_rate_limit (
+    bucket_key VARCHAR(255) PRIMARY KEY COMMENT 'バケットのキー',
+    tokens DOUBLE NOT NULL COMMENT '残りのトークン数',
+    refilled_at BIGINT NOT NULL COMMENT '最後に補充した日時（UNIXマイクロ秒）',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '作成日時',
+    created_by VARCHAR(50) NOT NULL COMMENT '作成ユーザー',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新日時',
+    updated_by VARCHAR(50) NOT NULL COMMENT '更新ユーザー'
+);
+
+-- 未使用のバケットの削除に使用するインデックス
+CREATE INDEX idx_cm_t_rate_limit_refilled_at ON cm_t_rate_limit (refilled_at);
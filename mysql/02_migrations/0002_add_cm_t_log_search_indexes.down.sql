@@ -0,0 +1,4 @@
+-- ログの検索に使用するインデックスを削除
+DROP INDEX idx_cm_t_log_log_level ON cm_t_log;
+DROP INDEX idx_cm_t_log_program_id ON cm_t_log;
+DROP INDEX idx_cm_t_log_created_at ON cm_t_log;
@@ -0,0 +1,4 @@
+-- ログの検索（期間、プログラムID、ログレベルでの絞り込みとログIDでのページング）に使用するインデックス
+CREATE INDEX idx_cm_t_log_created_at ON cm_t_log (created_at);
+CREATE INDEX idx_cm_t_log_program_id ON cm_t_log (program_id, log_id);
+CREATE INDEX idx_cm_t_log_log_level ON cm_t_log (log_level, log_id);